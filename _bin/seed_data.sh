@@ -19,6 +19,7 @@ set -e
 . "$(dirname "${0}")/require_env_var.sh"
 
 requireEnvVar "BOOKS_ADDR"
+requireEnvVar "BOOKS_API_TOKEN"
 exists "curl"
 exists "jq"
 
@@ -28,6 +29,7 @@ RESPONSE_AUTHOR1=$(curl \
   --silent --show-error --fail \
   --data-binary '{"first_name": "Anne", "last_name": "Rice"}' \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/author")
 AUTHOR_ID1=$(echo "${RESPONSE_AUTHOR1}" | jq '.author_id' -r)
 echo "Added author Anne Rice: ${AUTHOR_ID1}"
@@ -36,6 +38,7 @@ RESPONSE_AUTHOR2=$(curl \
   --silent --show-error --fail \
   --data-binary '{"first_name": "John", "last_name": "Steinbeck"}' \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/author")
 AUTHOR_ID2=$(echo "${RESPONSE_AUTHOR2}" | jq '.author_id' -r)
 echo "Added author John Steinbeck: ${AUTHOR_ID2}"
@@ -44,6 +47,7 @@ RESPONSE_AUTHOR3=$(curl \
   --silent --show-error --fail \
   --data-binary '{"first_name": "JK", "last_name": "Rowling"}' \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/author")
 AUTHOR_ID3=$(echo "${RESPONSE_AUTHOR3}" | jq '.author_id' -r)
 echo "Added author JK Rowling: ${AUTHOR_ID3}"
@@ -52,6 +56,7 @@ RESPONSE_AUTHOR4=$(curl \
   --silent --show-error --fail \
   --data-binary '{"first_name": "Ernest", "last_name": "Hemingway"}' \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/author")
 AUTHOR_ID4=$(echo "${RESPONSE_AUTHOR4}" | jq '.author_id' -r)
 echo "Added author Ernest Hemingway: ${AUTHOR_ID4}"
@@ -60,6 +65,7 @@ RESPONSE_AUTHOR5=$(curl \
   --silent --show-error --fail \
   --data-binary '{"first_name": "Kurt", "last_name": "Vonnegut"}' \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/author")
 AUTHOR_ID5=$(echo "${RESPONSE_AUTHOR5}" | jq '.author_id' -r)
 echo "Added author Kurt Vonnegut: ${AUTHOR_ID5}"
@@ -68,6 +74,7 @@ RESPONSE_AUTHOR6=$(curl \
   --silent --show-error --fail \
   --data-binary '{"first_name": "Agatha", "last_name": "Christie"}' \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/author")
 AUTHOR_ID6=$(echo "${RESPONSE_AUTHOR6}" | jq '.author_id' -r)
 echo "Added author Agatha Christie: ${AUTHOR_ID6}"
@@ -76,6 +83,7 @@ RESPONSE_AUTHOR7=$(curl \
   --silent --show-error --fail \
   --data-binary '{"first_name": "James", "last_name": "Joyce"}' \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/author")
 AUTHOR_ID7=$(echo "${RESPONSE_AUTHOR7}" | jq '.author_id' -r)
 echo "Added author James Joyce: ${AUTHOR_ID7}"
@@ -86,6 +94,7 @@ echo ':: Getting author James Joyce by ID:'
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/authors/${AUTHOR_ID7}")
 echo "${GET_RESULT}" | jq
 
@@ -95,6 +104,7 @@ echo ':: Getting author James Joyce by name:'
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/author?first_name=James&last_name=Joyce")
 echo "${GET_RESULT}" | jq
 
@@ -104,6 +114,7 @@ echo ':: Getting all authors:'
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/authors")
 echo "${GET_RESULT}" | jq
 
@@ -114,6 +125,7 @@ RESPONSE_BOOK1=$(curl \
   --silent --show-error --fail \
   --data-binary "{\"author_id\": \"${AUTHOR_ID1}\", \"title\": \"The Wolf Gift\", \"publish_date\": \"2012-02-14T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/book")
 BOOK_ID1=$(echo "${RESPONSE_BOOK1}" | jq '.book_id' -r)
 echo "Added book The Wolf Gift by Anne Rice: ${BOOK_ID1}"
@@ -122,6 +134,7 @@ RESPONSE_BOOK2=$(curl \
   --silent --show-error --fail \
   --data-binary "{\"author_id\": \"${AUTHOR_ID1}\", \"title\": \"Interview with the Vampire\", \"publish_date\": \"1976-05-05T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/book")
 BOOK_ID2=$(echo "${RESPONSE_BOOK2}" | jq '.book_id' -r)
 echo "Added book Interview with the Vampire by Anne Rice: ${BOOK_ID2}"
@@ -130,6 +143,7 @@ RESPONSE_BOOK3=$(curl \
   --silent --show-error --fail \
   --data-binary "{\"author_id\": \"${AUTHOR_ID1}\", \"title\": \"The Queen of the Damned\", \"publish_date\": \"1988-09-12T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/book")
 BOOK_ID3=$(echo "${RESPONSE_BOOK3}" | jq '.book_id' -r)
 echo "Added book The Queen of the Damned by Anne Rice: ${BOOK_ID3}"
@@ -138,6 +152,7 @@ RESPONSE_BOOK4=$(curl \
   --silent --show-error --fail \
   --data-binary "{\"author_id\": \"${AUTHOR_ID2}\", \"title\": \"East of Eden\", \"publish_date\": \"1952-09-19T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/book")
 BOOK_ID4=$(echo "${RESPONSE_BOOK4}" | jq '.book_id' -r)
 echo "Added book East of Eden by John Steinbeck: ${BOOK_ID4}"
@@ -146,6 +161,7 @@ RESPONSE_BOOK5=$(curl \
   --silent --show-error --fail \
   --data-binary "{\"author_id\": \"${AUTHOR_ID3}\", \"title\": \"Harry Potter and the Goblet of Fire\", \"publish_date\": \"2000-07-08T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/book")
 BOOK_ID5=$(echo "${RESPONSE_BOOK5}" | jq '.book_id' -r)
 echo "Added book Harry Potter and the Goblet of Fire by JK Rowling: ${BOOK_ID5}"
@@ -154,6 +170,7 @@ RESPONSE_BOOK6=$(curl \
   --silent --show-error --fail \
   --data-binary "{\"author_id\": \"${AUTHOR_ID6}\", \"title\": \"Murder on the Orient Express\", \"publish_date\": \"1934-01-01T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/book")
 BOOK_ID6=$(echo "${RESPONSE_BOOK6}" | jq '.book_id' -r)
 echo "Added book Murder on the Orient Express by Agatha Christie: ${BOOK_ID6}"
@@ -162,6 +179,7 @@ RESPONSE_BOOK7=$(curl \
   --silent --show-error --fail \
   --data-binary "{\"author_id\": \"${AUTHOR_ID7}\", \"title\": \"Ulysses\", \"publish_date\": \"1922-02-02T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/book")
 BOOK_ID7=$(echo "${RESPONSE_BOOK7}" | jq '.book_id' -r)
 echo "Added book Ulysses by James Joyce: ${BOOK_ID7}"
@@ -170,6 +188,7 @@ RESPONSE_BOOK8=$(curl \
   --silent --show-error --fail \
   --data-binary "{\"author_id\": \"${AUTHOR_ID7}\", \"title\": \"Finnegans Wake\", \"publish_date\": \"1939-05-04T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/book")
 BOOK_ID8=$(echo "${RESPONSE_BOOK8}" | jq '.book_id' -r)
 echo "Added book Finnegans Wake by James Joyce: ${BOOK_ID8}"
@@ -180,6 +199,7 @@ echo ':: Getting book East of Eden by ID:'
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/books/${BOOK_ID4}")
 echo "${GET_RESULT}" | jq
 
@@ -189,6 +209,7 @@ echo ':: Getting all books by James Joyce:'
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/books?author_id=${AUTHOR_ID7}")
 echo "${GET_RESULT}" | jq
 
@@ -198,6 +219,7 @@ echo ':: Getting author James Joyce by ID (again):'
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/authors/${AUTHOR_ID7}")
 echo "${GET_RESULT}" | jq
 
@@ -207,6 +229,7 @@ echo ':: Getting all authors (again):'
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/authors")
 echo "${GET_RESULT}" | jq
 
@@ -216,6 +239,7 @@ echo ':: Deleting author Ernest Hemingway by ID:'
 curl \
   --show-error --fail \
   --include \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   --request DELETE \
   "${BOOKS_ADDR}/v1alpha1/authors/${AUTHOR_ID4}"
 
@@ -226,6 +250,7 @@ RESPONSE_AUTHOR4=$(curl \
   --silent --show-error --fail \
   --data-binary '{"first_name": "Ernest", "last_name": "Hemingway"}' \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/author")
 AUTHOR_ID4=$(echo "${RESPONSE_AUTHOR4}" | jq '.author_id' -r)
 echo "Added back author Ernest Hemingway: ${AUTHOR_ID4}"
@@ -238,11 +263,13 @@ curl \
   --include \
   --data-binary "{\"id\": \"${AUTHOR_ID4}\", \"first_name\": \"Ernie\", \"last_name\": \"Hemingway\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   --request PUT \
   "${BOOKS_ADDR}/v1alpha1/author"
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/authors/${AUTHOR_ID4}")
 echo "${GET_RESULT}" | jq
 
@@ -251,11 +278,13 @@ curl \
   --include \
   --data-binary "{\"id\": \"${AUTHOR_ID4}\", \"first_name\": \"Ernest\", \"last_name\": \"Hemingway\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   --request PUT \
   "${BOOKS_ADDR}/v1alpha1/author"
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/authors/${AUTHOR_ID4}")
 echo "${GET_RESULT}" | jq
 
@@ -265,6 +294,7 @@ echo ':: Deleting book East of Eden by ID:'
 curl \
   --show-error --fail \
   --include \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   --request DELETE \
   "${BOOKS_ADDR}/v1alpha1/books/${BOOK_ID4}"
 
@@ -275,6 +305,7 @@ RESPONSE_BOOK4=$(curl \
   --silent --show-error --fail \
   --data-binary "{\"author_id\": \"${AUTHOR_ID2}\", \"title\": \"East of Eden\", \"publish_date\": \"1952-09-19T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/book")
 BOOK_ID4=$(echo "${RESPONSE_BOOK4}" | jq '.book_id' -r)
 echo "Added back book East of Eden by John Steinbeck: ${BOOK_ID4}"
@@ -287,11 +318,13 @@ curl \
   --include \
   --data-binary "{\"id\": \"${BOOK_ID4}\", \"author_id\": \"${AUTHOR_ID2}\", \"title\": \"West of LA\", \"publish_date\": \"1970-01-01T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   --request PUT \
   "${BOOKS_ADDR}/v1alpha1/book"
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/books/${BOOK_ID4}")
 echo "${GET_RESULT}" | jq
 
@@ -300,10 +333,12 @@ curl \
   --include \
   --data-binary "{\"id\": \"${BOOK_ID4}\", \"author_id\": \"${AUTHOR_ID2}\", \"title\": \"East of Eden\", \"publish_date\": \"1952-09-19T00:00:00Z\"}" \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   --request PUT \
   "${BOOKS_ADDR}/v1alpha1/book"
 GET_RESULT=$(curl \
   --silent --show-error --fail \
   --header 'Content-Type: application/json' \
+  --header "Authorization: Bearer ${BOOKS_API_TOKEN}" \
   "${BOOKS_ADDR}/v1alpha1/books/${BOOK_ID4}")
 echo "${GET_RESULT}" | jq
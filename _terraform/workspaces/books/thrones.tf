@@ -17,6 +17,10 @@ resource "books_api_author" "grr_martin" {
 
   first_name = "George R.R."
   last_name  = "Martin"
+
+  # This is a test workspace, so also delete any books added outside of
+  # Terraform on `terraform destroy`.
+  force_destroy = true
 }
 
 resource "books_api_book" "song_fire_ice1" {
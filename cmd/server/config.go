@@ -0,0 +1,94 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dhermes/example-terraform-provider/pkg/server"
+)
+
+// loadConfig replaces `c` with the config from (in increasing order of
+// precedence) the defaults, the config file given by `--config` or
+// `BOOKS_CONFIG`, `BOOKS_*` environment variables and the flags explicitly
+// set on the command line. The result is validated.
+func loadConfig(cmd *cobra.Command, c *server.Config) error {
+	flags := cmd.Flags()
+	path, err := flags.GetString("config")
+	if err != nil {
+		return err
+	}
+	if !flags.Changed("config") {
+		path = os.Getenv(server.EnvVar("config"))
+	}
+
+	loaded, err := server.LoadConfig(path, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	for _, key := range server.ConfigKeys() {
+		f := flags.Lookup(strings.ReplaceAll(key, "_", "-"))
+		if f == nil || !f.Changed {
+			continue
+		}
+		err = loaded.Set(key, f.Value.String())
+		if err != nil {
+			return err
+		}
+	}
+	dev, err := flags.GetBool("dev")
+	if err != nil {
+		return err
+	}
+	if dev {
+		loaded.Storage = server.StorageMemory
+	}
+
+	err = loaded.Validate()
+	if err != nil {
+		return err
+	}
+	*c = loaded
+	return nil
+}
+
+// requireAddr fails if the `addr` key is not set; it is only needed to serve
+// the Books API, not by the other commands.
+func requireAddr(c server.Config) error {
+	if c.Addr == "" {
+		return errors.New(`"addr" is required`)
+	}
+	return nil
+}
+
+// configCommand returns the `config` command, which prints the effective
+// config with secrets redacted.
+func configCommand(c *server.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "config",
+		Short: "Print the effective server config",
+		Long: "Print the effective server config, merged from the config file, " +
+			"BOOKS_* environment variables and flags, as TOML. The password in " +
+			"the DSN is redacted.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return server.WriteConfig(cmd.OutOrStdout(), *c)
+		},
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/dhermes/example-terraform-provider/pkg/server"
+)
+
+// tokenCommand returns the `token` command group for managing Books API
+// tokens directly in the database.
+func tokenCommand(ctx context.Context, c *server.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage Books API tokens",
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			err := loadConfig(cmd, c)
+			if err != nil {
+				return err
+			}
+			if c.Storage != server.StorageSQL {
+				return fmt.Errorf("API tokens require %q to be %q", "storage", server.StorageSQL)
+			}
+			return nil
+		},
+	}
+	cmd.AddCommand(tokenCreateCommand(ctx, c))
+	cmd.AddCommand(tokenRevokeCommand(ctx, c))
+	return cmd
+}
+
+func tokenCreateCommand(ctx context.Context, c *server.Config) *cobra.Command {
+	name := ""
+	role := server.RoleReader
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Mint a new Books API token",
+		Long: "Mint a new Books API token. The token is printed once and cannot " +
+			"be recovered later; only a hash of it is stored.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, err := server.Context(ctx, *c)
+			if err != nil {
+				return err
+			}
+			defer server.Close(ctx)
+
+			token, id, err := server.MintAPIToken(ctx, name, role)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "ID:    %s\nRole:  %s\nToken: %s\n", id, role, token)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "A name describing who or what will use the token")
+	cmd.Flags().StringVar(&role, "role", role, "The role granted to the token: 'reader', 'editor' or 'admin'")
+	// NOTE: This can only fail if the flag does not exist.
+	_ = cmd.MarkFlagRequired("name")
+	return cmd
+}
+
+func tokenRevokeCommand(ctx context.Context, c *server.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke TOKEN_ID",
+		Short: "Revoke a Books API token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid token ID %q; %w", args[0], err)
+			}
+
+			ctx, err := server.Context(ctx, *c)
+			if err != nil {
+				return err
+			}
+			defer server.Close(ctx)
+
+			err = server.RevokeAPIToken(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Revoked token %s\n", id)
+			return nil
+		},
+	}
+	return cmd
+}
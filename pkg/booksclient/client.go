@@ -26,14 +26,30 @@ import (
 type Client interface {
 	AddAuthor(context.Context, Author) (*AddAuthorResponse, error)
 	UpdateAuthor(context.Context, Author) (*Empty, error)
+	PatchAuthor(context.Context, PatchAuthorRequest) (*Empty, error)
 	GetAuthorByID(context.Context, GetAuthorByIDRequest) (*Author, error)
 	GetAuthorByName(context.Context, GetAuthorByNameRequest) (*Author, error)
-	GetAuthors(context.Context, Empty) (*GetAuthorsResponse, error)
-	DeleteAuthorByID(context.Context, DeleteAuthorRequest) (*Empty, error)
+	GetAuthors(context.Context, GetAuthorsRequest) (*GetAuthorsResponse, error)
+	DeleteAuthorByID(context.Context, DeleteAuthorRequest) (*DeleteAuthorResponse, error)
+	RestoreAuthorByID(context.Context, RestoreAuthorRequest) (*Empty, error)
 
 	AddBook(context.Context, Book) (*AddBookResponse, error)
 	UpdateBook(context.Context, Book) (*Empty, error)
+	PatchBook(context.Context, PatchBookRequest) (*Empty, error)
 	GetBookByID(context.Context, GetBookByIDRequest) (*Book, error)
 	GetBooks(context.Context, GetBooksRequest) (*GetBooksResponse, error)
 	DeleteBookByID(context.Context, DeleteBookRequest) (*Empty, error)
+	RestoreBookByID(context.Context, RestoreBookRequest) (*Empty, error)
+
+	GetTrash(context.Context, GetTrashRequest) (*GetTrashResponse, error)
+	GetAudit(context.Context, GetAuditRequest) (*GetAuditResponse, error)
+	Watch(context.Context) (*Watcher, error)
+
+	AddWebhook(context.Context, AddWebhookRequest) (*AddWebhookResponse, error)
+	GetWebhooks(context.Context, GetWebhooksRequest) (*GetWebhooksResponse, error)
+	DeleteWebhookByID(context.Context, DeleteWebhookRequest) (*Empty, error)
+	GetWebhookDeadLetters(context.Context, GetWebhookDeadLettersRequest) (*GetWebhookDeadLettersResponse, error)
+
+	Batch(context.Context, BatchRequest) (*BatchResponse, error)
+	Search(context.Context, SearchRequest) (*SearchResponse, error)
 }
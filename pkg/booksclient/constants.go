@@ -0,0 +1,56 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package booksclient
+
+const (
+	// HeaderRequestID is the canonicalized header used to send (and receive)
+	// a request ID.
+	HeaderRequestID = "X-Request-Id"
+	// HeaderAuthorization is the canonicalized header used to send the
+	// API token.
+	HeaderAuthorization = "Authorization"
+	// HeaderIfMatch is the canonicalized header used to make an update or
+	// delete conditional on the version of a resource.
+	HeaderIfMatch = "If-Match"
+	// HeaderIdempotencyKey is the canonicalized header used to make a create
+	// request safe to retry.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderActor is the canonicalized header used to name the person or
+	// system a request is made on behalf of, for the audit log.
+	HeaderActor = "X-Actor"
+	// HeaderLastEventID is the canonicalized header used to resume an event
+	// stream after the last event received.
+	HeaderLastEventID = "Last-Event-Id"
+	// HeaderWebhookEventID is the canonicalized header carrying the event ID
+	// of a webhook delivery.
+	HeaderWebhookEventID = "X-Books-Event-Id"
+	// HeaderWebhookTimestamp is the canonicalized header carrying the Unix
+	// time a webhook delivery was signed.
+	HeaderWebhookTimestamp = "X-Books-Timestamp"
+	// HeaderWebhookSignature is the canonicalized header carrying the
+	// signature of a webhook delivery.
+	HeaderWebhookSignature = "X-Books-Signature"
+	// MetadataError is the gRPC trailer key carrying the JSON error body of a
+	// failed RPC.
+	MetadataError = "books-error-bin"
+	// MetadataStatus is the gRPC trailer key carrying the HTTP status code
+	// equivalent to the status of a failed RPC; it is also sent (as `200`) in
+	// the header of an accepted `Watch` stream.
+	MetadataStatus = "books-status"
+	// MetadataLastEventID is the gRPC header key carrying the ID of the event
+	// an accepted `Watch` stream starts after, so a client can resume from
+	// there even if no event has been sent yet.
+	MetadataLastEventID = "books-last-event-id"
+)
@@ -0,0 +1,311 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package booksclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// NOTE: Ensure that
+//       * `*APIError` satisfies `error`.
+//       * `*PermissionDeniedError` satisfies `error`.
+//       * `*BatchError` satisfies `error`.
+var (
+	_ error = (*APIError)(nil)
+	_ error = (*PermissionDeniedError)(nil)
+	_ error = (*BatchError)(nil)
+)
+
+const (
+	// ErrorCodeInvalidArgument indicates a malformed or missing input.
+	ErrorCodeInvalidArgument = "invalid_argument"
+	// ErrorCodeUnauthenticated indicates a missing, malformed or revoked
+	// API token.
+	ErrorCodeUnauthenticated = "unauthenticated"
+	// ErrorCodePermissionDenied indicates the API token is valid, but its
+	// role does not allow the request.
+	ErrorCodePermissionDenied = "permission_denied"
+	// ErrorCodeNotFound indicates the requested resource does not exist.
+	ErrorCodeNotFound = "not_found"
+	// ErrorCodeRouteNotFound indicates the server has no route for the
+	// request path, e.g. because the address has an unexpected path prefix.
+	ErrorCodeRouteNotFound = "route_not_found"
+	// ErrorCodeMethodNotAllowed indicates an unsupported HTTP method.
+	ErrorCodeMethodNotAllowed = "method_not_allowed"
+	// ErrorCodeAlreadyExists indicates a uniqueness conflict.
+	ErrorCodeAlreadyExists = "already_exists"
+	// ErrorCodeHasDependents indicates a resource cannot be removed because
+	// other resources still refer to it.
+	ErrorCodeHasDependents = "has_dependents"
+	// ErrorCodeInvalidReference indicates the request refers to a resource
+	// that does not exist.
+	ErrorCodeInvalidReference = "invalid_reference"
+	// ErrorCodeFailedPrecondition indicates an `If-Match` precondition did not
+	// hold, i.e. the resource was modified since it was last read.
+	ErrorCodeFailedPrecondition = "failed_precondition"
+	// ErrorCodeRequestInProgress indicates a request with the same
+	// idempotency key is still being handled; it is safe to retry once that
+	// request has finished.
+	ErrorCodeRequestInProgress = "request_in_progress"
+	// ErrorCodeDeadlineExceeded indicates the request did not complete
+	// before its deadline.
+	ErrorCodeDeadlineExceeded = "deadline_exceeded"
+	// ErrorCodeUnimplemented indicates the request needs a feature the
+	// server does not have enabled, e.g. the audit log on a server that
+	// stores data in memory.
+	ErrorCodeUnimplemented = "unimplemented"
+	// ErrorCodeInternal indicates an unexpected server-side failure.
+	ErrorCodeInternal = "internal"
+)
+
+var (
+	// ErrUnauthenticated can be used with `errors.Is()` to check if a request
+	// failed because the API token is missing or invalid.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied can be used with `errors.Is()` to check if a
+	// request failed because the API token's role does not allow it.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrNotFound can be used with `errors.Is()` to check if a request failed
+	// because the resource does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists can be used with `errors.Is()` to check if a request
+	// failed because of a uniqueness conflict.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrHasDependents can be used with `errors.Is()` to check if a request
+	// failed because other resources still refer to the resource.
+	ErrHasDependents = errors.New("has dependents")
+	// ErrInvalidReference can be used with `errors.Is()` to check if a
+	// request failed because it refers to a resource that does not exist.
+	ErrInvalidReference = errors.New("invalid reference")
+	// ErrInvalidArgument can be used with `errors.Is()` to check if a request
+	// failed because of a malformed or missing input.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrPreconditionFailed can be used with `errors.Is()` to check if a
+	// conditional update or delete failed because the resource was modified
+	// since it was last read.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrRequestInProgress can be used with `errors.Is()` to check if a
+	// create request failed because a request with the same idempotency key
+	// was still in progress.
+	ErrRequestInProgress = errors.New("request in progress")
+	// ErrDeadlineExceeded can be used with `errors.Is()` to check if a
+	// request failed because the server timed out handling it.
+	ErrDeadlineExceeded = errors.New("deadline exceeded")
+)
+
+// APIError is a failed response from the Books API. Use `errors.As()` to
+// access the details or `errors.Is()` with one of the `Err*` sentinels to
+// check the category.
+type APIError struct {
+	// Action describes the client call that failed, e.g. "add author".
+	Action string `json:"-"`
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"-"`
+	// Code is a machine readable error code, e.g. `not_found`.
+	Code string `json:"code"`
+	// Message is a human readable description of the failure.
+	Message string `json:"message"`
+	// Field is the request field that caused the failure, if known.
+	Field string `json:"field,omitempty"`
+	// RequestID identifies the request in the server logs.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Error satisfies the `error` interface.
+func (ae *APIError) Error() string {
+	msg := fmt.Sprintf("failed to %s (status %d, code %q): %s", ae.Action, ae.StatusCode, ae.Code, ae.Message)
+	if ae.Field != "" {
+		msg += fmt.Sprintf(" (field %q)", ae.Field)
+	}
+	if ae.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", ae.RequestID)
+	}
+	return msg
+}
+
+// Is allows `errors.Is()` to match an `APIError` against the `Err*` sentinels.
+func (ae *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthenticated:
+		return ae.Code == ErrorCodeUnauthenticated
+	case ErrPermissionDenied:
+		return ae.Code == ErrorCodePermissionDenied
+	case ErrNotFound:
+		return ae.Code == ErrorCodeNotFound
+	case ErrAlreadyExists:
+		return ae.Code == ErrorCodeAlreadyExists
+	case ErrHasDependents:
+		return ae.Code == ErrorCodeHasDependents
+	case ErrInvalidReference:
+		return ae.Code == ErrorCodeInvalidReference
+	case ErrInvalidArgument:
+		return ae.Code == ErrorCodeInvalidArgument
+	case ErrPreconditionFailed:
+		return ae.Code == ErrorCodeFailedPrecondition
+	case ErrRequestInProgress:
+		return ae.Code == ErrorCodeRequestInProgress
+	case ErrDeadlineExceeded:
+		return ae.Code == ErrorCodeDeadlineExceeded
+	default:
+		return false
+	}
+}
+
+// IsNotFound is a convenience wrapper for `errors.Is(err, ErrNotFound)`. It
+// only matches a structured `not_found` error from the Books API, not a bare
+// `404 Not Found` from something in front of it.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// PermissionDeniedError is returned (instead of a bare `APIError`) when the
+// API token is valid but its role does not allow the request, e.g. a
+// `reader` token used to add an author. Use `errors.As()` to detect it; the
+// embedded `APIError` provides `Error()` and `Is()`.
+type PermissionDeniedError struct {
+	*APIError
+}
+
+// Unwrap allows `errors.As()` to reach the underlying `APIError`.
+func (pde *PermissionDeniedError) Unwrap() error {
+	return pde.APIError
+}
+
+// BatchError is returned (instead of a bare `APIError`) when at least one
+// operation in a batch failed and so the batch was rolled back. The embedded
+// `APIError` describes the first failure; `Operations` lists every failure.
+type BatchError struct {
+	*APIError
+	// Operations has one entry per failed operation.
+	Operations []BatchOperationError `json:"operations"`
+}
+
+// Unwrap allows `errors.As()` to reach the underlying `APIError`.
+func (be *BatchError) Unwrap() error {
+	return be.APIError
+}
+
+// BatchOperationError describes a single failed operation in a batch.
+type BatchOperationError struct {
+	// Index is the position of the operation in the batch.
+	Index int `json:"index"`
+	// Code is a machine readable error code, e.g. `invalid_reference`.
+	Code string `json:"code"`
+	// Message is a human readable description of the failure.
+	Message string `json:"message"`
+	// Field is the operation field that caused the failure, if known.
+	Field string `json:"field,omitempty"`
+}
+
+// IsPreconditionFailed is a convenience wrapper for
+// `errors.Is(err, ErrPreconditionFailed)`.
+func IsPreconditionFailed(err error) bool {
+	return errors.Is(err, ErrPreconditionFailed)
+}
+
+// IsRequestInProgress is a convenience wrapper for
+// `errors.Is(err, ErrRequestInProgress)`.
+func IsRequestInProgress(err error) bool {
+	return errors.Is(err, ErrRequestInProgress)
+}
+
+// IsPermissionDenied is a convenience wrapper for
+// `errors.Is(err, ErrPermissionDenied)`.
+func IsPermissionDenied(err error) bool {
+	return errors.Is(err, ErrPermissionDenied)
+}
+
+// newAPIError decodes the body of a failed response into an `APIError`. If
+// the body is not a structured error, the raw body becomes the message.
+func newAPIError(resp *http.Response, action string) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	requestID := resp.Header.Get(HeaderRequestID)
+	if requestID == "" && resp.Request != nil {
+		requestID = resp.Request.Header.Get(HeaderRequestID)
+	}
+	return decodeAPIError(body, action, resp.StatusCode, requestID)
+}
+
+// decodeAPIError decodes a JSON error body (from an HTTP response or a gRPC
+// trailer) into an `APIError`, wrapped as a `PermissionDeniedError` or
+// `BatchError` when appropriate.
+func decodeAPIError(body []byte, action string, statusCode int, requestID string) error {
+	ae := APIError{}
+	err := json.Unmarshal(body, &ae)
+	if err != nil || ae.Code == "" {
+		ae = APIError{Message: string(body)}
+	}
+
+	ae.Action = action
+	ae.StatusCode = statusCode
+	if ae.RequestID == "" {
+		ae.RequestID = requestID
+	}
+	if ae.Code == "" {
+		ae.Code = codeFromStatus(statusCode)
+	}
+	if ae.Code == ErrorCodePermissionDenied {
+		return &PermissionDeniedError{APIError: &ae}
+	}
+
+	be := BatchError{}
+	err = json.Unmarshal(body, &be)
+	if err == nil && len(be.Operations) > 0 {
+		be.APIError = &ae
+		return &be
+	}
+	return &ae
+}
+
+// codeFromStatus infers an error code for responses that did not include
+// one, e.g. from a proxy in front of the Books API.
+//
+// A `404 Not Found` without a structured body is never treated as
+// `not_found`. It most likely means the request did not reach the Books API
+// at all (e.g. a wrong address or path prefix, or a load balancer with no
+// backend) and callers such as the Terraform provider treat `not_found` as
+// "the resource is gone", which would drop every resource from state.
+func codeFromStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return ErrorCodeInvalidArgument
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthenticated
+	case http.StatusForbidden:
+		return ErrorCodePermissionDenied
+	case http.StatusMethodNotAllowed:
+		return ErrorCodeMethodNotAllowed
+	case http.StatusConflict:
+		return ErrorCodeAlreadyExists
+	case http.StatusUnprocessableEntity:
+		return ErrorCodeInvalidReference
+	case http.StatusPreconditionFailed:
+		return ErrorCodeFailedPrecondition
+	case http.StatusGatewayTimeout:
+		return ErrorCodeDeadlineExceeded
+	case http.StatusNotImplemented:
+		return ErrorCodeUnimplemented
+	default:
+		return ErrorCodeInternal
+	}
+}
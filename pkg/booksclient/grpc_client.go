@@ -0,0 +1,941 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package booksclient
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dhermes/example-terraform-provider/pkg/bookspb"
+)
+
+const (
+	// GRPCScheme is the scheme of a Books API address that selects the gRPC
+	// transport, e.g. `grpc://localhost:7535`.
+	GRPCScheme = "grpc"
+	// GRPCSecureScheme is the scheme of a Books API address that selects the
+	// gRPC transport over TLS, e.g. `grpcs://books.example.com:7535`.
+	GRPCSecureScheme = "grpcs"
+)
+
+// NOTE: Ensure that
+//       * `GRPCClient` satisfies `Client`.
+var (
+	_ Client = (*GRPCClient)(nil)
+)
+
+// GRPCClient is a gRPC implementation for the Books API.
+//
+// Errors are the same as for `HTTPClient`, e.g. an `*APIError` that can be
+// checked with `errors.Is()` against the `Err*` sentinels.
+type GRPCClient struct {
+	// Addr is the address for the gRPC transport of the Books API, e.g.
+	// `grpc://localhost:7535` or (for TLS) `grpcs://books.example.com:7535`.
+	Addr string
+	// Token is the API token sent as a bearer token with every RPC.
+	Token string
+
+	conn  *grpc.ClientConn
+	books bookspb.BooksClient
+}
+
+// NewGRPCClient returns a new `GRPCClient`, configured with the same options
+// as `NewHTTPClient()`. TLS is used for a `grpcs://` address, with the TLS
+// options if any were provided. The connection is established lazily, so an
+// unreachable server is only reported by the first RPC.
+func NewGRPCClient(opts ...Option) (*GRPCClient, error) {
+	hc, err := NewHTTPClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	target := strings.TrimPrefix(hc.Addr, GRPCScheme+"://")
+	creds := insecure.NewCredentials()
+	if strings.HasPrefix(hc.Addr, GRPCSecureScheme+"://") {
+		target = strings.TrimPrefix(hc.Addr, GRPCSecureScheme+"://")
+		tc := hc.TLSConfig
+		if tc == nil {
+			tc = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		creds = credentials.NewTLS(tc)
+	} else if hc.TLSConfig != nil {
+		return nil, fmt.Errorf("TLS options require a %s:// address, not %q", GRPCSecureScheme, hc.Addr)
+	}
+
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	gc := &GRPCClient{
+		Addr:  hc.Addr,
+		Token: hc.Token,
+		conn:  conn,
+		books: bookspb.NewBooksClient(conn),
+	}
+	return gc, nil
+}
+
+// Close closes the connection to the Books API.
+func (gc *GRPCClient) Close() error {
+	return gc.conn.Close()
+}
+
+// outgoingContext attaches the request ID (from `WithRequestID()` or newly
+// generated), the API token and the actor to `ctx` as gRPC metadata, along
+// with an idempotency key for a create RPC. It also returns the request ID.
+func (gc *GRPCClient) outgoingContext(ctx context.Context, idempotent bool) (context.Context, string) {
+	id := getRequestID(ctx)
+	if id == "" {
+		id = uuid.NewString()
+	}
+	kv := []string{strings.ToLower(HeaderRequestID), id}
+	if gc.Token != "" {
+		kv = append(kv, strings.ToLower(HeaderAuthorization), "Bearer "+gc.Token)
+	}
+	if actor := getActor(ctx); actor != "" {
+		kv = append(kv, strings.ToLower(HeaderActor), actor)
+	}
+	if idempotent {
+		key := getIdempotencyKey(ctx)
+		if key == "" {
+			key = uuid.NewString()
+		}
+		kv = append(kv, strings.ToLower(HeaderIdempotencyKey), key)
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, kv...), id
+}
+
+// invoke makes a unary RPC via `call`. A create RPC (`idempotent`) is sent
+// with an idempotency key and retried with the same key if the server is
+// unavailable or the original request is still in progress, in the same way
+// as `HTTPClient.doIdempotent()`.
+func (gc *GRPCClient) invoke(ctx context.Context, action string, idempotent bool, call func(context.Context, ...grpc.CallOption) error) error {
+	ctx, id := gc.outgoingContext(ctx, idempotent)
+
+	for attempt := 1; ; attempt++ {
+		var trailer metadata.MD
+		err := call(ctx, grpc.Trailer(&trailer))
+		if err == nil {
+			return nil
+		}
+
+		apiErr := newGRPCError(err, trailer, action, id)
+		attempts := 1
+		if idempotent && status.Code(err) == codes.Unavailable {
+			attempts = idempotentAttempts
+		}
+		if idempotent && IsRequestInProgress(apiErr) {
+			attempts = inProgressAttempts
+		}
+		if attempt >= attempts || ctx.Err() != nil {
+			return apiErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return apiErr
+		case <-time.After(time.Duration(attempt) * idempotentRetryDelay):
+		}
+	}
+}
+
+// newGRPCError converts a failed RPC into an `APIError` (via the JSON error
+// body in the trailer). An RPC that failed without reaching the Books API,
+// e.g. because the connection could not be established, is returned as is.
+func newGRPCError(err error, trailer metadata.MD, action, requestID string) error {
+	body := trailer.Get(MetadataError)
+	if len(body) == 0 {
+		return fmt.Errorf("%w (request ID %s)", err, requestID)
+	}
+
+	statusCode := 0
+	if values := trailer.Get(MetadataStatus); len(values) > 0 {
+		statusCode, _ = strconv.Atoi(values[0])
+	}
+	return decodeAPIError([]byte(body[0]), action, statusCode, requestID)
+}
+
+// AddAuthor adds a new author to be stored in the books service.
+func (gc *GRPCClient) AddAuthor(ctx context.Context, a Author) (*AddAuthorResponse, error) {
+	var resp *bookspb.AddAuthorResponse
+	err := gc.invoke(ctx, "add author", true, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.AddAuthor(ctx, authorToProto(a), opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(resp.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+	return &AddAuthorResponse{AuthorID: id}, nil
+}
+
+// UpdateAuthor updates an author stored in the books service.
+func (gc *GRPCClient) UpdateAuthor(ctx context.Context, a Author) (*Empty, error) {
+	err := gc.invoke(ctx, "update author", false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := gc.books.UpdateAuthor(ctx, authorToProto(a), opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+// PatchAuthor partially updates an author stored in the books service; only
+// the fields that are set are changed.
+func (gc *GRPCClient) PatchAuthor(ctx context.Context, par PatchAuthorRequest) (*Empty, error) {
+	id := par.AuthorID.String()
+	req := &bookspb.PatchAuthorRequest{
+		AuthorId:  &id,
+		FirstName: par.FirstName,
+		LastName:  par.LastName,
+	}
+	if par.Version != 0 {
+		req.Version = &par.Version
+	}
+
+	err := gc.invoke(ctx, "patch author", false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := gc.books.PatchAuthor(ctx, req, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+// GetAuthorByID gets an author by ID from the books service.
+func (gc *GRPCClient) GetAuthorByID(ctx context.Context, gar GetAuthorByIDRequest) (*Author, error) {
+	var resp *bookspb.Author
+	err := gc.invoke(ctx, "get author by ID", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.GetAuthorByID(ctx, &bookspb.GetAuthorByIDRequest{AuthorId: gar.AuthorID.String()}, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return authorFromProto(resp)
+}
+
+// GetAuthorByName gets an author by name from the books service.
+func (gc *GRPCClient) GetAuthorByName(ctx context.Context, gar GetAuthorByNameRequest) (*Author, error) {
+	req := &bookspb.GetAuthorByNameRequest{FirstName: gar.FirstName, LastName: gar.LastName}
+	var resp *bookspb.Author
+	err := gc.invoke(ctx, "get author by name", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.GetAuthorByName(ctx, req, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return authorFromProto(resp)
+}
+
+// GetAuthors gets a page of authors from the books service.
+func (gc *GRPCClient) GetAuthors(ctx context.Context, gar GetAuthorsRequest) (*GetAuthorsResponse, error) {
+	req := &bookspb.GetAuthorsRequest{
+		NamePrefix: gar.NamePrefix,
+		OrderBy:    gar.OrderBy,
+		PageSize:   int32(gar.PageSize),
+		PageToken:  gar.PageToken,
+	}
+	var resp *bookspb.GetAuthorsResponse
+	err := gc.invoke(ctx, "get authors", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.GetAuthors(ctx, req, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := GetAuthorsResponse{
+		Authors:       make([]Author, len(resp.Authors)),
+		NextPageToken: resp.NextPageToken,
+	}
+	for i, a := range resp.Authors {
+		author, err := authorFromProto(a)
+		if err != nil {
+			return nil, err
+		}
+		response.Authors[i] = *author
+	}
+	return &response, nil
+}
+
+// DeleteAuthorByID deletes an author by ID from the books service.
+func (gc *GRPCClient) DeleteAuthorByID(ctx context.Context, dar DeleteAuthorRequest) (*DeleteAuthorResponse, error) {
+	req := &bookspb.DeleteAuthorRequest{
+		AuthorId: dar.AuthorID.String(),
+		Version:  dar.Version,
+		Cascade:  dar.Cascade,
+	}
+	var resp *bookspb.DeleteAuthorResponse
+	err := gc.invoke(ctx, "delete author", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.DeleteAuthorByID(ctx, req, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteAuthorResponse{BooksDeleted: resp.BooksDeleted}, nil
+}
+
+// RestoreAuthorByID restores a deleted author from the trash.
+func (gc *GRPCClient) RestoreAuthorByID(ctx context.Context, rar RestoreAuthorRequest) (*Empty, error) {
+	err := gc.invoke(ctx, "restore author", false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := gc.books.RestoreAuthorByID(ctx, &bookspb.RestoreAuthorRequest{AuthorId: rar.AuthorID.String()}, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+// AddBook adds a new book to be stored in the books service.
+func (gc *GRPCClient) AddBook(ctx context.Context, b Book) (*AddBookResponse, error) {
+	var resp *bookspb.AddBookResponse
+	err := gc.invoke(ctx, "add book", true, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.AddBook(ctx, bookToProto(b), opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(resp.BookId)
+	if err != nil {
+		return nil, err
+	}
+	return &AddBookResponse{BookID: id}, nil
+}
+
+// UpdateBook updates a book stored in the books service.
+func (gc *GRPCClient) UpdateBook(ctx context.Context, b Book) (*Empty, error) {
+	err := gc.invoke(ctx, "update book", false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := gc.books.UpdateBook(ctx, bookToProto(b), opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+// PatchBook partially updates a book stored in the books service; only the
+// fields that are set are changed.
+func (gc *GRPCClient) PatchBook(ctx context.Context, pbr PatchBookRequest) (*Empty, error) {
+	id := pbr.BookID.String()
+	req := &bookspb.PatchBookRequest{
+		BookId:      &id,
+		Title:       pbr.Title,
+		PublishDate: timeToProto(pbr.PublishDate),
+	}
+	if pbr.AuthorID != nil {
+		authorID := pbr.AuthorID.String()
+		req.AuthorId = &authorID
+	}
+	if pbr.Version != 0 {
+		req.Version = &pbr.Version
+	}
+
+	err := gc.invoke(ctx, "patch book", false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := gc.books.PatchBook(ctx, req, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+// GetBookByID gets a book by ID from the books service.
+func (gc *GRPCClient) GetBookByID(ctx context.Context, gbr GetBookByIDRequest) (*Book, error) {
+	var resp *bookspb.Book
+	err := gc.invoke(ctx, "get book by ID", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.GetBookByID(ctx, &bookspb.GetBookByIDRequest{BookId: gbr.BookID.String()}, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bookFromProto(resp)
+}
+
+// GetBooks gets a page of books from the books service.
+func (gc *GRPCClient) GetBooks(ctx context.Context, gbr GetBooksRequest) (*GetBooksResponse, error) {
+	req := &bookspb.GetBooksRequest{
+		AuthorId:         uuidToProto(gbr.AuthorID),
+		TitleContains:    gbr.TitleContains,
+		AuthorNamePrefix: gbr.AuthorNamePrefix,
+		PublishedAfter:   timeToProto(gbr.PublishedAfter),
+		PublishedBefore:  timeToProto(gbr.PublishedBefore),
+		OrderBy:          gbr.OrderBy,
+		PageSize:         int32(gbr.PageSize),
+		PageToken:        gbr.PageToken,
+	}
+	var resp *bookspb.GetBooksResponse
+	err := gc.invoke(ctx, "get books", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.GetBooks(ctx, req, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := GetBooksResponse{
+		Books:         make([]Book, len(resp.Books)),
+		NextPageToken: resp.NextPageToken,
+	}
+	for i, b := range resp.Books {
+		book, err := bookFromProto(b)
+		if err != nil {
+			return nil, err
+		}
+		response.Books[i] = *book
+	}
+	return &response, nil
+}
+
+// DeleteBookByID deletes a book by ID from the books service.
+func (gc *GRPCClient) DeleteBookByID(ctx context.Context, dbr DeleteBookRequest) (*Empty, error) {
+	req := &bookspb.DeleteBookRequest{BookId: dbr.BookID.String(), Version: dbr.Version}
+	err := gc.invoke(ctx, "delete book", false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := gc.books.DeleteBookByID(ctx, req, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+// RestoreBookByID restores a deleted book from the trash.
+func (gc *GRPCClient) RestoreBookByID(ctx context.Context, rbr RestoreBookRequest) (*Empty, error) {
+	err := gc.invoke(ctx, "restore book", false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := gc.books.RestoreBookByID(ctx, &bookspb.RestoreBookRequest{BookId: rbr.BookID.String()}, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+// GetTrash lists the deleted authors and books that can still be restored.
+func (gc *GRPCClient) GetTrash(ctx context.Context, _ GetTrashRequest) (*GetTrashResponse, error) {
+	var resp *bookspb.GetTrashResponse
+	err := gc.invoke(ctx, "get trash", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.GetTrash(ctx, &bookspb.GetTrashRequest{}, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := GetTrashResponse{
+		Authors: make([]Author, len(resp.Authors)),
+		Books:   make([]Book, len(resp.Books)),
+	}
+	for i, a := range resp.Authors {
+		author, err := authorFromProto(a)
+		if err != nil {
+			return nil, err
+		}
+		response.Authors[i] = *author
+	}
+	for i, b := range resp.Books {
+		book, err := bookFromProto(b)
+		if err != nil {
+			return nil, err
+		}
+		response.Books[i] = *book
+	}
+	return &response, nil
+}
+
+// GetAudit gets a page of the audit log, newest events first.
+func (gc *GRPCClient) GetAudit(ctx context.Context, gar GetAuditRequest) (*GetAuditResponse, error) {
+	req := &bookspb.GetAuditRequest{
+		EntityType:     gar.EntityType,
+		EntityId:       uuidToProto(gar.EntityID),
+		Actor:          gar.Actor,
+		OccurredAfter:  timeToProto(gar.OccurredAfter),
+		OccurredBefore: timeToProto(gar.OccurredBefore),
+		PageSize:       int32(gar.PageSize),
+		PageToken:      gar.PageToken,
+	}
+	var resp *bookspb.GetAuditResponse
+	err := gc.invoke(ctx, "get audit", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.GetAudit(ctx, req, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := GetAuditResponse{
+		Events:        make([]AuditEvent, len(resp.Events)),
+		NextPageToken: resp.NextPageToken,
+	}
+	for i, e := range resp.Events {
+		entityID, err := uuid.Parse(e.EntityId)
+		if err != nil {
+			return nil, err
+		}
+		apiTokenID, err := optionalUUIDFromProto(e.ApiTokenId)
+		if err != nil {
+			return nil, err
+		}
+		response.Events[i] = AuditEvent{
+			ID:         e.Id,
+			OccurredAt: e.OccurredAt.AsTime(),
+			Actor:      e.Actor,
+			APITokenID: apiTokenID,
+			Action:     e.Action,
+			EntityType: e.EntityType,
+			EntityID:   entityID,
+			Before:     jsonFromProto(e.Before),
+			After:      jsonFromProto(e.After),
+		}
+	}
+	return &response, nil
+}
+
+// Watch streams changes to authors and books made after it is called.
+//
+// The stream is reconnected automatically, resuming after the last event
+// received, whenever it ends or fails. The events channel is closed once
+// `ctx` is done, or when reconnecting fails with an error that retrying
+// cannot fix (see `Watcher.Err()`). An error is returned if the first
+// connection fails.
+func (gc *GRPCClient) Watch(ctx context.Context) (*Watcher, error) {
+	stream, lastEventID, err := gc.openWatch(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w := newWatcher()
+	go gc.watch(ctx, stream, lastEventID, w)
+	return w, nil
+}
+
+// openWatch opens an event stream, resuming after `lastEventID` if set. It
+// also returns the ID of the event the stream starts after, if the server
+// sent it.
+func (gc *GRPCClient) openWatch(ctx context.Context, lastEventID *int64) (bookspb.Books_WatchClient, *int64, error) {
+	ctx, id := gc.outgoingContext(ctx, false)
+	stream, err := gc.books.Watch(ctx, &bookspb.WatchRequest{LastEventId: lastEventID})
+	if err != nil {
+		return nil, nil, newGRPCError(err, nil, "watch events", id)
+	}
+
+	// NOTE: The server sends the header once the stream is accepted, so
+	//       waiting for it surfaces e.g. an invalid API token right away.
+	md, err := stream.Header()
+	if err == nil && len(md.Get(MetadataStatus)) == 0 {
+		// NOTE: A stream that fails before it is accepted has no header,
+		//       only trailers; in that case `Recv()` returns the error.
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		return nil, nil, newGRPCError(err, stream.Trailer(), "watch events", id)
+	}
+
+	if values := md.Get(MetadataLastEventID); len(values) > 0 {
+		start, err := strconv.ParseInt(values[0], 10, 64)
+		if err == nil {
+			lastEventID = &start
+		}
+	}
+	return stream, lastEventID, nil
+}
+
+// watch sends the events from `stream` (and every reconnected stream after
+// it) on `w` until `ctx` is done or a reconnection fails for good; streams
+// are resumed after `lastEventID`, which is updated as events are received.
+func (gc *GRPCClient) watch(ctx context.Context, stream bookspb.Books_WatchClient, lastEventID *int64, w *Watcher) {
+	failures := 0
+	for {
+		for stream != nil {
+			e, err := stream.Recv()
+			if err != nil {
+				break
+			}
+			event, err := eventFromProto(e)
+			if err != nil {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				w.stop(nil)
+				return
+			case w.events <- *event:
+			}
+			lastEventID = &event.ID
+		}
+
+		delay := watchRetryDelay << failures
+		if delay > watchMaxRetryDelay || delay <= 0 {
+			delay = watchMaxRetryDelay
+		}
+		select {
+		case <-ctx.Done():
+			w.stop(nil)
+			return
+		case <-time.After(delay):
+		}
+
+		var start *int64
+		var err error
+		stream, start, err = gc.openWatch(ctx, lastEventID)
+		if isTerminalWatchError(err) || isTerminalGRPCError(err) {
+			w.stop(err)
+			return
+		}
+		if err != nil {
+			failures++
+			continue
+		}
+		lastEventID = start
+		failures = 0
+	}
+}
+
+// isTerminalGRPCError determines if `err` has a gRPC status that retrying
+// cannot fix. It covers failures without a structured error, e.g. a server
+// that does not implement the method at all.
+func isTerminalGRPCError(err error) bool {
+	// NOTE: `status.Code()` does not unwrap, and `newGRPCError()` wraps.
+	var se interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &se) {
+		return false
+	}
+
+	switch se.GRPCStatus().Code() {
+	case codes.Unauthenticated, codes.PermissionDenied, codes.Unimplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// AddWebhook subscribes a URL to changes to authors and books. The returned
+// secret is used to verify deliveries (see `VerifyWebhook()`).
+func (gc *GRPCClient) AddWebhook(ctx context.Context, awr AddWebhookRequest) (*AddWebhookResponse, error) {
+	var resp *bookspb.AddWebhookResponse
+	err := gc.invoke(ctx, "add webhook", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.AddWebhook(ctx, &bookspb.AddWebhookRequest{Url: awr.URL}, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wh, err := webhookFromProto(resp.Webhook)
+	if err != nil {
+		return nil, err
+	}
+	return &AddWebhookResponse{Webhook: *wh, Secret: resp.Secret}, nil
+}
+
+// GetWebhooks lists the webhooks subscribed to changes.
+func (gc *GRPCClient) GetWebhooks(ctx context.Context, _ GetWebhooksRequest) (*GetWebhooksResponse, error) {
+	var resp *bookspb.GetWebhooksResponse
+	err := gc.invoke(ctx, "get webhooks", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.GetWebhooks(ctx, &bookspb.GetWebhooksRequest{}, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := GetWebhooksResponse{Webhooks: make([]Webhook, len(resp.Webhooks))}
+	for i, w := range resp.Webhooks {
+		wh, err := webhookFromProto(w)
+		if err != nil {
+			return nil, err
+		}
+		response.Webhooks[i] = *wh
+	}
+	return &response, nil
+}
+
+// DeleteWebhookByID unsubscribes a webhook.
+func (gc *GRPCClient) DeleteWebhookByID(ctx context.Context, dwr DeleteWebhookRequest) (*Empty, error) {
+	err := gc.invoke(ctx, "delete webhook", false, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := gc.books.DeleteWebhookByID(ctx, &bookspb.DeleteWebhookRequest{WebhookId: dwr.WebhookID.String()}, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+// GetWebhookDeadLetters lists the deliveries for a webhook that failed too
+// many times to be retried.
+func (gc *GRPCClient) GetWebhookDeadLetters(ctx context.Context, gwr GetWebhookDeadLettersRequest) (*GetWebhookDeadLettersResponse, error) {
+	req := &bookspb.GetWebhookDeadLettersRequest{WebhookId: gwr.WebhookID.String(), PageSize: int32(gwr.PageSize)}
+	var resp *bookspb.GetWebhookDeadLettersResponse
+	err := gc.invoke(ctx, "get webhook dead letters", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.GetWebhookDeadLetters(ctx, req, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := GetWebhookDeadLettersResponse{DeadLetters: make([]DeadLetter, len(resp.DeadLetters))}
+	for i, dl := range resp.DeadLetters {
+		event, err := eventFromProto(dl.Event)
+		if err != nil {
+			return nil, err
+		}
+		response.DeadLetters[i] = DeadLetter{
+			ID:        dl.Id,
+			Event:     *event,
+			Attempts:  int(dl.Attempts),
+			LastError: dl.LastError,
+			DeadAt:    dl.DeadAt.AsTime(),
+		}
+	}
+	return &response, nil
+}
+
+// Batch runs a batch of operations in order, in a single transaction.
+func (gc *GRPCClient) Batch(ctx context.Context, br BatchRequest) (*BatchResponse, error) {
+	req := &bookspb.BatchRequest{Operations: make([]*bookspb.BatchOperation, len(br.Operations))}
+	for i, op := range br.Operations {
+		body, err := json.Marshal(op.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Operations[i] = &bookspb.BatchOperation{
+			Action:   op.Action,
+			Resource: op.Resource,
+			Body:     string(body),
+			Version:  op.Version,
+		}
+	}
+
+	var resp *bookspb.BatchResponse
+	err := gc.invoke(ctx, "run batch", true, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.Batch(ctx, req, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := BatchResponse{Results: make([]map[string]interface{}, len(resp.Results))}
+	for i, result := range resp.Results {
+		err = json.Unmarshal([]byte(result), &response.Results[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &response, nil
+}
+
+// Search runs a full-text search over book titles and author names.
+func (gc *GRPCClient) Search(ctx context.Context, sr SearchRequest) (*SearchResponse, error) {
+	req := &bookspb.SearchRequest{Query: sr.Query, Limit: int32(sr.Limit)}
+	var resp *bookspb.SearchResponse
+	err := gc.invoke(ctx, "search", false, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = gc.books.Search(ctx, req, opts...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := SearchResponse{Results: make([]SearchResult, len(resp.Results))}
+	for i, result := range resp.Results {
+		id, err := uuid.Parse(result.Id)
+		if err != nil {
+			return nil, err
+		}
+		response.Results[i] = SearchResult{
+			Kind:    result.Kind,
+			ID:      id,
+			Snippet: result.Snippet,
+			Score:   result.Score,
+		}
+	}
+	return &response, nil
+}
+
+func authorToProto(a Author) *bookspb.Author {
+	pa := &bookspb.Author{
+		FirstName: a.FirstName,
+		LastName:  a.LastName,
+		Version:   a.Version,
+	}
+	if a.ID != nil {
+		pa.Id = a.ID.String()
+	}
+	return pa
+}
+
+func authorFromProto(pa *bookspb.Author) (*Author, error) {
+	id, err := optionalUUIDFromProto(pa.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	a := Author{
+		FirstName: pa.FirstName,
+		LastName:  pa.LastName,
+		ID:        id,
+		BookCount: pa.BookCount,
+		Version:   pa.Version,
+		DeletedAt: timeFromProto(pa.DeletedAt),
+	}
+	return &a, nil
+}
+
+func bookToProto(b Book) *bookspb.Book {
+	pb := &bookspb.Book{
+		Title:       b.Title,
+		AuthorId:    b.AuthorID.String(),
+		PublishDate: timeToProto(b.PublishDate),
+		Version:     b.Version,
+	}
+	if b.ID != nil {
+		pb.Id = b.ID.String()
+	}
+	return pb
+}
+
+func bookFromProto(pb *bookspb.Book) (*Book, error) {
+	id, err := optionalUUIDFromProto(pb.Id)
+	if err != nil {
+		return nil, err
+	}
+	authorID, err := uuid.Parse(pb.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+
+	b := Book{
+		Title:       pb.Title,
+		AuthorID:    authorID,
+		PublishDate: timeFromProto(pb.PublishDate),
+		ID:          id,
+		Version:     pb.Version,
+		DeletedAt:   timeFromProto(pb.DeletedAt),
+	}
+	return &b, nil
+}
+
+func eventFromProto(pe *bookspb.Event) (*Event, error) {
+	if pe == nil {
+		return nil, errors.New("missing event")
+	}
+	entityID, err := uuid.Parse(pe.EntityId)
+	if err != nil {
+		return nil, err
+	}
+
+	e := Event{
+		ID:         pe.Id,
+		Type:       pe.Type,
+		EntityType: pe.EntityType,
+		EntityID:   entityID,
+		OccurredAt: pe.OccurredAt.AsTime(),
+		Data:       jsonFromProto(pe.Data),
+	}
+	return &e, nil
+}
+
+func webhookFromProto(pw *bookspb.Webhook) (*Webhook, error) {
+	if pw == nil {
+		return nil, errors.New("missing webhook")
+	}
+	id, err := uuid.Parse(pw.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	wh := Webhook{ID: id, URL: pw.Url, CreatedAt: pw.CreatedAt.AsTime()}
+	return &wh, nil
+}
+
+// uuidToProto converts an optional ID (the zero UUID if unset) to a string;
+// an unset ID is the empty string.
+func uuidToProto(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+// optionalUUIDFromProto parses an optional ID; the empty string is `nil`.
+func optionalUUIDFromProto(s string) (*uuid.UUID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func timeToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func timeFromProto(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// jsonFromProto converts a JSON string field; the empty string is `nil`.
+func jsonFromProto(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	return json.RawMessage(s)
+}
@@ -17,11 +17,30 @@ package booksclient
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// idempotentAttempts is the number of times a create request is sent
+	// (with the same idempotency key) before a transport error is returned.
+	idempotentAttempts = 3
+	// idempotentRetryDelay is the delay before the first retry of a create
+	// request; it grows linearly with each attempt.
+	idempotentRetryDelay = 250 * time.Millisecond
+	// inProgressAttempts is the number of times a create request is sent
+	// before a `request_in_progress` error is returned. It is higher than
+	// `idempotentAttempts` since the earlier request may take a while.
+	inProgressAttempts = 6
 )
 
 // NOTE: Ensure that
@@ -34,6 +53,13 @@ var (
 type HTTPClient struct {
 	// Addr is the base address for the Books API, e.g. `http://localhost:7104`.
 	Addr string
+	// Token is the API token sent as a bearer token with every request.
+	Token string
+	// TLSConfig is used to connect to an `https://` address; if it is `nil`,
+	// the system defaults are used.
+	TLSConfig *tls.Config
+
+	client *http.Client
 }
 
 // NewHTTPClient returns a new `HTTPClient` with all relevant defaults provided and
@@ -46,17 +72,125 @@ func NewHTTPClient(opts ...Option) (HTTPClient, error) {
 			return HTTPClient{}, err
 		}
 	}
+
+	if hc.TLSConfig != nil {
+		if strings.HasPrefix(hc.Addr, "http://") {
+			return HTTPClient{}, fmt.Errorf("TLS options require an https:// address, not %q", hc.Addr)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = hc.TLSConfig
+		hc.client = &http.Client{Transport: transport}
+	}
 	return hc, nil
 }
 
 // RawClient returns a standard libary HTTP client associated with this client.
-//
-// NOTE: For now this is just a stub wrapper around `http.DefaultClient` but
-//       it's provided here to make the code easier to test at a later date.
-func (HTTPClient) RawClient() *http.Client {
+// This is `http.DefaultClient` unless TLS options were provided.
+func (hc HTTPClient) RawClient() *http.Client {
+	if hc.client != nil {
+		return hc.client
+	}
 	return http.DefaultClient
 }
 
+// do sends an HTTP request to the Books API. Every request is tagged with a
+// request ID (from `WithRequestID()` or newly generated) so that failures can
+// be matched to server logs, and carries the API token (if there is one).
+func (hc *HTTPClient) do(req *http.Request) (*http.Response, error) {
+	id := getRequestID(req.Context())
+	if id == "" {
+		id = uuid.NewString()
+	}
+	req.Header.Set(HeaderRequestID, id)
+	if hc.Token != "" {
+		req.Header.Set(HeaderAuthorization, "Bearer "+hc.Token)
+	}
+	if actor := getActor(req.Context()); actor != "" {
+		req.Header.Set(HeaderActor, actor)
+	}
+
+	resp, err := hc.RawClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w (request ID %s)", err, id)
+	}
+
+	return resp, nil
+}
+
+// doIdempotent sends a create request with an `Idempotency-Key` (from the
+// context, or a random one) and retries transport errors, e.g. a dropped
+// connection, with the same key. The server replays the stored response for
+// a repeated key, so a retry after the original request committed does not
+// create a duplicate. A `request_in_progress` response, i.e. the original
+// request has not finished yet, is also retried.
+func (hc *HTTPClient) doIdempotent(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	key := getIdempotencyKey(ctx)
+	if key == "" {
+		key = uuid.NewString()
+	}
+	req.Header.Set(HeaderIdempotencyKey, key)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := hc.do(req)
+		attempts := idempotentAttempts
+		if err == nil {
+			if !requestInProgress(resp) {
+				return resp, nil
+			}
+			attempts = inProgressAttempts
+		}
+		if attempt >= attempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, err
+		case <-time.After(time.Duration(attempt) * idempotentRetryDelay):
+		}
+
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		req = req.Clone(ctx)
+		req.Body = body
+	}
+}
+
+// requestInProgress determines if a response is a `request_in_progress`
+// error. The body of a `409 Conflict` is read to check the code, so it is
+// replaced with a copy that can still be decoded by `newAPIError()`.
+func requestInProgress(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusConflict {
+		return false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	ae := APIError{}
+	err = json.Unmarshal(body, &ae)
+	return err == nil && ae.Code == ErrorCodeRequestInProgress
+}
+
+// setIfMatch makes a request conditional on the version of a resource; a
+// zero version leaves the request unconditional.
+func setIfMatch(req *http.Request, version int64) {
+	if version == 0 {
+		return
+	}
+	req.Header.Set(HeaderIfMatch, `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
 // AddAuthor adds a new author to be stored in the books service.
 func (hc *HTTPClient) AddAuthor(ctx context.Context, a Author) (*AddAuthorResponse, error) {
 	url := fmt.Sprintf("%s/v1alpha1/author", hc.Addr)
@@ -71,18 +205,14 @@ func (hc *HTTPClient) AddAuthor(ctx context.Context, a Author) (*AddAuthorRespon
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := hc.RawClient().Do(req)
+	resp, err := hc.doIdempotent(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to add author (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "add author")
 	}
 
 	var response AddAuthorResponse
@@ -97,6 +227,8 @@ func (hc *HTTPClient) AddAuthor(ctx context.Context, a Author) (*AddAuthorRespon
 // UpdateAuthor updates an author stored in the books service.
 func (hc *HTTPClient) UpdateAuthor(ctx context.Context, a Author) (*Empty, error) {
 	url := fmt.Sprintf("%s/v1alpha1/author", hc.Addr)
+	version := a.Version
+	a.Version = 0 // NOTE: The version is sent as `If-Match`, not in the body.
 	asJSON, err := json.Marshal(a)
 	if err != nil {
 		return nil, err
@@ -107,19 +239,45 @@ func (hc *HTTPClient) UpdateAuthor(ctx context.Context, a Author) (*Empty, error
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	setIfMatch(req, version)
 
-	resp, err := hc.RawClient().Do(req)
+	resp, err := hc.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to update author (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "update author")
+	}
+
+	return &Empty{}, nil
+}
+
+// PatchAuthor partially updates an author stored in the books service; only the
+// fields set in the request are changed.
+func (hc *HTTPClient) PatchAuthor(ctx context.Context, par PatchAuthorRequest) (*Empty, error) {
+	url := fmt.Sprintf("%s/v1alpha1/authors/%s", hc.Addr, url.PathEscape(par.AuthorID.String()))
+	asJSON, err := json.Marshal(par)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewReader(asJSON))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	setIfMatch(req, par.Version)
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return nil, newAPIError(resp, "patch author")
 	}
 
 	return &Empty{}, nil
@@ -134,18 +292,14 @@ func (hc *HTTPClient) GetAuthorByID(ctx context.Context, gabir GetAuthorByIDRequ
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := hc.RawClient().Do(req)
+	resp, err := hc.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to get author by ID (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "get author by ID")
 	}
 
 	var response Author
@@ -170,18 +324,14 @@ func (hc *HTTPClient) GetAuthorByName(ctx context.Context, gabnr GetAuthorByName
 	q.Add("last_name", gabnr.LastName)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := hc.RawClient().Do(req)
+	resp, err := hc.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to get author by name (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "get author by name")
 	}
 
 	var response Author
@@ -193,27 +343,30 @@ func (hc *HTTPClient) GetAuthorByName(ctx context.Context, gabnr GetAuthorByName
 	return &response, nil
 }
 
-// GetAuthors gets all authors currently stored in the books service.
-func (hc *HTTPClient) GetAuthors(ctx context.Context, _ Empty) (*GetAuthorsResponse, error) {
+// GetAuthors gets one page of authors currently stored in the books service.
+//
+// Use `NewAuthorIterator()` to walk every page.
+func (hc *HTTPClient) GetAuthors(ctx context.Context, gar GetAuthorsRequest) (*GetAuthorsResponse, error) {
 	url := fmt.Sprintf("%s/v1alpha1/authors", hc.Addr)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	q := req.URL.Query()
+	addNonEmpty(q, "name_prefix", gar.NamePrefix)
+	addNonEmpty(q, "order_by", gar.OrderBy)
+	addPageParams(q, gar.PageSize, gar.PageToken)
+	req.URL.RawQuery = q.Encode()
 
-	resp, err := hc.RawClient().Do(req)
+	resp, err := hc.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to get authors (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "get authors")
 	}
 
 	var response GetAuthorsResponse
@@ -226,25 +379,61 @@ func (hc *HTTPClient) GetAuthors(ctx context.Context, _ Empty) (*GetAuthorsRespo
 }
 
 // DeleteAuthorRequest deletes an author stored in the books service.
-func (hc *HTTPClient) DeleteAuthorByID(ctx context.Context, dar DeleteAuthorRequest) (*Empty, error) {
+//
+// Unless `dar.Cascade` is set, an author who still has books cannot be
+// deleted (`ErrHasDependents`).
+func (hc *HTTPClient) DeleteAuthorByID(ctx context.Context, dar DeleteAuthorRequest) (*DeleteAuthorResponse, error) {
 	url := fmt.Sprintf("%s/v1alpha1/authors/%s", hc.Addr, url.PathEscape(dar.AuthorID.String()))
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return nil, err
 	}
+	if dar.Cascade {
+		q := req.URL.Query()
+		q.Add("cascade", "true")
+		req.URL.RawQuery = q.Encode()
+	}
 
-	resp, err := hc.RawClient().Do(req)
+	setIfMatch(req, dar.Version)
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return &DeleteAuthorResponse{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, "delete author by ID")
+	}
+
+	var response DeleteAuthorResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// RestoreAuthorByID restores a deleted author from the trash of the books service.
+func (hc *HTTPClient) RestoreAuthorByID(ctx context.Context, rar RestoreAuthorRequest) (*Empty, error) {
+	url := fmt.Sprintf("%s/v1alpha1/authors/%s:restore", hc.Addr, url.PathEscape(rar.AuthorID.String()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := hc.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to delete author by ID (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "restore author by ID")
 	}
 
 	return &Empty{}, nil
@@ -266,18 +455,14 @@ func (hc *HTTPClient) AddBook(ctx context.Context, b Book) (*AddBookResponse, er
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := hc.RawClient().Do(req)
+	resp, err := hc.doIdempotent(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to add book (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "add book")
 	}
 
 	var response AddBookResponse
@@ -292,6 +477,8 @@ func (hc *HTTPClient) AddBook(ctx context.Context, b Book) (*AddBookResponse, er
 // UpdateBook updates a book stored in the books service.
 func (hc *HTTPClient) UpdateBook(ctx context.Context, b Book) (*Empty, error) {
 	url := fmt.Sprintf("%s/v1alpha1/book", hc.Addr)
+	version := b.Version
+	b.Version = 0 // NOTE: The version is sent as `If-Match`, not in the body.
 	asJSON, err := json.Marshal(b)
 	if err != nil {
 		return nil, err
@@ -302,19 +489,45 @@ func (hc *HTTPClient) UpdateBook(ctx context.Context, b Book) (*Empty, error) {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	setIfMatch(req, version)
 
-	resp, err := hc.RawClient().Do(req)
+	resp, err := hc.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to update book (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "update book")
+	}
+
+	return &Empty{}, nil
+}
+
+// PatchBook partially updates a book stored in the books service; only the
+// fields set in the request are changed.
+func (hc *HTTPClient) PatchBook(ctx context.Context, pbr PatchBookRequest) (*Empty, error) {
+	url := fmt.Sprintf("%s/v1alpha1/books/%s", hc.Addr, url.PathEscape(pbr.BookID.String()))
+	asJSON, err := json.Marshal(pbr)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewReader(asJSON))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	setIfMatch(req, pbr.Version)
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return nil, newAPIError(resp, "patch book")
 	}
 
 	return &Empty{}, nil
@@ -329,18 +542,14 @@ func (hc *HTTPClient) GetBookByID(ctx context.Context, gbbir GetBookByIDRequest)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := hc.RawClient().Do(req)
+	resp, err := hc.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to get book by ID (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "get book by ID")
 	}
 
 	var response Book
@@ -352,7 +561,10 @@ func (hc *HTTPClient) GetBookByID(ctx context.Context, gbbir GetBookByIDRequest)
 	return &response, nil
 }
 
-// GetBooks gets all books currently stored in the books service for a given author.
+// GetBooks gets one page of books currently stored in the books service that
+// match the filters in `gbr`.
+//
+// Use `NewBookIterator()` to walk every page.
 func (hc *HTTPClient) GetBooks(ctx context.Context, gbr GetBooksRequest) (*GetBooksResponse, error) {
 	url := fmt.Sprintf("%s/v1alpha1/books", hc.Addr)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -361,21 +573,29 @@ func (hc *HTTPClient) GetBooks(ctx context.Context, gbr GetBooksRequest) (*GetBo
 	}
 	req.Header.Set("Content-Type", "application/json")
 	q := req.URL.Query()
-	q.Add("author_id", gbr.AuthorID.String())
+	if gbr.AuthorID != uuid.Nil {
+		q.Add("author_id", gbr.AuthorID.String())
+	}
+	addNonEmpty(q, "title_contains", gbr.TitleContains)
+	addNonEmpty(q, "author_name_prefix", gbr.AuthorNamePrefix)
+	if gbr.PublishedAfter != nil {
+		q.Add("published_after", gbr.PublishedAfter.Format(time.RFC3339Nano))
+	}
+	if gbr.PublishedBefore != nil {
+		q.Add("published_before", gbr.PublishedBefore.Format(time.RFC3339Nano))
+	}
+	addNonEmpty(q, "order_by", gbr.OrderBy)
+	addPageParams(q, gbr.PageSize, gbr.PageToken)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := hc.RawClient().Do(req)
+	resp, err := hc.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to get books (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "get books")
 	}
 
 	var response GetBooksResponse
@@ -395,19 +615,309 @@ func (hc *HTTPClient) DeleteBookByID(ctx context.Context, dbr DeleteBookRequest)
 		return nil, err
 	}
 
-	resp, err := hc.RawClient().Do(req)
+	setIfMatch(req, dbr.Version)
+
+	resp, err := hc.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusNoContent {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to delete book by ID (status %d, body %q)", resp.StatusCode, body)
+		return nil, newAPIError(resp, "delete book by ID")
+	}
+
+	return &Empty{}, nil
+}
+
+// RestoreBookByID restores a deleted book from the trash of the books service.
+func (hc *HTTPClient) RestoreBookByID(ctx context.Context, rbr RestoreBookRequest) (*Empty, error) {
+	url := fmt.Sprintf("%s/v1alpha1/books/%s:restore", hc.Addr, url.PathEscape(rbr.BookID.String()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return nil, newAPIError(resp, "restore book by ID")
+	}
+
+	return &Empty{}, nil
+}
+
+// GetTrash gets the deleted authors and books that can still be restored.
+func (hc *HTTPClient) GetTrash(ctx context.Context, _ GetTrashRequest) (*GetTrashResponse, error) {
+	url := fmt.Sprintf("%s/v1alpha1/trash", hc.Addr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, "get trash")
+	}
+
+	var response GetTrashResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetAudit gets a page of the audit log, newest first.
+func (hc *HTTPClient) GetAudit(ctx context.Context, gar GetAuditRequest) (*GetAuditResponse, error) {
+	url := fmt.Sprintf("%s/v1alpha1/audit", hc.Addr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	q := req.URL.Query()
+	addNonEmpty(q, "entity_type", gar.EntityType)
+	if gar.EntityID != uuid.Nil {
+		q.Add("entity_id", gar.EntityID.String())
+	}
+	addNonEmpty(q, "actor", gar.Actor)
+	if gar.OccurredAfter != nil {
+		q.Add("occurred_after", gar.OccurredAfter.Format(time.RFC3339Nano))
+	}
+	if gar.OccurredBefore != nil {
+		q.Add("occurred_before", gar.OccurredBefore.Format(time.RFC3339Nano))
+	}
+	addPageParams(q, gar.PageSize, gar.PageToken)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, "get audit")
+	}
+
+	var response GetAuditResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// AddWebhook subscribes a URL to changes in the books service. The returned
+// secret is needed to verify deliveries (see `VerifyWebhook()`); it cannot
+// be retrieved again.
+func (hc *HTTPClient) AddWebhook(ctx context.Context, awr AddWebhookRequest) (*AddWebhookResponse, error) {
+	url := fmt.Sprintf("%s/v1alpha1/webhooks", hc.Addr)
+	asJSON, err := json.Marshal(awr)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(asJSON))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, "add webhook")
+	}
+
+	var response AddWebhookResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetWebhooks gets all webhooks subscribed to the books service.
+func (hc *HTTPClient) GetWebhooks(ctx context.Context, _ GetWebhooksRequest) (*GetWebhooksResponse, error) {
+	url := fmt.Sprintf("%s/v1alpha1/webhooks", hc.Addr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, "get webhooks")
+	}
+
+	var response GetWebhooksResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// DeleteWebhookByID unsubscribes a webhook from the books service.
+func (hc *HTTPClient) DeleteWebhookByID(ctx context.Context, dwr DeleteWebhookRequest) (*Empty, error) {
+	url := fmt.Sprintf("%s/v1alpha1/webhooks/%s", hc.Addr, url.PathEscape(dwr.WebhookID.String()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return nil, newAPIError(resp, "delete webhook by ID")
 	}
 
 	return &Empty{}, nil
 }
+
+// GetWebhookDeadLetters gets the deliveries for a webhook that failed too
+// many times to be retried.
+func (hc *HTTPClient) GetWebhookDeadLetters(ctx context.Context, gwdlr GetWebhookDeadLettersRequest) (*GetWebhookDeadLettersResponse, error) {
+	url := fmt.Sprintf("%s/v1alpha1/webhooks/%s/dead_letters", hc.Addr, url.PathEscape(gwdlr.WebhookID.String()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	q := req.URL.Query()
+	addPageParams(q, gwdlr.PageSize, "")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, "get webhook dead letters")
+	}
+
+	var response GetWebhookDeadLettersResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// Batch runs an ordered list of create / update / delete operations in a
+// single transaction; either every operation is applied or none are. If any
+// operation fails the error is a `*BatchError` describing each failure.
+func (hc *HTTPClient) Batch(ctx context.Context, br BatchRequest) (*BatchResponse, error) {
+	url := fmt.Sprintf("%s/v1alpha1/batch", hc.Addr)
+	asJSON, err := json.Marshal(br)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(asJSON))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hc.doIdempotent(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, "run batch")
+	}
+
+	var response BatchResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// Search does a full-text search over the book titles and author names
+// stored in the books service.
+func (hc *HTTPClient) Search(ctx context.Context, sr SearchRequest) (*SearchResponse, error) {
+	url := fmt.Sprintf("%s/v1alpha1/search", hc.Addr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	q := req.URL.Query()
+	q.Add("q", sr.Query)
+	if sr.Limit > 0 {
+		q.Add("limit", strconv.Itoa(sr.Limit))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, "search")
+	}
+
+	var response SearchResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func addPageParams(q url.Values, pageSize int, pageToken string) {
+	if pageSize > 0 {
+		q.Add("page_size", strconv.Itoa(pageSize))
+	}
+	if pageToken != "" {
+		q.Add("page_token", pageToken)
+	}
+}
+
+func addNonEmpty(q url.Values, key, value string) {
+	if value != "" {
+		q.Add(key, value)
+	}
+}
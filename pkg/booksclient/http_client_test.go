@@ -0,0 +1,141 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package booksclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// idempotentServer responds to `POST /v1alpha1/author` with `responses` in
+// turn, then with a created author, and records the idempotency keys sent.
+type idempotentServer struct {
+	mu        sync.Mutex
+	responses []string
+	keys      []string
+}
+
+func (is *idempotentServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	is.keys = append(is.keys, req.Header.Get(HeaderIdempotencyKey))
+	w.Header().Set("Content-Type", "application/json")
+	if len(is.responses) == 0 {
+		fmt.Fprintf(w, "{\"author_id\": %q}\n", uuid.NewString())
+		return
+	}
+
+	code := is.responses[0]
+	is.responses = is.responses[1:]
+	w.WriteHeader(http.StatusConflict)
+	fmt.Fprintf(w, "{\"code\": %q, \"message\": \"conflict\"}\n", code)
+}
+
+func newIdempotentClient(t *testing.T, responses ...string) (HTTPClient, *idempotentServer) {
+	t.Helper()
+
+	is := &idempotentServer{responses: responses}
+	server := httptest.NewServer(is)
+	t.Cleanup(server.Close)
+
+	hc, err := NewHTTPClient(OptAddr(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hc, is
+}
+
+func TestAddAuthorRetriesRequestInProgress(t *testing.T) {
+	hc, is := newIdempotentClient(t, ErrorCodeRequestInProgress, ErrorCodeRequestInProgress)
+
+	_, err := hc.AddAuthor(context.Background(), Author{FirstName: "Ursula", LastName: "Le Guin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(is.keys) != 3 {
+		t.Fatalf("server received %d requests, want 3", len(is.keys))
+	}
+	for _, key := range is.keys {
+		if key == "" || key != is.keys[0] {
+			t.Fatalf("idempotency keys %v are not all the same", is.keys)
+		}
+	}
+}
+
+func TestAddAuthorDoesNotRetryConflict(t *testing.T) {
+	hc, is := newIdempotentClient(t, ErrorCodeAlreadyExists)
+
+	_, err := hc.AddAuthor(context.Background(), Author{FirstName: "Ursula", LastName: "Le Guin"})
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("got error %v, want %v", err, ErrAlreadyExists)
+	}
+	if IsRequestInProgress(err) {
+		t.Fatalf("error %v should not be %v", err, ErrRequestInProgress)
+	}
+	if len(is.keys) != 1 {
+		t.Fatalf("server received %d requests, want 1", len(is.keys))
+	}
+}
+
+func TestWatchStopsWhenUnauthenticated(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if requests > 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(w, "{\"code\": \"unauthenticated\", \"message\": \"invalid API token\"}")
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 1\nid: 7\n\n")
+		fmt.Fprint(w, "id: 8\ndata: {\"id\": 8, \"type\": \"author.created\"}\n\n")
+	}))
+	t.Cleanup(server.Close)
+
+	hc, err := NewHTTPClient(OptAddr(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	w, err := hc.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []int64{}
+	for e := range w.Events() {
+		ids = append(ids, e.ID)
+	}
+	if len(ids) != 1 || ids[0] != 8 {
+		t.Fatalf("got events %v, want [8]", ids)
+	}
+	if !errors.Is(w.Err(), ErrUnauthenticated) {
+		t.Fatalf("got error %v, want %v", w.Err(), ErrUnauthenticated)
+	}
+	if requests != 2 {
+		t.Fatalf("server received %d requests, want 2", requests)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package booksclient
+
+import (
+	"context"
+)
+
+// AuthorIterator walks every page of a list authors query.
+//
+// Call `Next()` until it returns `false`, reading each author via `Author()`,
+// then check `Err()` to distinguish exhaustion from failure.
+type AuthorIterator struct {
+	c       Client
+	req     GetAuthorsRequest
+	page    []Author
+	current Author
+	done    bool
+	err     error
+}
+
+// NewAuthorIterator returns an iterator over all authors, starting from the
+// page described by `gar`.
+func NewAuthorIterator(c Client, gar GetAuthorsRequest) *AuthorIterator {
+	return &AuthorIterator{c: c, req: gar}
+}
+
+// Next advances the iterator, fetching the next page if necessary. It returns
+// `false` when there are no more authors or an error occurred.
+func (ai *AuthorIterator) Next(ctx context.Context) bool {
+	for len(ai.page) == 0 {
+		if ai.done || ai.err != nil {
+			return false
+		}
+
+		resp, err := ai.c.GetAuthors(ctx, ai.req)
+		if err != nil {
+			ai.err = err
+			return false
+		}
+
+		ai.page = resp.Authors
+		ai.req.PageToken = resp.NextPageToken
+		ai.done = resp.NextPageToken == ""
+	}
+
+	ai.current = ai.page[0]
+	ai.page = ai.page[1:]
+	return true
+}
+
+// Author returns the current author; only valid after `Next()` returns `true`.
+func (ai *AuthorIterator) Author() Author {
+	return ai.current
+}
+
+// Err returns the error (if any) that stopped the iteration.
+func (ai *AuthorIterator) Err() error {
+	return ai.err
+}
+
+// BookIterator walks every page of a books-by-author query.
+type BookIterator struct {
+	c       Client
+	req     GetBooksRequest
+	page    []Book
+	current Book
+	done    bool
+	err     error
+}
+
+// NewBookIterator returns an iterator over all books matching `gbr`, starting
+// from the page described by `gbr`.
+func NewBookIterator(c Client, gbr GetBooksRequest) *BookIterator {
+	return &BookIterator{c: c, req: gbr}
+}
+
+// Next advances the iterator, fetching the next page if necessary. It returns
+// `false` when there are no more books or an error occurred.
+func (bi *BookIterator) Next(ctx context.Context) bool {
+	for len(bi.page) == 0 {
+		if bi.done || bi.err != nil {
+			return false
+		}
+
+		resp, err := bi.c.GetBooks(ctx, bi.req)
+		if err != nil {
+			bi.err = err
+			return false
+		}
+
+		bi.page = resp.Books
+		bi.req.PageToken = resp.NextPageToken
+		bi.done = resp.NextPageToken == ""
+	}
+
+	bi.current = bi.page[0]
+	bi.page = bi.page[1:]
+	return true
+}
+
+// Book returns the current book; only valid after `Next()` returns `true`.
+func (bi *BookIterator) Book() Book {
+	return bi.current
+}
+
+// Err returns the error (if any) that stopped the iteration.
+func (bi *BookIterator) Err() error {
+	return bi.err
+}
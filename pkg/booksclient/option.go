@@ -14,7 +14,15 @@
 
 package booksclient
 
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
 // Option represents an initialization helper that can modify an HTTP client in-place.
+// The same options are used to configure a `GRPCClient`.
 type Option func(*HTTPClient) error
 
 // OptAddr sets the address on an HTTP client.
@@ -24,3 +32,66 @@ func OptAddr(addr string) Option {
 		return nil
 	}
 }
+
+// OptToken sets the API token on an HTTP client.
+func OptToken(token string) Option {
+	return func(hc *HTTPClient) error {
+		hc.Token = token
+		return nil
+	}
+}
+
+// OptCACertPEM sets PEM encoded CA certificates that are trusted (instead of
+// the system roots) to verify the Books API server certificate.
+func OptCACertPEM(caPEM []byte) Option {
+	return func(hc *HTTPClient) error {
+		tc := hc.tlsConfig()
+		if tc.RootCAs == nil {
+			tc.RootCAs = x509.NewCertPool()
+		}
+		if !tc.RootCAs.AppendCertsFromPEM(caPEM) {
+			return errors.New("no certificates found in CA certificate PEM")
+		}
+		return nil
+	}
+}
+
+// OptClientCertPEM sets the PEM encoded client certificate and private key
+// presented to the Books API, e.g. for a server that requires mutual TLS.
+func OptClientCertPEM(certPEM, keyPEM []byte) Option {
+	return func(hc *HTTPClient) error {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("invalid client certificate, %w", err)
+		}
+		hc.tlsConfig().Certificates = []tls.Certificate{cert}
+		return nil
+	}
+}
+
+// OptServerName sets the name used to verify the Books API server
+// certificate; by default the host in the address is used.
+func OptServerName(name string) Option {
+	return func(hc *HTTPClient) error {
+		hc.tlsConfig().ServerName = name
+		return nil
+	}
+}
+
+// OptInsecureSkipVerify disables verification of the Books API server
+// certificate. This should only be used for testing.
+func OptInsecureSkipVerify(skip bool) Option {
+	return func(hc *HTTPClient) error {
+		hc.tlsConfig().InsecureSkipVerify = skip
+		return nil
+	}
+}
+
+// tlsConfig returns the TLS configuration of an HTTP client, creating it if
+// it does not yet exist.
+func (hc *HTTPClient) tlsConfig() *tls.Config {
+	if hc.TLSConfig == nil {
+		hc.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return hc.TLSConfig
+}
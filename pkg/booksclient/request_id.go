@@ -0,0 +1,64 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package booksclient
+
+import (
+	"context"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID attaches a request ID to a context; requests made by the
+// HTTP client with this context will send it rather than generating one.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func getRequestID(ctx context.Context) string {
+	raw := ctx.Value(requestIDKey{})
+	id, _ := raw.(string)
+	return id // Will be empty if type assertion fails
+}
+
+type idempotencyKeyKey struct{}
+
+// WithIdempotencyKey attaches an idempotency key to a context; create
+// requests made by the HTTP client with this context will send it rather
+// than generating one. Use this to safely retry a create call (e.g. after a
+// network failure) across separate calls.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+func getIdempotencyKey(ctx context.Context) string {
+	raw := ctx.Value(idempotencyKeyKey{})
+	key, _ := raw.(string)
+	return key // Will be empty if type assertion fails
+}
+
+type actorKey struct{}
+
+// WithActor attaches an actor to a context; requests made by the HTTP client
+// with this context will send it, and changes they make are attributed to it
+// in the audit log rather than to the API token name.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func getActor(ctx context.Context) string {
+	raw := ctx.Value(actorKey{})
+	actor, _ := raw.(string)
+	return actor // Will be empty if type assertion fails
+}
@@ -15,6 +15,7 @@
 package booksclient
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,21 +26,30 @@ type Empty struct{}
 
 // Author contains information about an author.
 //
-// At creation time, neither the ID nor the book count can be set.
+// At creation time, neither the book count nor the version can be set; the
+// ID can be set to choose it rather than have the books service generate it.
 type Author struct {
 	// FirstName is the given name of the author.
 	FirstName string `json:"first_name"`
 	// LastName is the surname of the author.
 	LastName string `json:"last_name"`
-	// ID is the database identifier, if the author has already been created.
+	// ID is the database identifier, if the author has already been created
+	// (or is being created with a client-chosen ID).
 	ID *uuid.UUID `json:"id,omitempty"`
 	// BookCount is the number of books by the author in the books service.
 	BookCount uint32 `json:"book_count,omitempty"`
+	// Version is incremented by the books service on every update. If set
+	// when calling `UpdateAuthor()`, the update fails with
+	// `ErrPreconditionFailed` if the author has been modified since.
+	Version int64 `json:"version,omitempty"`
+	// DeletedAt is only set for authors in the trash (see `GetTrash()`).
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // Book contains information about a book.
 //
-// At creation time, the ID cannot be set.
+// At creation time, the version cannot be set; the ID can be set to choose it
+// rather than have the books service generate it.
 type Book struct {
 	// Title is the book title.
 	Title string `json:"title"`
@@ -47,6 +57,80 @@ type Book struct {
 	AuthorID uuid.UUID `json:"author_id"`
 	// PublishDate is the date the book was published.
 	PublishDate *time.Time `json:"publish_date,omitempty"`
-	// ID is the database identifier, if the book has already been created.
+	// ID is the database identifier, if the book has already been created
+	// (or is being created with a client-chosen ID).
 	ID *uuid.UUID `json:"id,omitempty"`
+	// Version is incremented by the books service on every update. If set
+	// when calling `UpdateBook()`, the update fails with
+	// `ErrPreconditionFailed` if the book has been modified since.
+	Version int64 `json:"version,omitempty"`
+	// DeletedAt is only set for books in the trash (see `GetTrash()`).
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// AuditEvent is a single change to an author or book, as recorded in the
+// audit log.
+type AuditEvent struct {
+	// ID orders events; a larger ID is a later event.
+	ID int64 `json:"id"`
+	// OccurredAt is the time the change was made.
+	OccurredAt time.Time `json:"occurred_at"`
+	// Actor is who made the change: the `X-Actor` header sent with the
+	// request, the API token name, or `system` for e.g. trash purges.
+	Actor string `json:"actor"`
+	// APITokenID is the API token used to make the change, if any.
+	APITokenID *uuid.UUID `json:"api_token_id,omitempty"`
+	// Action is one of `create`, `update`, `delete`, `restore` or `purge`.
+	Action string `json:"action"`
+	// EntityType is `author` or `book`.
+	EntityType string `json:"entity_type"`
+	// EntityID is the ID of the author or book that was changed.
+	EntityID uuid.UUID `json:"entity_id"`
+	// Before and After are JSON snapshots of the stored row; `Before` is
+	// empty for a create and `After` is empty for a purge.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Event is a change to an author or book, as streamed by `Watch()`.
+type Event struct {
+	// ID orders events; a larger ID is a later event.
+	ID int64 `json:"id"`
+	// Type is the entity type and what happened to it, e.g. `author.created`,
+	// `book.updated`, `book.deleted`, `author.restored` or `book.purged`.
+	Type string `json:"type"`
+	// EntityType is `author` or `book`.
+	EntityType string `json:"entity_type"`
+	// EntityID is the ID of the author or book that was changed.
+	EntityID uuid.UUID `json:"entity_id"`
+	// OccurredAt is the time the change was made.
+	OccurredAt time.Time `json:"occurred_at"`
+	// Data is a JSON snapshot of the stored row after the change (or before
+	// it, for a purge).
+	Data json.RawMessage `json:"data"`
+}
+
+// Webhook is a URL subscribed to changes to authors and books. Every change
+// is POSTed to it as an `Event`, signed with the webhook secret.
+type Webhook struct {
+	// ID is the database identifier.
+	ID uuid.UUID `json:"id"`
+	// URL is where deliveries are sent.
+	URL string `json:"url"`
+	// CreatedAt is the time the webhook was created.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeadLetter is a webhook delivery that failed too many times to be retried.
+type DeadLetter struct {
+	// ID is the database identifier of the delivery.
+	ID int64 `json:"id"`
+	// Event is the event that could not be delivered.
+	Event Event `json:"event"`
+	// Attempts is the number of failed delivery attempts.
+	Attempts int `json:"attempts"`
+	// LastError describes the final failure, e.g. the response status.
+	LastError string `json:"last_error,omitempty"`
+	// DeadAt is the time of the final failure.
+	DeadAt time.Time `json:"dead_at"`
 }
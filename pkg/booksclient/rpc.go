@@ -15,6 +15,9 @@
 package booksclient
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -38,16 +41,69 @@ type GetAuthorByNameRequest struct {
 	LastName string `json:"last_name"`
 }
 
+// GetAuthorsRequest is the request for a list authors query.
+type GetAuthorsRequest struct {
+	// NamePrefix, if set, only matches authors whose first name, last name
+	// or full name starts with it (case-insensitive).
+	NamePrefix string `json:"name_prefix,omitempty"`
+	// OrderBy is the ordering of the results, `last_name` (the default) or
+	// `book_count`, optionally followed by ` asc` or ` desc`.
+	OrderBy string `json:"order_by,omitempty"`
+	// PageSize is the maximum number of authors to return; if unset the
+	// server default is used.
+	PageSize int `json:"page_size,omitempty"`
+	// PageToken is the `NextPageToken` from a previous response; if unset
+	// the first page is returned.
+	PageToken string `json:"page_token,omitempty"`
+}
+
 // GetAuthorsResponse is the response for a list authors query.
 type GetAuthorsResponse struct {
 	// Authors is the sequence of retrieved authors.
 	Authors []Author `json:"authors"`
+	// NextPageToken can be used to retrieve the next page of authors; it
+	// will be empty if this is the last page.
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// PatchAuthorRequest is the request for a partial update of an author; it
+// is sent as a JSON merge patch, so `nil` fields are left unchanged.
+type PatchAuthorRequest struct {
+	// AuthorID is the ID of the author being updated.
+	AuthorID uuid.UUID `json:"-"`
+	// FirstName is the new given name of the author, if it is changing.
+	FirstName *string `json:"first_name,omitempty"`
+	// LastName is the new surname of the author, if it is changing.
+	LastName *string `json:"last_name,omitempty"`
+	// Version, if set, makes the update conditional: it fails with
+	// `ErrPreconditionFailed` if the author has been modified since.
+	Version int64 `json:"-"`
 }
 
 // DeleteAuthorRequest is the request for an author deletion.
 type DeleteAuthorRequest struct {
 	// AuthorID is the ID of the author being deleted.
 	AuthorID uuid.UUID `json:"author_id"`
+	// Version, if set, makes the deletion conditional: it fails with
+	// `ErrPreconditionFailed` if the author has been modified since.
+	Version int64 `json:"version,omitempty"`
+	// Cascade also deletes all of the author's books (atomically); without
+	// it, an author who still has books cannot be deleted.
+	Cascade bool `json:"cascade,omitempty"`
+}
+
+// DeleteAuthorResponse is the response after an author was deleted.
+type DeleteAuthorResponse struct {
+	// BooksDeleted is the number of books deleted along with the author; it
+	// is always zero unless the deletion cascaded.
+	BooksDeleted int64 `json:"books_deleted"`
+}
+
+// RestoreAuthorRequest is the request to restore a deleted author from the
+// trash.
+type RestoreAuthorRequest struct {
+	// AuthorID is the ID of the author being restored.
+	AuthorID uuid.UUID `json:"author_id"`
 }
 
 // AddBookResponse is the response after a book was added.
@@ -62,20 +118,239 @@ type GetBookByIDRequest struct {
 	BookID uuid.UUID `json:"book_id"`
 }
 
-// GetBooksRequest is a request for a books-by-author query.
+// GetBooksRequest is a request for a list books query.
 type GetBooksRequest struct {
-	// AuthorID is the ID of the author of the books.
-	AuthorID uuid.UUID `json:"author_id"`
+	// AuthorID, if set, only matches books by this author.
+	AuthorID uuid.UUID `json:"author_id,omitempty"`
+	// TitleContains, if set, only matches books whose title contains it
+	// (case-insensitive).
+	TitleContains string `json:"title_contains,omitempty"`
+	// AuthorNamePrefix, if set, only matches books whose author's first
+	// name, last name or full name starts with it (case-insensitive).
+	AuthorNamePrefix string `json:"author_name_prefix,omitempty"`
+	// PublishedAfter, if set, only matches books published at or after it.
+	PublishedAfter *time.Time `json:"published_after,omitempty"`
+	// PublishedBefore, if set, only matches books published strictly
+	// before it.
+	PublishedBefore *time.Time `json:"published_before,omitempty"`
+	// OrderBy is the ordering of the results, `title` (the default) or
+	// `publish_date`, optionally followed by ` asc` or ` desc`.
+	OrderBy string `json:"order_by,omitempty"`
+	// PageSize is the maximum number of books to return; if unset the
+	// server default is used.
+	PageSize int `json:"page_size,omitempty"`
+	// PageToken is the `NextPageToken` from a previous response; if unset
+	// the first page is returned.
+	PageToken string `json:"page_token,omitempty"`
 }
 
 // GetBooksResponse is the response for a books query.
 type GetBooksResponse struct {
 	// Books is the sequence of retrieved books.
 	Books []Book `json:"books"`
+	// NextPageToken can be used to retrieve the next page of books; it
+	// will be empty if this is the last page.
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// PatchBookRequest is the request for a partial update of a book; it is
+// sent as a JSON merge patch, so `nil` fields are left unchanged.
+type PatchBookRequest struct {
+	// BookID is the ID of the book being updated.
+	BookID uuid.UUID `json:"-"`
+	// Title is the new book title, if it is changing.
+	Title *string `json:"title,omitempty"`
+	// AuthorID is the ID of the new author of the book, if it is changing.
+	AuthorID *uuid.UUID `json:"author_id,omitempty"`
+	// PublishDate is the new publish date of the book, if it is changing.
+	PublishDate *time.Time `json:"publish_date,omitempty"`
+	// Version, if set, makes the update conditional: it fails with
+	// `ErrPreconditionFailed` if the book has been modified since.
+	Version int64 `json:"-"`
 }
 
 // DeleteBookRequest is the request for a book deletion.
 type DeleteBookRequest struct {
 	// BookID is the ID of the book being deleted.
 	BookID uuid.UUID `json:"book_id"`
+	// Version, if set, makes the deletion conditional: it fails with
+	// `ErrPreconditionFailed` if the book has been modified since.
+	Version int64 `json:"version,omitempty"`
+}
+
+// RestoreBookRequest is the request to restore a deleted book from the
+// trash.
+type RestoreBookRequest struct {
+	// BookID is the ID of the book being restored.
+	BookID uuid.UUID `json:"book_id"`
+}
+
+// GetTrashRequest is the request to list deleted authors and books.
+type GetTrashRequest struct{}
+
+// GetTrashResponse is the response for a trash listing.
+type GetTrashResponse struct {
+	// Authors is the sequence of deleted authors, most recently deleted
+	// first.
+	Authors []Author `json:"authors"`
+	// Books is the sequence of deleted books, most recently deleted first.
+	Books []Book `json:"books"`
+}
+
+// GetAuditRequest is the request for a page of the audit log.
+type GetAuditRequest struct {
+	// EntityType, if set, only matches events for `author` or `book`.
+	EntityType string `json:"entity_type,omitempty"`
+	// EntityID, if set, only matches events for this author or book.
+	EntityID uuid.UUID `json:"entity_id,omitempty"`
+	// Actor, if set, only matches events made by this actor.
+	Actor string `json:"actor,omitempty"`
+	// OccurredAfter, if set, only matches events at or after it.
+	OccurredAfter *time.Time `json:"occurred_after,omitempty"`
+	// OccurredBefore, if set, only matches events strictly before it.
+	OccurredBefore *time.Time `json:"occurred_before,omitempty"`
+	// PageSize is the maximum number of events to return; if unset the
+	// server default is used.
+	PageSize int `json:"page_size,omitempty"`
+	// PageToken is the `NextPageToken` from a previous response; if unset
+	// the first page is returned.
+	PageToken string `json:"page_token,omitempty"`
+}
+
+// GetAuditResponse is the response for an audit log query.
+type GetAuditResponse struct {
+	// Events is the sequence of retrieved events, newest first.
+	Events []AuditEvent `json:"events"`
+	// NextPageToken can be used to retrieve the next page of events; it
+	// will be empty if this is the last page.
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// AddWebhookRequest is the request to subscribe a URL to changes.
+type AddWebhookRequest struct {
+	// URL is the absolute `http` or `https` URL deliveries are POSTed to.
+	URL string `json:"url"`
+}
+
+// AddWebhookResponse is the response for a new webhook.
+type AddWebhookResponse struct {
+	Webhook
+	// Secret is the key used to sign deliveries; it is only returned when
+	// the webhook is created.
+	Secret string `json:"secret"`
+}
+
+// GetWebhooksRequest is the request to list webhooks.
+type GetWebhooksRequest struct{}
+
+// GetWebhooksResponse is the response for a webhooks listing.
+type GetWebhooksResponse struct {
+	// Webhooks is the sequence of webhooks, oldest first.
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// DeleteWebhookRequest is the request for a webhook deletion.
+type DeleteWebhookRequest struct {
+	// WebhookID is the ID of the webhook being deleted.
+	WebhookID uuid.UUID `json:"webhook_id"`
+}
+
+// GetWebhookDeadLettersRequest is the request to list the dead letters for a
+// webhook.
+type GetWebhookDeadLettersRequest struct {
+	// WebhookID is the ID of the webhook.
+	WebhookID uuid.UUID `json:"webhook_id"`
+	// PageSize is the maximum number of dead letters to return; if unset
+	// the server default is used.
+	PageSize int `json:"page_size,omitempty"`
+}
+
+// GetWebhookDeadLettersResponse is the response for a dead letters listing.
+type GetWebhookDeadLettersResponse struct {
+	// DeadLetters is the sequence of dead letters, most recent first.
+	DeadLetters []DeadLetter `json:"dead_letters"`
+}
+
+// SearchRequest is the request for a full-text search over book titles and
+// author names.
+type SearchRequest struct {
+	// Query uses web search syntax, e.g. `game thrones`, `"game of thrones"`
+	// or `tolkien -hobbit`.
+	Query string `json:"q"`
+	// Limit is the maximum number of results to return; if unset the server
+	// default is used.
+	Limit int `json:"limit,omitempty"`
+}
+
+// SearchResponse is the response for a full-text search.
+type SearchResponse struct {
+	// Results is the sequence of matches, most relevant first.
+	Results []SearchResult `json:"results"`
+}
+
+// SearchResult is a single author or book that matched a full-text search.
+type SearchResult struct {
+	// Kind is either `author` or `book`.
+	Kind string `json:"kind"`
+	// ID is the ID of the matching author or book.
+	ID uuid.UUID `json:"id"`
+	// Snippet is the matched text (a book title or an author's full name),
+	// HTML-escaped, with matching terms wrapped in `<b>` and `</b>`.
+	Snippet string `json:"snippet"`
+	// Score is the relevance of the match; higher is more relevant.
+	Score float64 `json:"score"`
+}
+
+const (
+	// BatchActionCreate creates an author or book; the body is the same as
+	// for `AddAuthor()` / `AddBook()`.
+	BatchActionCreate = "create"
+	// BatchActionUpdate updates an author or book; the body is the same as
+	// for `UpdateAuthor()` / `UpdateBook()`.
+	BatchActionUpdate = "update"
+	// BatchActionDelete deletes an author or book; the body is `{"id": ...}`.
+	BatchActionDelete = "delete"
+
+	// BatchResourceAuthor is the resource for author operations in a batch.
+	BatchResourceAuthor = "author"
+	// BatchResourceBook is the resource for book operations in a batch.
+	BatchResourceBook = "book"
+)
+
+// BatchRequest is the request for a batch of operations that are run in
+// order in a single transaction.
+type BatchRequest struct {
+	// Operations is the ordered list of operations in the batch.
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is a single operation in a batch.
+type BatchOperation struct {
+	// Action is one of `BatchActionCreate`, `BatchActionUpdate` or
+	// `BatchActionDelete`.
+	Action string `json:"action"`
+	// Resource is one of `BatchResourceAuthor` or `BatchResourceBook`.
+	Resource string `json:"resource"`
+	// Body is serialized as the JSON body of the operation. Any string
+	// value in it can be a reference to a field in the result of an earlier
+	// operation (see `BatchReference()`); since references are strings, use
+	// a `map[string]interface{}` rather than e.g. a `Book` to include one.
+	Body interface{} `json:"body"`
+	// Version, if set, makes an update or delete conditional: the batch
+	// fails with `ErrPreconditionFailed` if the resource has been modified
+	// since.
+	Version int64 `json:"version,omitempty"`
+}
+
+// BatchResponse is the response for a batch that was committed.
+type BatchResponse struct {
+	// Results has one entry per operation, e.g. `{"author_id": ...}` for a
+	// created author or `{"version": ...}` for an update.
+	Results []map[string]interface{} `json:"results"`
+}
+
+// BatchReference returns a reference to `field` in the result of operation
+// `index` in the same batch, e.g. `$ops[0].author_id`.
+func BatchReference(index int, field string) string {
+	return fmt.Sprintf("$ops[%d].%s", index, field)
 }
@@ -0,0 +1,229 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package booksclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// watchRetryDelay is the delay before reconnecting an event stream,
+	// unless the server suggests a different one.
+	watchRetryDelay = time.Second
+	// watchMaxRetryDelay caps the (exponential) delay between reconnection
+	// attempts that keep failing.
+	watchMaxRetryDelay = 30 * time.Second
+)
+
+// Watcher is a stream of changes to authors and books, as returned by
+// `Watch()`.
+//
+// Receive from `Events()` until the channel is closed, then check `Err()` to
+// distinguish the context being done from a failure that reconnecting cannot
+// fix.
+type Watcher struct {
+	events chan Event
+	err    error
+}
+
+func newWatcher() *Watcher {
+	return &Watcher{events: make(chan Event)}
+}
+
+// Events returns the channel the changes are sent on.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Err returns the error (if any) that stopped the stream; only valid after
+// the `Events()` channel is closed.
+func (w *Watcher) Err() error {
+	return w.err
+}
+
+// stop records `err` as the reason the stream stopped and closes the
+// `Events()` channel.
+func (w *Watcher) stop(err error) {
+	w.err = err
+	close(w.events)
+}
+
+// isTerminalWatchError determines if reconnecting an event stream after `err`
+// cannot succeed, e.g. because the API token is invalid.
+func isTerminalWatchError(err error) bool {
+	ae := &APIError{}
+	if !errors.As(err, &ae) {
+		return false
+	}
+
+	switch ae.Code {
+	case ErrorCodeUnauthenticated, ErrorCodePermissionDenied, ErrorCodeUnimplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// Watch streams changes to authors and books made after it is called.
+//
+// The stream is reconnected automatically, resuming after the last event
+// received, whenever it ends or fails. The events channel is closed once
+// `ctx` is done, or when reconnecting fails with an error that retrying
+// cannot fix (see `Watcher.Err()`). An error is returned if the first
+// connection fails.
+func (hc *HTTPClient) Watch(ctx context.Context) (*Watcher, error) {
+	resp, err := hc.openEvents(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	w := newWatcher()
+	go hc.watch(ctx, resp, w)
+	return w, nil
+}
+
+// openEvents opens an event stream, resuming after `lastEventID` if set.
+func (hc *HTTPClient) openEvents(ctx context.Context, lastEventID string) (*http.Response, error) {
+	url := fmt.Sprintf("%s/v1alpha1/events", hc.Addr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set(HeaderLastEventID, lastEventID)
+	}
+
+	resp, err := hc.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp, "watch events")
+	}
+
+	return resp, nil
+}
+
+// watch sends the events from `resp` (and every reconnected stream after it)
+// on `w` until `ctx` is done or a reconnection fails for good.
+func (hc *HTTPClient) watch(ctx context.Context, resp *http.Response, w *Watcher) {
+	es := eventStream{retry: watchRetryDelay}
+	failures := 0
+	for {
+		if resp != nil {
+			es.read(ctx, resp.Body, w.events)
+			resp.Body.Close()
+		}
+
+		delay := es.retry << failures
+		if delay > watchMaxRetryDelay || delay <= 0 {
+			delay = watchMaxRetryDelay
+		}
+		select {
+		case <-ctx.Done():
+			w.stop(nil)
+			return
+		case <-time.After(delay):
+		}
+
+		var err error
+		resp, err = hc.openEvents(ctx, es.lastEventID)
+		if isTerminalWatchError(err) {
+			w.stop(err)
+			return
+		}
+		if err != nil {
+			failures++
+			continue
+		}
+		failures = 0
+	}
+}
+
+// eventStream is the state of a server-sent events stream that carries over
+// when it is reconnected.
+type eventStream struct {
+	lastEventID string
+	retry       time.Duration
+}
+
+// read parses server-sent events from `r` and sends them on `events` until
+// the stream ends or `ctx` is done.
+func (es *eventStream) read(ctx context.Context, r io.Reader, events chan<- Event) {
+	br := bufio.NewReader(r)
+	id := es.lastEventID
+	var data strings.Builder
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			// NOTE: The ID is recorded even for an event without data; the
+			//       server sends one when the stream opens so that it can be
+			//       resumed before any change is sent.
+			es.lastEventID = id
+			if data.Len() > 0 {
+				e := Event{}
+				err = json.Unmarshal([]byte(data.String()), &e)
+				if err == nil {
+					select {
+					case events <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			id = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		case "retry":
+			ms, err := strconv.Atoi(value)
+			if err == nil && ms > 0 {
+				es.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package booksclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// webhookSignaturePrefix precedes the hex encoded HMAC in the
+	// `X-Books-Signature` header.
+	webhookSignaturePrefix = "sha256="
+)
+
+var (
+	// ErrInvalidWebhookSignature is returned by `VerifyWebhook()` when a
+	// delivery is not signed with the webhook secret, or was signed too long
+	// ago.
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+)
+
+// VerifyWebhook checks that a webhook delivery (its headers and raw body)
+// was signed with `secret` no more than `tolerance` ago and returns the
+// event it carries. A zero `tolerance` skips the timestamp check.
+//
+// Deliveries are retried until they succeed, so the same event may arrive
+// more than once; use `Event.ID` to ignore repeats.
+func VerifyWebhook(secret string, header http.Header, body []byte, tolerance time.Duration) (*Event, error) {
+	timestamp := header.Get(HeaderWebhookTimestamp)
+	signature := header.Get(HeaderWebhookSignature)
+	if timestamp == "" || !strings.HasPrefix(signature, webhookSignaturePrefix) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, webhookSignaturePrefix))
+	if err != nil {
+		return nil, ErrInvalidWebhookSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	if tolerance > 0 {
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidWebhookSignature
+		}
+		age := time.Since(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return nil, ErrInvalidWebhookSignature
+		}
+	}
+
+	e := Event{}
+	err = json.Unmarshal(body, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
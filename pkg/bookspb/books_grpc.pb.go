@@ -0,0 +1,970 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: pkg/bookspb/books.proto
+
+package bookspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// BooksClient is the client API for Books service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BooksClient interface {
+	AddAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*AddAuthorResponse, error)
+	UpdateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PatchAuthor(ctx context.Context, in *PatchAuthorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetAuthorByID(ctx context.Context, in *GetAuthorByIDRequest, opts ...grpc.CallOption) (*Author, error)
+	GetAuthorByName(ctx context.Context, in *GetAuthorByNameRequest, opts ...grpc.CallOption) (*Author, error)
+	GetAuthors(ctx context.Context, in *GetAuthorsRequest, opts ...grpc.CallOption) (*GetAuthorsResponse, error)
+	DeleteAuthorByID(ctx context.Context, in *DeleteAuthorRequest, opts ...grpc.CallOption) (*DeleteAuthorResponse, error)
+	RestoreAuthorByID(ctx context.Context, in *RestoreAuthorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	AddBook(ctx context.Context, in *Book, opts ...grpc.CallOption) (*AddBookResponse, error)
+	UpdateBook(ctx context.Context, in *Book, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PatchBook(ctx context.Context, in *PatchBookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetBookByID(ctx context.Context, in *GetBookByIDRequest, opts ...grpc.CallOption) (*Book, error)
+	GetBooks(ctx context.Context, in *GetBooksRequest, opts ...grpc.CallOption) (*GetBooksResponse, error)
+	DeleteBookByID(ctx context.Context, in *DeleteBookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RestoreBookByID(ctx context.Context, in *RestoreBookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetTrash(ctx context.Context, in *GetTrashRequest, opts ...grpc.CallOption) (*GetTrashResponse, error)
+	GetAudit(ctx context.Context, in *GetAuditRequest, opts ...grpc.CallOption) (*GetAuditResponse, error)
+	// Watch streams changes to authors and books; unlike the HTTP/JSON event
+	// stream, it is not ended by the server until it shuts down. Once the
+	// stream is accepted, its header carries `books-status: 200` and the ID of
+	// the event it starts after in `books-last-event-id`.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Books_WatchClient, error)
+	AddWebhook(ctx context.Context, in *AddWebhookRequest, opts ...grpc.CallOption) (*AddWebhookResponse, error)
+	GetWebhooks(ctx context.Context, in *GetWebhooksRequest, opts ...grpc.CallOption) (*GetWebhooksResponse, error)
+	DeleteWebhookByID(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetWebhookDeadLetters(ctx context.Context, in *GetWebhookDeadLettersRequest, opts ...grpc.CallOption) (*GetWebhookDeadLettersResponse, error)
+	Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+}
+
+type booksClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBooksClient(cc grpc.ClientConnInterface) BooksClient {
+	return &booksClient{cc}
+}
+
+func (c *booksClient) AddAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*AddAuthorResponse, error) {
+	out := new(AddAuthorResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/AddAuthor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) UpdateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/UpdateAuthor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) PatchAuthor(ctx context.Context, in *PatchAuthorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/PatchAuthor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) GetAuthorByID(ctx context.Context, in *GetAuthorByIDRequest, opts ...grpc.CallOption) (*Author, error) {
+	out := new(Author)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/GetAuthorByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) GetAuthorByName(ctx context.Context, in *GetAuthorByNameRequest, opts ...grpc.CallOption) (*Author, error) {
+	out := new(Author)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/GetAuthorByName", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) GetAuthors(ctx context.Context, in *GetAuthorsRequest, opts ...grpc.CallOption) (*GetAuthorsResponse, error) {
+	out := new(GetAuthorsResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/GetAuthors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) DeleteAuthorByID(ctx context.Context, in *DeleteAuthorRequest, opts ...grpc.CallOption) (*DeleteAuthorResponse, error) {
+	out := new(DeleteAuthorResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/DeleteAuthorByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) RestoreAuthorByID(ctx context.Context, in *RestoreAuthorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/RestoreAuthorByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) AddBook(ctx context.Context, in *Book, opts ...grpc.CallOption) (*AddBookResponse, error) {
+	out := new(AddBookResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/AddBook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) UpdateBook(ctx context.Context, in *Book, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/UpdateBook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) PatchBook(ctx context.Context, in *PatchBookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/PatchBook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) GetBookByID(ctx context.Context, in *GetBookByIDRequest, opts ...grpc.CallOption) (*Book, error) {
+	out := new(Book)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/GetBookByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) GetBooks(ctx context.Context, in *GetBooksRequest, opts ...grpc.CallOption) (*GetBooksResponse, error) {
+	out := new(GetBooksResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/GetBooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) DeleteBookByID(ctx context.Context, in *DeleteBookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/DeleteBookByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) RestoreBookByID(ctx context.Context, in *RestoreBookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/RestoreBookByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) GetTrash(ctx context.Context, in *GetTrashRequest, opts ...grpc.CallOption) (*GetTrashResponse, error) {
+	out := new(GetTrashResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/GetTrash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) GetAudit(ctx context.Context, in *GetAuditRequest, opts ...grpc.CallOption) (*GetAuditResponse, error) {
+	out := new(GetAuditResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/GetAudit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Books_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Books_ServiceDesc.Streams[0], "/books.v1alpha1.Books/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &booksWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Books_WatchClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type booksWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *booksWatchClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *booksClient) AddWebhook(ctx context.Context, in *AddWebhookRequest, opts ...grpc.CallOption) (*AddWebhookResponse, error) {
+	out := new(AddWebhookResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/AddWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) GetWebhooks(ctx context.Context, in *GetWebhooksRequest, opts ...grpc.CallOption) (*GetWebhooksResponse, error) {
+	out := new(GetWebhooksResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/GetWebhooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) DeleteWebhookByID(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/DeleteWebhookByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) GetWebhookDeadLetters(ctx context.Context, in *GetWebhookDeadLettersRequest, opts ...grpc.CallOption) (*GetWebhookDeadLettersResponse, error) {
+	out := new(GetWebhookDeadLettersResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/GetWebhookDeadLetters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/Batch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, "/books.v1alpha1.Books/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BooksServer is the server API for Books service.
+// All implementations must embed UnimplementedBooksServer
+// for forward compatibility
+type BooksServer interface {
+	AddAuthor(context.Context, *Author) (*AddAuthorResponse, error)
+	UpdateAuthor(context.Context, *Author) (*emptypb.Empty, error)
+	PatchAuthor(context.Context, *PatchAuthorRequest) (*emptypb.Empty, error)
+	GetAuthorByID(context.Context, *GetAuthorByIDRequest) (*Author, error)
+	GetAuthorByName(context.Context, *GetAuthorByNameRequest) (*Author, error)
+	GetAuthors(context.Context, *GetAuthorsRequest) (*GetAuthorsResponse, error)
+	DeleteAuthorByID(context.Context, *DeleteAuthorRequest) (*DeleteAuthorResponse, error)
+	RestoreAuthorByID(context.Context, *RestoreAuthorRequest) (*emptypb.Empty, error)
+	AddBook(context.Context, *Book) (*AddBookResponse, error)
+	UpdateBook(context.Context, *Book) (*emptypb.Empty, error)
+	PatchBook(context.Context, *PatchBookRequest) (*emptypb.Empty, error)
+	GetBookByID(context.Context, *GetBookByIDRequest) (*Book, error)
+	GetBooks(context.Context, *GetBooksRequest) (*GetBooksResponse, error)
+	DeleteBookByID(context.Context, *DeleteBookRequest) (*emptypb.Empty, error)
+	RestoreBookByID(context.Context, *RestoreBookRequest) (*emptypb.Empty, error)
+	GetTrash(context.Context, *GetTrashRequest) (*GetTrashResponse, error)
+	GetAudit(context.Context, *GetAuditRequest) (*GetAuditResponse, error)
+	// Watch streams changes to authors and books; unlike the HTTP/JSON event
+	// stream, it is not ended by the server until it shuts down. Once the
+	// stream is accepted, its header carries `books-status: 200` and the ID of
+	// the event it starts after in `books-last-event-id`.
+	Watch(*WatchRequest, Books_WatchServer) error
+	AddWebhook(context.Context, *AddWebhookRequest) (*AddWebhookResponse, error)
+	GetWebhooks(context.Context, *GetWebhooksRequest) (*GetWebhooksResponse, error)
+	DeleteWebhookByID(context.Context, *DeleteWebhookRequest) (*emptypb.Empty, error)
+	GetWebhookDeadLetters(context.Context, *GetWebhookDeadLettersRequest) (*GetWebhookDeadLettersResponse, error)
+	Batch(context.Context, *BatchRequest) (*BatchResponse, error)
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	mustEmbedUnimplementedBooksServer()
+}
+
+// UnimplementedBooksServer must be embedded to have forward compatible implementations.
+type UnimplementedBooksServer struct {
+}
+
+func (UnimplementedBooksServer) AddAuthor(context.Context, *Author) (*AddAuthorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddAuthor not implemented")
+}
+func (UnimplementedBooksServer) UpdateAuthor(context.Context, *Author) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAuthor not implemented")
+}
+func (UnimplementedBooksServer) PatchAuthor(context.Context, *PatchAuthorRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchAuthor not implemented")
+}
+func (UnimplementedBooksServer) GetAuthorByID(context.Context, *GetAuthorByIDRequest) (*Author, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthorByID not implemented")
+}
+func (UnimplementedBooksServer) GetAuthorByName(context.Context, *GetAuthorByNameRequest) (*Author, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthorByName not implemented")
+}
+func (UnimplementedBooksServer) GetAuthors(context.Context, *GetAuthorsRequest) (*GetAuthorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthors not implemented")
+}
+func (UnimplementedBooksServer) DeleteAuthorByID(context.Context, *DeleteAuthorRequest) (*DeleteAuthorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAuthorByID not implemented")
+}
+func (UnimplementedBooksServer) RestoreAuthorByID(context.Context, *RestoreAuthorRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreAuthorByID not implemented")
+}
+func (UnimplementedBooksServer) AddBook(context.Context, *Book) (*AddBookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBook not implemented")
+}
+func (UnimplementedBooksServer) UpdateBook(context.Context, *Book) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBook not implemented")
+}
+func (UnimplementedBooksServer) PatchBook(context.Context, *PatchBookRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchBook not implemented")
+}
+func (UnimplementedBooksServer) GetBookByID(context.Context, *GetBookByIDRequest) (*Book, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBookByID not implemented")
+}
+func (UnimplementedBooksServer) GetBooks(context.Context, *GetBooksRequest) (*GetBooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBooks not implemented")
+}
+func (UnimplementedBooksServer) DeleteBookByID(context.Context, *DeleteBookRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBookByID not implemented")
+}
+func (UnimplementedBooksServer) RestoreBookByID(context.Context, *RestoreBookRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreBookByID not implemented")
+}
+func (UnimplementedBooksServer) GetTrash(context.Context, *GetTrashRequest) (*GetTrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrash not implemented")
+}
+func (UnimplementedBooksServer) GetAudit(context.Context, *GetAuditRequest) (*GetAuditResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAudit not implemented")
+}
+func (UnimplementedBooksServer) Watch(*WatchRequest, Books_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedBooksServer) AddWebhook(context.Context, *AddWebhookRequest) (*AddWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddWebhook not implemented")
+}
+func (UnimplementedBooksServer) GetWebhooks(context.Context, *GetWebhooksRequest) (*GetWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWebhooks not implemented")
+}
+func (UnimplementedBooksServer) DeleteWebhookByID(context.Context, *DeleteWebhookRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhookByID not implemented")
+}
+func (UnimplementedBooksServer) GetWebhookDeadLetters(context.Context, *GetWebhookDeadLettersRequest) (*GetWebhookDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWebhookDeadLetters not implemented")
+}
+func (UnimplementedBooksServer) Batch(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Batch not implemented")
+}
+func (UnimplementedBooksServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedBooksServer) mustEmbedUnimplementedBooksServer() {}
+
+// UnsafeBooksServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BooksServer will
+// result in compilation errors.
+type UnsafeBooksServer interface {
+	mustEmbedUnimplementedBooksServer()
+}
+
+func RegisterBooksServer(s grpc.ServiceRegistrar, srv BooksServer) {
+	s.RegisterService(&Books_ServiceDesc, srv)
+}
+
+func _Books_AddAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Author)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).AddAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/AddAuthor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).AddAuthor(ctx, req.(*Author))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_UpdateAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Author)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).UpdateAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/UpdateAuthor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).UpdateAuthor(ctx, req.(*Author))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_PatchAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).PatchAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/PatchAuthor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).PatchAuthor(ctx, req.(*PatchAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_GetAuthorByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuthorByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).GetAuthorByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/GetAuthorByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).GetAuthorByID(ctx, req.(*GetAuthorByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_GetAuthorByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuthorByNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).GetAuthorByName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/GetAuthorByName",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).GetAuthorByName(ctx, req.(*GetAuthorByNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_GetAuthors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuthorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).GetAuthors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/GetAuthors",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).GetAuthors(ctx, req.(*GetAuthorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_DeleteAuthorByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).DeleteAuthorByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/DeleteAuthorByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).DeleteAuthorByID(ctx, req.(*DeleteAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_RestoreAuthorByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).RestoreAuthorByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/RestoreAuthorByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).RestoreAuthorByID(ctx, req.(*RestoreAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_AddBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Book)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).AddBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/AddBook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).AddBook(ctx, req.(*Book))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_UpdateBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Book)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).UpdateBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/UpdateBook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).UpdateBook(ctx, req.(*Book))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_PatchBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).PatchBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/PatchBook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).PatchBook(ctx, req.(*PatchBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_GetBookByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBookByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).GetBookByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/GetBookByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).GetBookByID(ctx, req.(*GetBookByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_GetBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).GetBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/GetBooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).GetBooks(ctx, req.(*GetBooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_DeleteBookByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).DeleteBookByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/DeleteBookByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).DeleteBookByID(ctx, req.(*DeleteBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_RestoreBookByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).RestoreBookByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/RestoreBookByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).RestoreBookByID(ctx, req.(*RestoreBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_GetTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).GetTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/GetTrash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).GetTrash(ctx, req.(*GetTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_GetAudit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).GetAudit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/GetAudit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).GetAudit(ctx, req.(*GetAuditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BooksServer).Watch(m, &booksWatchServer{stream})
+}
+
+type Books_WatchServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type booksWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *booksWatchServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Books_AddWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).AddWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/AddWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).AddWebhook(ctx, req.(*AddWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_GetWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).GetWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/GetWebhooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).GetWebhooks(ctx, req.(*GetWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_DeleteWebhookByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).DeleteWebhookByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/DeleteWebhookByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).DeleteWebhookByID(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_GetWebhookDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWebhookDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).GetWebhookDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/GetWebhookDeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).GetWebhookDeadLetters(ctx, req.(*GetWebhookDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_Batch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).Batch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/Batch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).Batch(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Books_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.v1alpha1.Books/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Books_ServiceDesc is the grpc.ServiceDesc for Books service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Books_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "books.v1alpha1.Books",
+	HandlerType: (*BooksServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddAuthor",
+			Handler:    _Books_AddAuthor_Handler,
+		},
+		{
+			MethodName: "UpdateAuthor",
+			Handler:    _Books_UpdateAuthor_Handler,
+		},
+		{
+			MethodName: "PatchAuthor",
+			Handler:    _Books_PatchAuthor_Handler,
+		},
+		{
+			MethodName: "GetAuthorByID",
+			Handler:    _Books_GetAuthorByID_Handler,
+		},
+		{
+			MethodName: "GetAuthorByName",
+			Handler:    _Books_GetAuthorByName_Handler,
+		},
+		{
+			MethodName: "GetAuthors",
+			Handler:    _Books_GetAuthors_Handler,
+		},
+		{
+			MethodName: "DeleteAuthorByID",
+			Handler:    _Books_DeleteAuthorByID_Handler,
+		},
+		{
+			MethodName: "RestoreAuthorByID",
+			Handler:    _Books_RestoreAuthorByID_Handler,
+		},
+		{
+			MethodName: "AddBook",
+			Handler:    _Books_AddBook_Handler,
+		},
+		{
+			MethodName: "UpdateBook",
+			Handler:    _Books_UpdateBook_Handler,
+		},
+		{
+			MethodName: "PatchBook",
+			Handler:    _Books_PatchBook_Handler,
+		},
+		{
+			MethodName: "GetBookByID",
+			Handler:    _Books_GetBookByID_Handler,
+		},
+		{
+			MethodName: "GetBooks",
+			Handler:    _Books_GetBooks_Handler,
+		},
+		{
+			MethodName: "DeleteBookByID",
+			Handler:    _Books_DeleteBookByID_Handler,
+		},
+		{
+			MethodName: "RestoreBookByID",
+			Handler:    _Books_RestoreBookByID_Handler,
+		},
+		{
+			MethodName: "GetTrash",
+			Handler:    _Books_GetTrash_Handler,
+		},
+		{
+			MethodName: "GetAudit",
+			Handler:    _Books_GetAudit_Handler,
+		},
+		{
+			MethodName: "AddWebhook",
+			Handler:    _Books_AddWebhook_Handler,
+		},
+		{
+			MethodName: "GetWebhooks",
+			Handler:    _Books_GetWebhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhookByID",
+			Handler:    _Books_DeleteWebhookByID_Handler,
+		},
+		{
+			MethodName: "GetWebhookDeadLetters",
+			Handler:    _Books_GetWebhookDeadLetters_Handler,
+		},
+		{
+			MethodName: "Batch",
+			Handler:    _Books_Batch_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _Books_Search_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Books_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/bookspb/books.proto",
+}
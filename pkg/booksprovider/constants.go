@@ -19,4 +19,24 @@ const (
 	// be used to specify the `addr` for the Books API. For example, this
 	// value could equal `http://localhost:7534`.
 	EnvVarBooksAPIAddr = "BOOKS_API_ADDR"
+	// EnvVarBooksAPIToken is the configuration environment variable that can
+	// be used to specify the `token` for the Books API, e.g. one minted via
+	// `server token create`.
+	EnvVarBooksAPIToken = "BOOKS_API_TOKEN"
+	// EnvVarBooksAPICACertPEM is the configuration environment variable that
+	// can be used to specify the `ca_cert_pem` for the Books API, i.e. PEM
+	// encoded CA certificates trusted instead of the system roots.
+	EnvVarBooksAPICACertPEM = "BOOKS_API_CA_CERT_PEM"
+	// EnvVarBooksAPIClientCertPEM is the configuration environment variable
+	// that can be used to specify the `client_cert_pem` for the Books API,
+	// i.e. a PEM encoded client certificate for mutual TLS.
+	EnvVarBooksAPIClientCertPEM = "BOOKS_API_CLIENT_CERT_PEM"
+	// EnvVarBooksAPIClientKeyPEM is the configuration environment variable
+	// that can be used to specify the `client_key_pem` for the Books API,
+	// i.e. the PEM encoded private key for `client_cert_pem`.
+	EnvVarBooksAPIClientKeyPEM = "BOOKS_API_CLIENT_KEY_PEM"
+	// EnvVarBooksAPIInsecureSkipVerify is the configuration environment
+	// variable that can be used to specify `insecure_skip_verify` for the
+	// Books API, e.g. `true` for a server with a self-signed certificate.
+	EnvVarBooksAPIInsecureSkipVerify = "BOOKS_API_INSECURE_SKIP_VERIFY"
 )
@@ -35,7 +35,7 @@ func Configure(_ context.Context, d *schema.ResourceData) (booksclient.Client, e
 		return nil, err
 	}
 
-	_, err := url.Parse(addr)
+	u, err := url.Parse(addr)
 	if err != nil {
 		err := terraform.DiagnosticError{
 			Summary: "Unable to create Books API client",
@@ -44,10 +44,70 @@ func Configure(_ context.Context, d *schema.ResourceData) (booksclient.Client, e
 		return nil, err
 	}
 
-	c, err := booksclient.NewHTTPClient(booksclient.OptAddr(addr))
+	// NOTE: The token is optional here so that a missing token results in a
+	//       401 from the Books API rather than a configuration failure.
+	token, _ := d.Get("token").(string)
+
+	opts := []booksclient.Option{
+		booksclient.OptAddr(addr),
+		booksclient.OptToken(token),
+	}
+	tlsOpts, err := tlsOptions(d)
 	if err != nil {
 		return nil, err
 	}
+	opts = append(opts, tlsOpts...)
+
+	// NOTE: A `grpc://` (or `grpcs://`) address selects the gRPC transport;
+	//       any other address is the base URL for the HTTP/JSON API.
+	if u.Scheme == booksclient.GRPCScheme || u.Scheme == booksclient.GRPCSecureScheme {
+		gc, err := booksclient.NewGRPCClient(opts...)
+		if err != nil {
+			return nil, clientError(err)
+		}
+		return gc, nil
+	}
+
+	c, err := booksclient.NewHTTPClient(opts...)
+	if err != nil {
+		return nil, clientError(err)
+	}
 
 	return &c, nil
 }
+
+// tlsOptions returns the Books API client options for the TLS attributes of
+// the provider; there are none if no TLS attributes are set.
+func tlsOptions(d *schema.ResourceData) ([]booksclient.Option, error) {
+	opts := []booksclient.Option{}
+	caCertPEM, _ := d.Get("ca_cert_pem").(string)
+	if caCertPEM != "" {
+		opts = append(opts, booksclient.OptCACertPEM([]byte(caCertPEM)))
+	}
+	clientCertPEM, _ := d.Get("client_cert_pem").(string)
+	clientKeyPEM, _ := d.Get("client_key_pem").(string)
+	if (clientCertPEM == "") != (clientKeyPEM == "") {
+		err := terraform.DiagnosticError{
+			Summary: "Unable to create Books API client",
+			Detail:  "Both client_cert_pem and client_key_pem must be set for mutual TLS",
+		}
+		return nil, err
+	}
+	if clientCertPEM != "" {
+		opts = append(opts, booksclient.OptClientCertPEM([]byte(clientCertPEM), []byte(clientKeyPEM)))
+	}
+	insecureSkipVerify, _ := d.Get("insecure_skip_verify").(bool)
+	if insecureSkipVerify {
+		opts = append(opts, booksclient.OptInsecureSkipVerify(true))
+	}
+	return opts, nil
+}
+
+// clientError converts an error from creating a Books API client, e.g. an
+// invalid certificate, into a diagnostic.
+func clientError(err error) error {
+	return terraform.DiagnosticError{
+		Summary: "Unable to create Books API client",
+		Detail:  err.Error(),
+	}
+}
@@ -16,12 +16,15 @@ package booksprovider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	"github.com/dhermes/example-terraform-provider/pkg/booksclient"
+	"github.com/dhermes/example-terraform-provider/pkg/terraform"
 )
 
 // ResourceAuthor represents a `books_api_author` resource and directly
@@ -31,10 +34,12 @@ type ResourceAuthor struct {
 	mutex sync.RWMutex
 	d     *schema.ResourceData
 
-	FirstName *string    `terraform:"first_name,required"`
-	LastName  *string    `terraform:"last_name,required"`
-	BookCount *int       `terraform:"book_count,computed"`
-	ID        *uuid.UUID `terraform:"id,string,computed"`
+	FirstName    *string    `terraform:"first_name,required"`
+	LastName     *string    `terraform:"last_name,required"`
+	ForceDestroy *bool      `terraform:"force_destroy,optional"`
+	BookCount    *int       `terraform:"book_count,computed"`
+	Version      *int       `terraform:"version,computed"`
+	ID           *uuid.UUID `terraform:"id,string,computed"`
 }
 
 // Create is the create (C) component of the CRUD lifecycle for
@@ -46,7 +51,7 @@ func (ra *ResourceAuthor) Create(ctx context.Context, c booksclient.Client) erro
 	a := booksclient.Author{FirstName: ra.GetFirstName(), LastName: ra.GetLastName()}
 	aar, err := c.AddAuthor(ctx, a)
 	if err != nil {
-		return err
+		return permissionError(err, "add authors")
 	}
 
 	ra.ID = &aar.AuthorID
@@ -61,12 +66,26 @@ func (ra *ResourceAuthor) Create(ctx context.Context, c booksclient.Client) erro
 // Read is the read (R) component of the CRUD lifecycle for
 // the `books_api_author` resource.
 //
+// If the author no longer exists, it is removed from state and a warning
+// diagnostic is returned (as an error) instead of failing outright.
+//
 // NOTE: This assumes the called has already invoked `ra.Populate()`, either
 //       directly or indirectly, e.g. via `NewResourceAuthor()`.
 func (ra *ResourceAuthor) Read(ctx context.Context, c booksclient.Client) error {
 	id := ra.GetID()
 	gabir := booksclient.GetAuthorByIDRequest{AuthorID: id}
 	a, err := c.GetAuthorByID(ctx, gabir)
+	if booksclient.IsNotFound(err) {
+		// NOTE: Clearing the ID removes the resource from state, so Terraform
+		//       will propose re-creating it rather than failing the plan.
+		ra.ID = nil
+		ra.d.SetId("")
+		err = terraform.DiagnosticWarning{
+			Summary: "Author no longer exists",
+			Detail:  fmt.Sprintf("Author %s was not found in the Books API (it may have been deleted outside of Terraform); removing it from state%s", id, requestIDSuffix(err)),
+		}
+		return err
+	}
 	if err != nil {
 		return err
 	}
@@ -75,6 +94,8 @@ func (ra *ResourceAuthor) Read(ctx context.Context, c booksclient.Client) error
 	ra.LastName = &a.LastName
 	bc := int(a.BookCount)
 	ra.BookCount = &bc
+	v := int(a.Version)
+	ra.Version = &v
 	ra.ID = a.ID
 	return ra.Persist()
 }
@@ -89,11 +110,27 @@ func (ra *ResourceAuthor) Update(ctx context.Context, c booksclient.Client) erro
 		return ra.Read(ctx, c)
 	}
 
-	a := booksclient.Author{ID: ra.ID, FirstName: ra.GetFirstName(), LastName: ra.GetLastName()}
-	_, err := c.UpdateAuthor(ctx, a)
-	if err != nil {
+	// NOTE: Only the changed fields are sent (as a merge patch) and sending
+	//       the version from state makes the update conditional, so it fails
+	//       if the author was modified since the last refresh.
+	par := booksclient.PatchAuthorRequest{AuthorID: ra.GetID(), Version: int64(ra.GetVersion())}
+	if ra.d.HasChange("first_name") {
+		par.FirstName = ra.FirstName
+	}
+	if ra.d.HasChange("last_name") {
+		par.LastName = ra.LastName
+	}
+	_, err := c.PatchAuthor(ctx, par)
+	if booksclient.IsPreconditionFailed(err) {
+		err = terraform.DiagnosticError{
+			Summary: "Author was modified outside of Terraform",
+			Detail:  fmt.Sprintf("Author %s changed in the Books API since it was last refreshed; refresh and review the plan before applying again%s", ra.GetID(), requestIDSuffix(err)),
+		}
 		return err
 	}
+	if err != nil {
+		return permissionError(err, "update authors")
+	}
 
 	return ra.Read(ctx, c)
 }
@@ -101,11 +138,25 @@ func (ra *ResourceAuthor) Update(ctx context.Context, c booksclient.Client) erro
 // Delete is the delete (D) component of the CRUD lifecycle for
 // the `books_api_author` resource.
 //
+// If `force_destroy` is set, the author's books (including any created
+// outside of Terraform) are deleted along with it.
+//
 // NOTE: This assumes the called has already invoked `ra.Populate()`, either
 //       directly or indirectly, e.g. via `NewResourceAuthor()`.
 func (ra *ResourceAuthor) Delete(ctx context.Context, c booksclient.Client) error {
 	id := ra.GetID()
-	dar := booksclient.DeleteAuthorRequest{AuthorID: id}
+	dar := booksclient.DeleteAuthorRequest{AuthorID: id, Cascade: ra.GetForceDestroy()}
 	_, err := c.DeleteAuthorByID(ctx, dar)
-	return err
+	if booksclient.IsNotFound(err) {
+		// NOTE: Already deleted (e.g. outside of Terraform) is the desired end state.
+		return nil
+	}
+	if errors.Is(err, booksclient.ErrHasDependents) {
+		err = terraform.DiagnosticError{
+			Summary: "Author still has books",
+			Detail:  fmt.Sprintf("Author %s cannot be deleted while books still refer to it; delete those books first or set force_destroy = true to delete them along with the author%s", id, requestIDSuffix(err)),
+		}
+		return err
+	}
+	return permissionError(err, "delete authors")
 }
@@ -57,6 +57,18 @@ func (ra *ResourceAuthor) GetLastName() string {
 	return *ra.LastName
 }
 
+// GetForceDestroy is a value accessor for a pointer field; a safe
+// dereference. (The goal is to make code that can be autogenerated.)
+func (ra *ResourceAuthor) GetForceDestroy() bool {
+	ra.mutex.RLock()
+	defer ra.mutex.RUnlock()
+
+	if ra.ForceDestroy == nil {
+		return false
+	}
+	return *ra.ForceDestroy
+}
+
 // GetBookCount is a value accessor for a pointer field; a safe dereference.
 // (The goal is to make code that can be autogenerated.)
 func (ra *ResourceAuthor) GetBookCount() int {
@@ -69,6 +81,18 @@ func (ra *ResourceAuthor) GetBookCount() int {
 	return *ra.BookCount
 }
 
+// GetVersion is a value accessor for a pointer field; a safe dereference.
+// (The goal is to make code that can be autogenerated.)
+func (ra *ResourceAuthor) GetVersion() int {
+	ra.mutex.RLock()
+	defer ra.mutex.RUnlock()
+
+	if ra.Version == nil {
+		return 0
+	}
+	return *ra.Version
+}
+
 // GetID is a value accessor for a pointer field; a safe dereference.
 // (The goal is to make code that can be autogenerated.)
 func (ra *ResourceAuthor) GetID() uuid.UUID {
@@ -84,10 +108,12 @@ func (ra *ResourceAuthor) GetID() uuid.UUID {
 // Changed detects if any of the user input fields have changed.
 // (The goal is to make code that can be autogenerated.)
 func (ra *ResourceAuthor) Changed() bool {
-	//    [INPUT] first_name | string            | required
-	//    [INPUT] last_name  | string            | required
-	// [COMPUTED] book_count | int               | computed
-	// [COMPUTED] id         | uuid.UUID->string | computed
+	//    [INPUT] first_name    | string            | required
+	//    [INPUT] last_name     | string            | required
+	//    [LOCAL] force_destroy | bool              | optional
+	// [COMPUTED] book_count    | int               | computed
+	// [COMPUTED] version       | int               | computed
+	// [COMPUTED] id            | uuid.UUID->string | computed
 	return ra.d.HasChange("first_name") || ra.d.HasChange("last_name")
 }
 
@@ -119,6 +145,16 @@ func (ra *ResourceAuthor) Populate() error {
 		return err
 	}
 
+	// force_destroy | bool | optional
+	forceDestroy, ok := ra.d.Get("force_destroy").(bool)
+	if !ok {
+		err = terraform.DiagnosticError{
+			Summary: "Could not determine author force destroy",
+			Detail:  "Invalid force destroy parameter type",
+		}
+		return err
+	}
+
 	// book_count | int | computed
 	bookCount := ra.BookCount
 	bookCountInterface := ra.d.Get("book_count")
@@ -134,6 +170,21 @@ func (ra *ResourceAuthor) Populate() error {
 		bookCount = &bc
 	}
 
+	// version | int | computed
+	version := ra.Version
+	versionInterface := ra.d.Get("version")
+	if versionInterface != nil {
+		v, ok := versionInterface.(int)
+		if !ok {
+			err = terraform.DiagnosticError{
+				Summary: "Could not determine author version",
+				Detail:  "Invalid version parameter type",
+			}
+			return err
+		}
+		version = &v
+	}
+
 	// id | uuid.UUID->string | computed
 	idStr := ra.d.Id()
 	id := ra.ID
@@ -148,7 +199,9 @@ func (ra *ResourceAuthor) Populate() error {
 	// Only populate fields after all parsing is complete without error.
 	ra.FirstName = &firstName
 	ra.LastName = &lastName
+	ra.ForceDestroy = &forceDestroy
 	ra.BookCount = bookCount
+	ra.Version = version
 	ra.ID = id
 	return nil
 }
@@ -158,10 +211,10 @@ func (ra *ResourceAuthor) Populate() error {
 // can be autogenerated.)
 //
 // NOTE: This method only takes a read lock for the exported fields,
-//       `FirstName`, `LastName`, `BookCount` and `ID`. This method does do
-//       some "writes" to `ra.d` but the lock is not intended to make
-//       `schema.ResourceData` concurrency-safe (it is already via
-//       `MapFieldWriter`).
+//       `FirstName`, `LastName`, `ForceDestroy`, `BookCount`, `Version` and
+//       `ID`. This method does do some "writes" to `ra.d` but the lock is
+//       not intended to make `schema.ResourceData` concurrency-safe (it is
+//       already via `MapFieldWriter`).
 func (ra *ResourceAuthor) Persist() error {
 	ra.mutex.RLock()
 	defer ra.mutex.RUnlock()
@@ -180,6 +233,13 @@ func (ra *ResourceAuthor) Persist() error {
 		}
 	}
 
+	if ra.ForceDestroy != nil {
+		err := ra.d.Set("force_destroy", *ra.ForceDestroy)
+		if err != nil {
+			return err
+		}
+	}
+
 	if ra.BookCount != nil {
 		err := ra.d.Set("book_count", *ra.BookCount)
 		if err != nil {
@@ -187,6 +247,13 @@ func (ra *ResourceAuthor) Persist() error {
 		}
 	}
 
+	if ra.Version != nil {
+		err := ra.d.Set("version", *ra.Version)
+		if err != nil {
+			return err
+		}
+	}
+
 	if ra.ID != nil {
 		ra.d.SetId(ra.ID.String())
 	}
@@ -208,11 +275,22 @@ func (*ResourceAuthor) Schema() map[string]*schema.Schema {
 			Type:     schema.TypeString,
 			Required: true,
 		},
+		// force_destroy | bool | optional
+		"force_destroy": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
 		// book_count | int | computed
 		"book_count": {
 			Type:     schema.TypeInt,
 			Computed: true,
 		},
+		// version | int | computed
+		"version": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
 		// id | uuid.UUID->string | computed
 		"id": {
 			Type:     schema.TypeString,
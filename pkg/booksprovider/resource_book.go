@@ -16,12 +16,14 @@ package booksprovider
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	"github.com/dhermes/example-terraform-provider/pkg/booksclient"
+	"github.com/dhermes/example-terraform-provider/pkg/terraform"
 )
 
 // ResourceBook represents a `books_api_book` resource and directly
@@ -34,6 +36,7 @@ type ResourceBook struct {
 	Title       *string    `terraform:"title,required"`
 	AuthorID    *uuid.UUID `terraform:"author_id,string,required"`
 	PublishDate *Date      `terraform:"publish_date,string,required"`
+	Version     *int       `terraform:"version,computed"`
 	ID          *uuid.UUID `terraform:"id,string,computed"`
 }
 
@@ -47,7 +50,7 @@ func (rb *ResourceBook) Create(ctx context.Context, c booksclient.Client) error
 	b := booksclient.Book{Title: rb.GetTitle(), AuthorID: rb.GetAuthorID(), PublishDate: &pd.Time}
 	abr, err := c.AddBook(ctx, b)
 	if err != nil {
-		return err
+		return permissionError(err, "add books")
 	}
 
 	rb.ID = &abr.BookID
@@ -62,12 +65,26 @@ func (rb *ResourceBook) Create(ctx context.Context, c booksclient.Client) error
 // Read is the read (R) component of the CRUD lifecycle for
 // the `books_api_book` resource.
 //
+// If the book no longer exists, it is removed from state and a warning
+// diagnostic is returned (as an error) instead of failing outright.
+//
 // NOTE: This assumes the called has already invoked `rb.Populate()`, either
 //       directly or indirectly, e.g. via `NewResourceBook()`.
 func (rb *ResourceBook) Read(ctx context.Context, c booksclient.Client) error {
 	id := rb.GetID()
 	gbbir := booksclient.GetBookByIDRequest{BookID: id}
 	b, err := c.GetBookByID(ctx, gbbir)
+	if booksclient.IsNotFound(err) {
+		// NOTE: Clearing the ID removes the resource from state, so Terraform
+		//       will propose re-creating it rather than failing the plan.
+		rb.ID = nil
+		rb.d.SetId("")
+		err = terraform.DiagnosticWarning{
+			Summary: "Book no longer exists",
+			Detail:  fmt.Sprintf("Book %s was not found in the Books API (it may have been deleted outside of Terraform); removing it from state%s", id, requestIDSuffix(err)),
+		}
+		return err
+	}
 	if err != nil {
 		return err
 	}
@@ -77,6 +94,8 @@ func (rb *ResourceBook) Read(ctx context.Context, c booksclient.Client) error {
 	if b.PublishDate != nil {
 		rb.PublishDate = &Date{Time: *b.PublishDate}
 	}
+	v := int(b.Version)
+	rb.Version = &v
 	return rb.Persist()
 }
 
@@ -90,12 +109,30 @@ func (rb *ResourceBook) Update(ctx context.Context, c booksclient.Client) error
 		return rb.Read(ctx, c)
 	}
 
-	pd := rb.PublishDate
-	b := booksclient.Book{ID: rb.ID, Title: rb.GetTitle(), AuthorID: rb.GetAuthorID(), PublishDate: &pd.Time}
-	_, err := c.UpdateBook(ctx, b)
-	if err != nil {
+	// NOTE: Only the changed fields are sent (as a merge patch) and sending
+	//       the version from state makes the update conditional, so it fails
+	//       if the book was modified since the last refresh.
+	pbr := booksclient.PatchBookRequest{BookID: rb.GetID(), Version: int64(rb.GetVersion())}
+	if rb.d.HasChange("title") {
+		pbr.Title = rb.Title
+	}
+	if rb.d.HasChange("author_id") {
+		pbr.AuthorID = rb.AuthorID
+	}
+	if rb.d.HasChange("publish_date") {
+		pbr.PublishDate = &rb.PublishDate.Time
+	}
+	_, err := c.PatchBook(ctx, pbr)
+	if booksclient.IsPreconditionFailed(err) {
+		err = terraform.DiagnosticError{
+			Summary: "Book was modified outside of Terraform",
+			Detail:  fmt.Sprintf("Book %s changed in the Books API since it was last refreshed; refresh and review the plan before applying again%s", rb.GetID(), requestIDSuffix(err)),
+		}
 		return err
 	}
+	if err != nil {
+		return permissionError(err, "update books")
+	}
 
 	return rb.Read(ctx, c)
 }
@@ -109,5 +146,9 @@ func (rb *ResourceBook) Delete(ctx context.Context, c booksclient.Client) error
 	id := rb.GetID()
 	dbr := booksclient.DeleteBookRequest{BookID: id}
 	_, err := c.DeleteBookByID(ctx, dbr)
-	return err
+	if booksclient.IsNotFound(err) {
+		// NOTE: Already deleted (e.g. outside of Terraform) is the desired end state.
+		return nil
+	}
+	return permissionError(err, "delete books")
 }
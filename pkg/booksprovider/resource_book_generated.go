@@ -69,6 +69,18 @@ func (rb *ResourceBook) GetPublishDate() Date {
 	return *rb.PublishDate
 }
 
+// GetVersion is a value accessor for a pointer field; a safe dereference.
+// (The goal is to make code that can be autogenerated.)
+func (rb *ResourceBook) GetVersion() int {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+
+	if rb.Version == nil {
+		return 0
+	}
+	return *rb.Version
+}
+
 // GetID is a value accessor for a pointer field; a safe dereference.
 // (The goal is to make code that can be autogenerated.)
 func (rb *ResourceBook) GetID() uuid.UUID {
@@ -87,6 +99,7 @@ func (rb *ResourceBook) Changed() bool {
 	//    [INPUT] title        | string            | required
 	//    [INPUT] author_id    | uuid.UUID->string | required
 	//    [INPUT] publish_date | Date->string      | required
+	// [COMPUTED] version      | int               | computed
 	// [COMPUTED] id           | uuid.UUID->string | computed
 	return rb.d.HasChange("title") || rb.d.HasChange("author_id") || rb.d.HasChange("publish_date")
 }
@@ -137,6 +150,21 @@ func (rb *ResourceBook) Populate() error {
 		return err
 	}
 
+	// version | int | computed
+	version := rb.Version
+	versionInterface := rb.d.Get("version")
+	if versionInterface != nil {
+		v, ok := versionInterface.(int)
+		if !ok {
+			err = terraform.DiagnosticError{
+				Summary: "Could not determine book version",
+				Detail:  "Invalid version parameter type",
+			}
+			return err
+		}
+		version = &v
+	}
+
 	// id | uuid.UUID->string | computed
 	idStr := rb.d.Id()
 	id := rb.ID
@@ -152,6 +180,7 @@ func (rb *ResourceBook) Populate() error {
 	rb.Title = &title
 	rb.AuthorID = &authorID
 	rb.PublishDate = &pd
+	rb.Version = version
 	rb.ID = id
 	return nil
 }
@@ -161,9 +190,9 @@ func (rb *ResourceBook) Populate() error {
 // can be autogenerated.)
 //
 // NOTE: This method only takes a read lock for the exported fields,
-//       `Title`, `AuthorID`, `PublishDate` and `ID`. This method does do
-//       some "writes" to `ra.d` but the lock is not intended to make
-//       `schema.ResourceData` concurrency-safe (it is already via
+//       `Title`, `AuthorID`, `PublishDate`, `Version` and `ID`. This
+//       method does do some "writes" to `ra.d` but the lock is not intended
+//       to make `schema.ResourceData` concurrency-safe (it is already via
 //       `MapFieldWriter`).
 func (rb *ResourceBook) Persist() error {
 	rb.mutex.RLock()
@@ -190,6 +219,13 @@ func (rb *ResourceBook) Persist() error {
 		}
 	}
 
+	if rb.Version != nil {
+		err := rb.d.Set("version", *rb.Version)
+		if err != nil {
+			return err
+		}
+	}
+
 	if rb.ID != nil {
 		rb.d.SetId(rb.ID.String())
 	}
@@ -216,6 +252,11 @@ func (*ResourceBook) Schema() map[string]*schema.Schema {
 			Type:     schema.TypeString,
 			Required: true,
 		},
+		// version | int | computed
+		"version": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
 		// id | uuid.UUID->string | computed
 		"id": {
 			Type:     schema.TypeString,
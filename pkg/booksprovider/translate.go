@@ -15,11 +15,13 @@
 package booksprovider
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/dhermes/example-terraform-provider/pkg/booksclient"
 	"github.com/dhermes/example-terraform-provider/pkg/terraform"
 )
 
@@ -48,3 +50,29 @@ func dateFromString(dateStr, fieldName string) (Date, error) {
 	}
 	return Date{}, err
 }
+
+// permissionError converts a `booksclient.PermissionDeniedError` into a
+// diagnostic explaining that the configured token cannot make changes; other
+// errors are returned unchanged.
+func permissionError(err error, action string) error {
+	var pde *booksclient.PermissionDeniedError
+	if !errors.As(err, &pde) {
+		return err
+	}
+
+	return terraform.DiagnosticError{
+		Summary: "This token cannot apply",
+		Detail:  fmt.Sprintf("The Books API token does not have permission to %s (%s); use a token with a role that allows it%s", action, pde.Message, requestIDSuffix(err)),
+	}
+}
+
+// requestIDSuffix describes the Books API request that failed with `err`, so
+// a diagnostic can be matched to the server logs; it is empty if `err` does
+// not carry a request ID.
+func requestIDSuffix(err error) string {
+	var ae *booksclient.APIError
+	if !errors.As(err, &ae) || ae.RequestID == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (request ID %s)", ae.RequestID)
+}
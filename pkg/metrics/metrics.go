@@ -0,0 +1,307 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NOTE: Ensure that
+//       * `*Registry` satisfies `http.Handler`.
+var (
+	_ http.Handler = (*Registry)(nil)
+)
+
+const (
+	// ContentType is the content type of the Prometheus text exposition format.
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	// DefaultBuckets are histogram buckets (in seconds) suitable for request
+	// and query latencies; these match the Prometheus client defaults.
+	DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+)
+
+// collector is a single metric family that can write itself in the text
+// exposition format.
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds a set of metric families.
+type Registry struct {
+	mutex      sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry returns a new, empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Counter registers a new counter family with the given label names.
+func (r *Registry) Counter(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		values:     map[string]*counterValue{},
+	}
+	r.register(c)
+	return c
+}
+
+// Histogram registers a new histogram family with the given (sorted,
+// ascending) bucket upper bounds and label names.
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	h := &HistogramVec{
+		name:       name,
+		help:       help,
+		buckets:    buckets,
+		labelNames: labelNames,
+		values:     map[string]*histogramValue{},
+	}
+	r.register(h)
+	return h
+}
+
+// GaugeFunc registers a gauge (without labels) whose value is computed by
+// calling `fn` at scrape time.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(&valueFunc{name: name, help: help, metricType: "gauge", fn: fn})
+}
+
+// CounterFunc registers a counter (without labels) whose value is computed by
+// calling `fn` at scrape time; `fn` must never decrease, e.g. it may read a
+// running total kept elsewhere.
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(&valueFunc{name: name, help: help, metricType: "counter", fn: fn})
+}
+
+// Write writes every registered metric family in the text exposition format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	collectors := make([]collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.mutex.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP satisfies the `http.Handler` interface; it serves the registry
+// in the text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(http.StatusOK)
+	r.Write(w)
+}
+
+// CounterVec is a counter family partitioned by labels.
+type CounterVec struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mutex  sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+// Inc increments the counter for the given label values by 1.
+func (cv *CounterVec) Inc(labelValues ...string) {
+	cv.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by `v`.
+func (cv *CounterVec) Add(v float64, labelValues ...string) {
+	key := labelKey(labelValues)
+
+	cv.mutex.Lock()
+	defer cv.mutex.Unlock()
+	cval, ok := cv.values[key]
+	if !ok {
+		cval = &counterValue{labelValues: labelValues}
+		cv.values[key] = cval
+	}
+	cval.value += v
+}
+
+func (cv *CounterVec) write(w *bufio.Writer) {
+	writeHeader(w, cv.name, cv.help, "counter")
+
+	cv.mutex.Lock()
+	defer cv.mutex.Unlock()
+	keys := make([]string, 0, len(cv.values))
+	for key := range cv.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cval := cv.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", cv.name, formatLabels(cv.labelNames, cval.labelValues, "", ""), formatFloat(cval.value))
+	}
+}
+
+// HistogramVec is a histogram family partitioned by labels.
+type HistogramVec struct {
+	name       string
+	help       string
+	buckets    []float64
+	labelNames []string
+
+	mutex  sync.Mutex
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64 // Non-cumulative, one per bucket
+	count       uint64
+	sum         float64
+}
+
+// Observe records a single observation for the given label values.
+func (hv *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := labelKey(labelValues)
+
+	hv.mutex.Lock()
+	defer hv.mutex.Unlock()
+	hval, ok := hv.values[key]
+	if !ok {
+		hval = &histogramValue{labelValues: labelValues, counts: make([]uint64, len(hv.buckets))}
+		hv.values[key] = hval
+	}
+
+	i := sort.SearchFloat64s(hv.buckets, v)
+	if i < len(hv.buckets) {
+		hval.counts[i]++
+	}
+	hval.count++
+	hval.sum += v
+}
+
+func (hv *HistogramVec) write(w *bufio.Writer) {
+	writeHeader(w, hv.name, hv.help, "histogram")
+
+	hv.mutex.Lock()
+	defer hv.mutex.Unlock()
+	keys := make([]string, 0, len(hv.values))
+	for key := range hv.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		hval := hv.values[key]
+		cumulative := uint64(0)
+		for i, upper := range hv.buckets {
+			cumulative += hval.counts[i]
+			labels := formatLabels(hv.labelNames, hval.labelValues, "le", formatFloat(upper))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", hv.name, labels, cumulative)
+		}
+		labels := formatLabels(hv.labelNames, hval.labelValues, "le", "+Inf")
+		fmt.Fprintf(w, "%s_bucket%s %d\n", hv.name, labels, hval.count)
+		labels = formatLabels(hv.labelNames, hval.labelValues, "", "")
+		fmt.Fprintf(w, "%s_sum%s %s\n", hv.name, labels, formatFloat(hval.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", hv.name, labels, hval.count)
+	}
+}
+
+// valueFunc is a gauge or counter (without labels) computed at scrape time.
+type valueFunc struct {
+	name       string
+	help       string
+	metricType string
+	fn         func() float64
+}
+
+func (vf *valueFunc) write(w *bufio.Writer) {
+	writeHeader(w, vf.name, vf.help, vf.metricType)
+	fmt.Fprintf(w, "%s %s\n", vf.name, formatFloat(vf.fn()))
+}
+
+func writeHeader(w *bufio.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+// labelKey joins label values with a separator that cannot appear in valid
+// UTF-8 so it can be used as a map key.
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+// formatLabels formats a label set, e.g. `{route="/",status="200"}`; if
+// `extraName` is non-empty that label is appended (used for `le`).
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	parts := []string{}
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, name, escapeLabelValue(value)))
+	}
+	if extraName != "" {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, extraName, extraValue))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
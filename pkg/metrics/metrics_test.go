@@ -0,0 +1,112 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	requests := r.Counter("requests_total", "Total requests,\nwith a \\ in the help.", "method", "path")
+	latency := r.Histogram("latency_seconds", "Latency in seconds.", []float64{0.1, 1}, "method")
+	r.GaugeFunc("temperature", "A gauge.", func() float64 { return math.Inf(1) })
+	r.CounterFunc("waits_total", "A counter read at scrape time.", func() float64 { return 3 })
+
+	requests.Inc("POST", `/a"b`)
+	requests.Add(2.5, "GET", "C:\\books\n")
+	latency.Observe(0.05, "GET")
+	latency.Observe(0.1, "GET")
+	latency.Observe(2, "GET")
+	latency.Observe(0.5, "DELETE")
+
+	w := &bytes.Buffer{}
+	err := r.Write(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP requests_total Total requests,\nwith a \\ in the help.
+# TYPE requests_total counter
+requests_total{method="GET",path="C:\\books\n"} 2.5
+requests_total{method="POST",path="/a\"b"} 1
+# HELP latency_seconds Latency in seconds.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{method="DELETE",le="0.1"} 0
+latency_seconds_bucket{method="DELETE",le="1"} 1
+latency_seconds_bucket{method="DELETE",le="+Inf"} 1
+latency_seconds_sum{method="DELETE"} 0.5
+latency_seconds_count{method="DELETE"} 1
+latency_seconds_bucket{method="GET",le="0.1"} 2
+latency_seconds_bucket{method="GET",le="1"} 2
+latency_seconds_bucket{method="GET",le="+Inf"} 3
+latency_seconds_sum{method="GET"} 2.15
+latency_seconds_count{method="GET"} 3
+# HELP temperature A gauge.
+# TYPE temperature gauge
+temperature +Inf
+# HELP waits_total A counter read at scrape time.
+# TYPE waits_total counter
+waits_total 3
+`
+	if got := w.String(); got != want {
+		t.Fatalf("unexpected exposition\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistryWriteEmpty(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("requests_total", "Total requests.", "method")
+
+	w := &bytes.Buffer{}
+	err := r.Write(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# HELP requests_total Total requests.\n# TYPE requests_total counter\n"
+	if got := w.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.GaugeFunc("up", "Whether the server is up.", func() float64 { return 1 })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Content-Type"); got != ContentType {
+		t.Fatalf("got content type %q, want %q", got, ContentType)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# HELP up Whether the server is up.\n# TYPE up gauge\nup 1\n"
+	if string(body) != want {
+		t.Fatalf("got body %q, want %q", body, want)
+	}
+}
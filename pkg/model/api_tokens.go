@@ -0,0 +1,112 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	insertAPIToken = `
+INSERT INTO
+  api_tokens (id, name, token_hash, role, created_at)
+VALUES
+  ($1, $2, $3, $4, NOW())
+`
+	getAPITokenByHash = `
+SELECT
+  id,
+  name,
+  token_hash,
+  role,
+  created_at,
+  revoked_at
+FROM
+  api_tokens
+WHERE
+  token_hash = $1 AND
+  revoked_at IS NULL
+`
+	revokeAPIToken = `
+UPDATE
+  api_tokens
+SET
+  revoked_at = NOW()
+WHERE
+  id = $1 AND
+  revoked_at IS NULL
+`
+)
+
+// InsertAPIToken inserts a new API token into the database. Only the hash
+// of the token is provided; the token itself is never stored.
+func InsertAPIToken(ctx context.Context, pool *sql.DB, t APIToken) (uuid.UUID, error) {
+	defer observeQuery(ctx, "insert_api_token", time.Now())
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	_, err = pool.ExecContext(ctx, insertAPIToken, id, t.Name, t.TokenHash, t.Role)
+	if err != nil {
+		return uuid.Nil, translateError(err)
+	}
+
+	return id, nil
+}
+
+// GetAPITokenByHash gets an unrevoked API token from the database by the hash
+// of the token.
+func GetAPITokenByHash(ctx context.Context, pool *sql.DB, tokenHash []byte) (*APIToken, error) {
+	defer observeQuery(ctx, "get_api_token_by_hash", time.Now())
+
+	row := pool.QueryRowContext(ctx, getAPITokenByHash, tokenHash)
+
+	t := APIToken{}
+	err := row.Scan(&t.ID, &t.Name, &t.TokenHash, &t.Role, &t.CreatedAt, &t.RevokedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &t, nil
+}
+
+// RevokeAPIToken marks an API token as revoked; revoked tokens can no longer
+// be used to authenticate.
+func RevokeAPIToken(ctx context.Context, pool *sql.DB, id uuid.UUID) error {
+	defer observeQuery(ctx, "revoke_api_token", time.Now())
+
+	result, err := pool.ExecContext(ctx, revokeAPIToken, id)
+	if err != nil {
+		return translateError(err)
+	}
+
+	revokeCount, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if revokeCount == 0 {
+		return fmt.Errorf("could not revoke API token, %w", ErrNotFound)
+	}
+
+	return nil
+}
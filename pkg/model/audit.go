@@ -0,0 +1,284 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NOTE: Ensure that
+//       * `*sql.DB` satisfies `txBeginner`.
+var (
+	_ txBeginner = (*sql.DB)(nil)
+)
+
+const (
+	setAuditActor = `
+SELECT
+  set_config('books.audit_actor', $1, TRUE),
+  set_config('books.audit_api_token_id', $2, TRUE)
+`
+	getAuditEvents = `
+SELECT
+  id,
+  occurred_at,
+  actor,
+  api_token_id,
+  action,
+  entity_type,
+  entity_id,
+  before,
+  after
+FROM
+  audit_log
+WHERE
+  ($2::TEXT = '' OR entity_type = $2) AND
+  ($3::UUID IS NULL OR entity_id = $3) AND
+  ($4::TEXT = '' OR actor = $4) AND
+  ($5::TIMESTAMPTZ IS NULL OR occurred_at >= $5) AND
+  ($6::TIMESTAMPTZ IS NULL OR occurred_at < $6) AND
+  (NOT $7::BOOLEAN OR id < $8)
+ORDER BY
+  id DESC
+LIMIT
+  $1
+`
+	getAuditEventsSince = `
+SELECT
+  id,
+  occurred_at,
+  actor,
+  api_token_id,
+  action,
+  entity_type,
+  entity_id,
+  before,
+  after,
+  pg_snapshot_xmin(pg_current_snapshot())::TEXT::BIGINT,
+  pg_snapshot_xmax(pg_current_snapshot())::TEXT::BIGINT
+FROM
+  audit_log
+WHERE
+  id > $2
+ORDER BY
+  id
+LIMIT
+  $1
+`
+	getLatestAuditEventID = `
+SELECT
+  COALESCE(MAX(id), 0)
+FROM
+  audit_log
+`
+)
+
+// txBeginner is the subset of `*sql.DB` used to start a transaction.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// audited runs `fn` in a transaction after recording the actor from the
+// context (see `WithActor()`) for the audit log triggers, so the audit rows
+// are written in the same transaction as the change. If `pool` is already a
+// transaction (e.g. in a batch), `fn` runs directly in it.
+func audited(ctx context.Context, pool Queryer, fn func(tx Queryer) error) error {
+	b, ok := pool.(txBeginner)
+	if !ok {
+		err := setActor(ctx, pool)
+		if err != nil {
+			return err
+		}
+		return fn(pool)
+	}
+
+	tx, err := b.BeginTx(ctx, nil)
+	if err != nil {
+		return translateError(err)
+	}
+	// NOTE: This is a no-op if the transaction has been committed.
+	defer tx.Rollback()
+
+	err = setActor(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return translateError(tx.Commit())
+}
+
+// setActor sets the transaction-local settings read by the audit log
+// triggers. With no actor in the context the change is attributed to
+// `system`.
+func setActor(ctx context.Context, tx Queryer) error {
+	a := GetActor(ctx)
+	apiTokenID := ""
+	if a.APITokenID != uuid.Nil {
+		apiTokenID = a.APITokenID.String()
+	}
+
+	_, err := tx.ExecContext(ctx, setAuditActor, a.Name, apiTokenID)
+	return translateError(err)
+}
+
+// GetAuditEvents gets one page of audit events from the database, newest
+// first.
+//
+// Events are filtered according to `q`. If `after` is `nil`, the first page
+// is returned. At most `limit` events are returned.
+func GetAuditEvents(ctx context.Context, pool *sql.DB, q AuditQuery, limit int, after *AuditCursor) ([]AuditEvent, error) {
+	defer observeQuery(ctx, "get_audit_events", time.Now())
+
+	cursor := AuditCursor{}
+	if after != nil {
+		cursor = *after
+	}
+
+	rows, err := pool.QueryContext(
+		ctx,
+		getAuditEvents,
+		limit,
+		q.EntityType,
+		q.EntityID,
+		q.Actor,
+		q.OccurredAfter,
+		q.OccurredBefore,
+		after != nil,
+		cursor.ID,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return scanAuditEvents(rows)
+}
+
+// AuditFeed reads new audit events in ID order, resuming after the last
+// event it returned.
+//
+// IDs are taken from a sequence when a row is inserted, so a transaction can
+// commit a higher ID while a lower one is held by a transaction that is still
+// running (or will roll back). The feed stops at such a gap rather than skip
+// it. Audit rows are written by triggers after the row they describe, so the
+// transaction holding a missing ID already has a transaction ID, older than
+// the `xmax` of the snapshot that saw the gap; once the snapshot `xmin` reaches that `xmax`, every such
+// transaction has finished and a missing ID can only have been rolled back.
+type AuditFeed struct {
+	last int64
+	// gapID is the first missing ID the feed is waiting on, or zero.
+	gapID int64
+	// gapXmax is the snapshot `xmax` when the feed first saw `gapID` missing.
+	gapXmax int64
+}
+
+// NewAuditFeed returns a feed of the audit events with an ID greater than
+// `last`.
+func NewAuditFeed(last int64) *AuditFeed {
+	return &AuditFeed{last: last}
+}
+
+// Next gets the next audit events, oldest first. At most `limit` events are
+// returned; fewer means the feed has caught up, or is waiting for the
+// transactions that could fill a gap in the IDs to finish.
+func (af *AuditFeed) Next(ctx context.Context, pool *sql.DB, limit int) ([]AuditEvent, error) {
+	defer observeQuery(ctx, "get_audit_events_since", time.Now())
+
+	rows, err := pool.QueryContext(ctx, getAuditEventsSince, limit, af.last)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	events := []AuditEvent{}
+	for rows.Next() {
+		e := AuditEvent{}
+		var xmin, xmax int64
+		err := rows.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.APITokenID, &e.Action, &e.EntityType, &e.EntityID, &e.Before, &e.After, &xmin, &xmax)
+		if err != nil {
+			return nil, err
+		}
+		if e.ID != af.last+1 && !af.gapSettled(xmin, xmax) {
+			break
+		}
+
+		af.gapID = 0
+		af.last = e.ID
+		events = append(events, e)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// gapSettled determines if the IDs missing after `af.last` can be skipped,
+// given the `xmin` and `xmax` of the current snapshot.
+func (af *AuditFeed) gapSettled(xmin, xmax int64) bool {
+	if af.gapID != af.last+1 {
+		af.gapID, af.gapXmax = af.last+1, xmax
+	}
+	return xmin >= af.gapXmax
+}
+
+// GetLatestAuditEventID gets the ID of the most recent audit event, or zero
+// if there are none.
+func GetLatestAuditEventID(ctx context.Context, pool *sql.DB) (int64, error) {
+	defer observeQuery(ctx, "get_latest_audit_event_id", time.Now())
+
+	row := pool.QueryRowContext(ctx, getLatestAuditEventID)
+
+	var id int64
+	err := row.Scan(&id)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return id, nil
+}
+
+// scanAuditEvents reads every row from an audit event query and closes
+// `rows`.
+func scanAuditEvents(rows *sql.Rows) ([]AuditEvent, error) {
+	defer rows.Close()
+
+	events := []AuditEvent{}
+	for rows.Next() {
+		e := AuditEvent{}
+		err := rows.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.APITokenID, &e.Action, &e.EntityType, &e.EntityID, &e.Before, &e.After)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
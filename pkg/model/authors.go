@@ -18,6 +18,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -34,83 +36,311 @@ UPDATE
   authors
 SET
   first_name = $2,
-  last_name = $3
+  last_name = $3,
+  version = version + 1
 WHERE
-  id = $1
+  id = $1 AND
+  deleted_at IS NULL AND
+  ($4::BIGINT = 0 OR version = $4)
+RETURNING
+  version
+`
+	patchAuthor = `
+UPDATE
+  authors
+SET
+  first_name = COALESCE($2, first_name),
+  last_name = COALESCE($3, last_name),
+  version = version + 1
+WHERE
+  id = $1 AND
+  deleted_at IS NULL AND
+  ($4::BIGINT = 0 OR version = $4)
+RETURNING
+  version
 `
 	getAuthorByID = `
 SELECT
   id,
   first_name,
   last_name,
+  version,
   (
     SELECT
       COUNT(*) AS book_count
     FROM
       books AS b
     WHERE
-      b.author_id = a.id
+      b.author_id = a.id AND
+      b.deleted_at IS NULL
   )
 FROM
   authors AS a
 WHERE
-  id = $1
+  id = $1 AND
+  deleted_at IS NULL
 `
 	getAuthorByName = `
 SELECT
   id,
   first_name,
   last_name,
+  version,
   (
     SELECT
       COUNT(*) AS book_count
     FROM
       books AS b
     WHERE
-      b.author_id = a.id
+      b.author_id = a.id AND
+      b.deleted_at IS NULL
   )
 FROM
   authors AS a
 WHERE
   first_name = $1 AND
-  last_name = $2
+  last_name = $2 AND
+  deleted_at IS NULL
 `
 
-	getAllAuthors = `
+	getAuthorsByLastName = `
 SELECT
-  a.id, a.first_name, a.last_name, COALESCE(b.book_count, 0)
+  a.id, a.first_name, a.last_name, a.version, COALESCE(b.book_count, 0)
 FROM
   authors AS a
-FULL OUTER JOIN (
+LEFT OUTER JOIN (
   SELECT
     author_id, COUNT(*) AS book_count
   FROM
     books
+  WHERE
+    deleted_at IS NULL
   GROUP BY
     author_id
 ) AS b
 ON
   a.id = b.author_id
+WHERE
+  a.deleted_at IS NULL AND
+  (
+    $2::TEXT = '' OR
+    a.first_name ILIKE $2 || '%' OR
+    a.last_name ILIKE $2 || '%' OR
+    (a.first_name || ' ' || a.last_name) ILIKE $2 || '%'
+  ) AND
+  (
+    NOT $3::BOOLEAN OR
+    (a.last_name, a.first_name, a.id) > ($4::TEXT, $5::TEXT, $6::UUID)
+  )
+ORDER BY
+  a.last_name, a.first_name, a.id
+LIMIT
+  $1
+`
+	getAuthorsByLastNameDesc = `
+SELECT
+  a.id, a.first_name, a.last_name, a.version, COALESCE(b.book_count, 0)
+FROM
+  authors AS a
+LEFT OUTER JOIN (
+  SELECT
+    author_id, COUNT(*) AS book_count
+  FROM
+    books
+  WHERE
+    deleted_at IS NULL
+  GROUP BY
+    author_id
+) AS b
+ON
+  a.id = b.author_id
+WHERE
+  a.deleted_at IS NULL AND
+  (
+    $2::TEXT = '' OR
+    a.first_name ILIKE $2 || '%' OR
+    a.last_name ILIKE $2 || '%' OR
+    (a.first_name || ' ' || a.last_name) ILIKE $2 || '%'
+  ) AND
+  (
+    NOT $3::BOOLEAN OR
+    (a.last_name, a.first_name, a.id) < ($4::TEXT, $5::TEXT, $6::UUID)
+  )
+ORDER BY
+  a.last_name DESC, a.first_name DESC, a.id DESC
+LIMIT
+  $1
+`
+	getAuthorsByBookCount = `
+SELECT
+  a.id, a.first_name, a.last_name, a.version, COALESCE(b.book_count, 0)
+FROM
+  authors AS a
+LEFT OUTER JOIN (
+  SELECT
+    author_id, COUNT(*) AS book_count
+  FROM
+    books
+  WHERE
+    deleted_at IS NULL
+  GROUP BY
+    author_id
+) AS b
+ON
+  a.id = b.author_id
+WHERE
+  a.deleted_at IS NULL AND
+  (
+    $2::TEXT = '' OR
+    a.first_name ILIKE $2 || '%' OR
+    a.last_name ILIKE $2 || '%' OR
+    (a.first_name || ' ' || a.last_name) ILIKE $2 || '%'
+  ) AND
+  (
+    NOT $3::BOOLEAN OR
+    (COALESCE(b.book_count, 0), a.id) > ($4::BIGINT, $5::UUID)
+  )
+ORDER BY
+  COALESCE(b.book_count, 0), a.id
+LIMIT
+  $1
+`
+	getAuthorsByBookCountDesc = `
+SELECT
+  a.id, a.first_name, a.last_name, a.version, COALESCE(b.book_count, 0)
+FROM
+  authors AS a
+LEFT OUTER JOIN (
+  SELECT
+    author_id, COUNT(*) AS book_count
+  FROM
+    books
+  WHERE
+    deleted_at IS NULL
+  GROUP BY
+    author_id
+) AS b
+ON
+  a.id = b.author_id
+WHERE
+  a.deleted_at IS NULL AND
+  (
+    $2::TEXT = '' OR
+    a.first_name ILIKE $2 || '%' OR
+    a.last_name ILIKE $2 || '%' OR
+    (a.first_name || ' ' || a.last_name) ILIKE $2 || '%'
+  ) AND
+  (
+    NOT $3::BOOLEAN OR
+    (COALESCE(b.book_count, 0), a.id) < ($4::BIGINT, $5::UUID)
+  )
+ORDER BY
+  COALESCE(b.book_count, 0) DESC, a.id DESC
+LIMIT
+  $1
 `
 	deleteAuthorByID = `
-DELETE FROM
+UPDATE
   authors AS a
+SET
+  deleted_at = NOW(),
+  version = a.version + 1
 WHERE
   a.id = $1 AND
+  a.deleted_at IS NULL AND
+  ($2::BIGINT = 0 OR a.version = $2) AND
+  NOT EXISTS (
+    SELECT 1 FROM books AS b WHERE b.author_id = a.id AND b.deleted_at IS NULL FOR UPDATE
+  )
+`
+	deleteAuthorByIDCascade = `
+WITH
+  deleted_author AS (
+    UPDATE
+      authors AS a
+    SET
+      deleted_at = NOW(),
+      version = a.version + 1
+    WHERE
+      a.id = $1 AND
+      a.deleted_at IS NULL AND
+      ($2::BIGINT = 0 OR a.version = $2)
+    RETURNING
+      a.id
+  ),
+  deleted_books AS (
+    UPDATE
+      books AS b
+    SET
+      deleted_at = NOW(),
+      version = b.version + 1
+    FROM
+      deleted_author AS da
+    WHERE
+      b.author_id = da.id AND
+      b.deleted_at IS NULL
+    RETURNING
+      b.id
+  )
+SELECT
+  (SELECT COUNT(*) FROM deleted_author),
+  (SELECT COUNT(*) FROM deleted_books)
+`
+	restoreAuthorByID = `
+UPDATE
+  authors
+SET
+  deleted_at = NULL,
+  version = version + 1
+WHERE
+  id = $1 AND
+  deleted_at IS NOT NULL
+RETURNING
+  version
+`
+	getDeletedAuthors = `
+SELECT
+  id,
+  first_name,
+  last_name,
+  version,
+  deleted_at
+FROM
+  authors
+WHERE
+  deleted_at IS NOT NULL
+ORDER BY
+  deleted_at DESC, id
+`
+	purgeDeletedAuthors = `
+DELETE FROM
+  authors AS a
+WHERE
+  a.deleted_at < $1 AND
   NOT EXISTS (
-    SELECT 1 FROM books AS b WHERE b.author_id = a.id FOR UPDATE
+    SELECT 1 FROM books AS b WHERE b.author_id = a.id
   )
 `
 )
 
-// InsertAuthor inserts an author into the database.
-func InsertAuthor(ctx context.Context, pool *sql.DB, a Author) (uuid.UUID, error) {
-	id, err := uuid.NewRandom()
-	if err != nil {
-		return uuid.Nil, err
+// InsertAuthor inserts an author into the database. If `a.ID` is set it is
+// used as the ID, otherwise a random ID is generated.
+func InsertAuthor(ctx context.Context, pool Queryer, a Author) (uuid.UUID, error) {
+	defer observeQuery(ctx, "insert_author", time.Now())
+
+	var err error
+	id := a.ID
+	if id == uuid.Nil {
+		id, err = uuid.NewRandom()
+		if err != nil {
+			return uuid.Nil, err
+		}
 	}
 
-	_, err = pool.ExecContext(ctx, insertAuthor, id, a.FirstName, a.LastName)
+	err = audited(ctx, pool, func(tx Queryer) error {
+		_, err := tx.ExecContext(ctx, insertAuthor, id, a.FirstName, a.LastName)
+		return translateError(err)
+	})
 	if err != nil {
 		return uuid.Nil, err
 	}
@@ -118,65 +348,117 @@ func InsertAuthor(ctx context.Context, pool *sql.DB, a Author) (uuid.UUID, error
 	return id, nil
 }
 
-// UpdateAuthor updates an author from the database directly by ID.
-func UpdateAuthor(ctx context.Context, pool *sql.DB, a Author) error {
-	result, err := pool.ExecContext(ctx, updateAuthor, a.ID, a.FirstName, a.LastName)
-	if err != nil {
-		return err
-	}
+// UpdateAuthor updates an author from the database directly by ID and
+// returns the new version. If `a.Version` is non-zero, the update only
+// succeeds if the stored version matches.
+func UpdateAuthor(ctx context.Context, pool Queryer, a Author) (int64, error) {
+	defer observeQuery(ctx, "update_author", time.Now())
 
-	updateCount, err := result.RowsAffected()
+	var version int64
+	err := audited(ctx, pool, func(tx Queryer) error {
+		row := tx.QueryRowContext(ctx, updateAuthor, a.ID, a.FirstName, a.LastName, a.Version)
+		err := row.Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return updateAuthorFailure(ctx, tx, a.ID)
+		}
+		return translateError(err)
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	if updateCount == 0 {
-		return errors.New("could not update author, does not exist")
+	return version, nil
+}
+
+// PatchAuthor partially updates an author from the database directly by ID
+// and returns the new version. Only the non-`nil` fields in `p` are updated.
+// If `version` is non-zero, the update only succeeds if the stored version
+// matches.
+func PatchAuthor(ctx context.Context, pool Queryer, id uuid.UUID, p AuthorPatch, version int64) (int64, error) {
+	defer observeQuery(ctx, "patch_author", time.Now())
+
+	var newVersion int64
+	err := audited(ctx, pool, func(tx Queryer) error {
+		row := tx.QueryRowContext(ctx, patchAuthor, id, p.FirstName, p.LastName, version)
+		err := row.Scan(&newVersion)
+		if errors.Is(err, sql.ErrNoRows) {
+			return updateAuthorFailure(ctx, tx, id)
+		}
+		return translateError(err)
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return newVersion, nil
 }
 
 // GetAuthorByID gets an author from the database by ID.
-func GetAuthorByID(ctx context.Context, pool *sql.DB, id uuid.UUID) (*Author, error) {
+func GetAuthorByID(ctx context.Context, pool Queryer, id uuid.UUID) (*Author, error) {
+	defer observeQuery(ctx, "get_author_by_id", time.Now())
+
 	row := pool.QueryRowContext(ctx, getAuthorByID, id)
 
 	a := Author{}
-	err := row.Scan(&a.ID, &a.FirstName, &a.LastName, &a.BookCount)
+	err := row.Scan(&a.ID, &a.FirstName, &a.LastName, &a.Version, &a.BookCount)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	return &a, nil
 }
 
 // GetAuthorByName gets an author from the database by name.
-func GetAuthorByName(ctx context.Context, pool *sql.DB, firstName, lastName string) (*Author, error) {
+func GetAuthorByName(ctx context.Context, pool Queryer, firstName, lastName string) (*Author, error) {
+	defer observeQuery(ctx, "get_author_by_name", time.Now())
+
 	row := pool.QueryRowContext(ctx, getAuthorByName, firstName, lastName)
 
 	a := Author{}
-	err := row.Scan(&a.ID, &a.FirstName, &a.LastName, &a.BookCount)
+	err := row.Scan(&a.ID, &a.FirstName, &a.LastName, &a.Version, &a.BookCount)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	return &a, nil
 }
 
-// GetAllAuthors gets (all) authors from the database.
+// GetAuthors gets one page of authors from the database.
 //
-// This function does not use paging (but it would in a real application).
-func GetAllAuthors(ctx context.Context, pool *sql.DB) ([]Author, error) {
-	rows, err := pool.QueryContext(ctx, getAllAuthors)
+// Authors are filtered and ordered according to `q`; every ordering ends
+// with the ID so it is stable and `after` can be used as a keyset cursor. If
+// `after` is `nil`, the first page is returned. At most `limit` authors are
+// returned.
+func GetAuthors(ctx context.Context, pool Queryer, q AuthorQuery, limit int, after *AuthorCursor) ([]Author, error) {
+	defer observeQuery(ctx, "get_authors", time.Now())
+
+	cursor := AuthorCursor{}
+	if after != nil {
+		cursor = *after
+	}
+	namePrefix := escapeLike(q.NamePrefix)
+
+	var rows *sql.Rows
+	var err error
+	switch {
+	case q.OrderBy == AuthorOrderBookCount && q.Descending:
+		rows, err = pool.QueryContext(ctx, getAuthorsByBookCountDesc, limit, namePrefix, after != nil, cursor.BookCount, cursor.ID)
+	case q.OrderBy == AuthorOrderBookCount:
+		rows, err = pool.QueryContext(ctx, getAuthorsByBookCount, limit, namePrefix, after != nil, cursor.BookCount, cursor.ID)
+	case q.Descending:
+		rows, err = pool.QueryContext(ctx, getAuthorsByLastNameDesc, limit, namePrefix, after != nil, cursor.LastName, cursor.FirstName, cursor.ID)
+	default:
+		rows, err = pool.QueryContext(ctx, getAuthorsByLastName, limit, namePrefix, after != nil, cursor.LastName, cursor.FirstName, cursor.ID)
+	}
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	defer rows.Close()
 
 	authors := []Author{}
 	for rows.Next() {
 		a := Author{}
-		err = rows.Scan(&a.ID, &a.FirstName, &a.LastName, &a.BookCount)
+		err = rows.Scan(&a.ID, &a.FirstName, &a.LastName, &a.Version, &a.BookCount)
 		if err != nil {
 			return nil, err
 		}
@@ -191,21 +473,160 @@ func GetAllAuthors(ctx context.Context, pool *sql.DB) ([]Author, error) {
 	return authors, nil
 }
 
-// DeleteAuthorByID deletes an author from the database by ID.
-func DeleteAuthorByID(ctx context.Context, pool *sql.DB, id uuid.UUID) error {
-	result, err := pool.ExecContext(ctx, deleteAuthorByID, id)
+// DeleteAuthorByID deletes an author from the database by ID. This is a soft
+// delete: the author is moved to the trash (`deleted_at` is set) and can be
+// restored until it is purged. If `version` is non-zero, the delete only
+// succeeds if the stored version matches.
+func DeleteAuthorByID(ctx context.Context, pool Queryer, id uuid.UUID, version int64) error {
+	defer observeQuery(ctx, "delete_author_by_id", time.Now())
+
+	return audited(ctx, pool, func(tx Queryer) error {
+		result, err := tx.ExecContext(ctx, deleteAuthorByID, id, version)
+		if err != nil {
+			return err
+		}
+
+		deleteCount, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if deleteCount == 0 {
+			return deleteAuthorFailure(ctx, tx, id, version)
+		}
+
+		return nil
+	})
+}
+
+// DeleteAuthorByIDCascade deletes an author from the database by ID along
+// with all of their books, and returns the number of books deleted. Both
+// (soft) deletes happen in a single statement, so they are atomic. If `version` is
+// non-zero, the delete only succeeds if the stored version matches.
+func DeleteAuthorByIDCascade(ctx context.Context, pool Queryer, id uuid.UUID, version int64) (int64, error) {
+	defer observeQuery(ctx, "delete_author_by_id_cascade", time.Now())
+
+	var booksDeleted int64
+	err := audited(ctx, pool, func(tx Queryer) error {
+		row := tx.QueryRowContext(ctx, deleteAuthorByIDCascade, id, version)
+
+		var deleteCount int64
+		err := row.Scan(&deleteCount, &booksDeleted)
+		if err != nil {
+			return translateError(err)
+		}
+
+		if deleteCount == 0 {
+			return deleteAuthorFailure(ctx, tx, id, version)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	deleteCount, err := result.RowsAffected()
+	return booksDeleted, nil
+}
+
+// RestoreAuthorByID restores a deleted author from the trash and returns the
+// new version. Books deleted along with the author are not restored.
+func RestoreAuthorByID(ctx context.Context, pool Queryer, id uuid.UUID) (int64, error) {
+	defer observeQuery(ctx, "restore_author_by_id", time.Now())
+
+	var version int64
+	err := audited(ctx, pool, func(tx Queryer) error {
+		row := tx.QueryRowContext(ctx, restoreAuthorByID, id)
+		return translateError(row.Scan(&version))
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not restore author, %w", err)
+	}
+
+	return version, nil
+}
+
+// GetDeletedAuthors gets all authors in the trash, most recently deleted
+// first. The book count is not populated.
+func GetDeletedAuthors(ctx context.Context, pool Queryer) ([]Author, error) {
+	defer observeQuery(ctx, "get_deleted_authors", time.Now())
+
+	rows, err := pool.QueryContext(ctx, getDeletedAuthors)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	authors := []Author{}
+	for rows.Next() {
+		a := Author{}
+		err = rows.Scan(&a.ID, &a.FirstName, &a.LastName, &a.Version, &a.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		authors = append(authors, a)
+	}
+
+	err = rows.Err()
 	if err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
+
+// PurgeDeletedAuthors permanently deletes authors that were deleted before
+// `deletedBefore` and returns the number purged. Authors that still have
+// books (deleted or not) are kept, so books should be purged first.
+func PurgeDeletedAuthors(ctx context.Context, pool Queryer, deletedBefore time.Time) (int64, error) {
+	defer observeQuery(ctx, "purge_deleted_authors", time.Now())
+
+	var purged int64
+	err := audited(ctx, pool, func(tx Queryer) error {
+		result, err := tx.ExecContext(ctx, purgeDeletedAuthors, deletedBefore)
+		if err != nil {
+			return translateError(err)
+		}
+
+		purged, err = result.RowsAffected()
 		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// updateAuthorFailure determines why `updateAuthor` did not update a row:
+// either the author does not exist or its version did not match.
+func updateAuthorFailure(ctx context.Context, pool Queryer, id uuid.UUID) error {
+	_, err := GetAuthorByID(ctx, pool, id)
+	if err != nil {
+		return fmt.Errorf("could not update author, %w", err)
+	}
+
+	return fmt.Errorf("could not update author, %w", ErrVersionMismatch)
+}
+
+// deleteAuthorFailure determines why `deleteAuthorByID` did not delete a
+// row: either the author does not exist, its version did not match or it
+// still has books.
+func deleteAuthorFailure(ctx context.Context, pool Queryer, id uuid.UUID, version int64) error {
+	a, err := GetAuthorByID(ctx, pool, id)
+	if err != nil {
+		return fmt.Errorf("could not delete author, %w", err)
+	}
+
+	if version != 0 && a.Version != version {
+		return fmt.Errorf("could not delete author, %w", ErrVersionMismatch)
 	}
 
-	if deleteCount == 0 {
-		return errors.New("could not delete author, does not exist or still has books")
+	if a.BookCount > 0 {
+		return fmt.Errorf("could not delete author, %w", ErrHasBooks)
 	}
 
-	return nil
+	// NOTE: This is only reachable if the author was concurrently created
+	//       or had its books removed; treat it as a retryable conflict.
+	return fmt.Errorf("could not delete author, %w", ErrConflict)
 }
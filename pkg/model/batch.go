@@ -0,0 +1,120 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// NOTE: Ensure that
+//       * `*sql.DB` satisfies `Queryer`.
+//       * `*sql.Tx` satisfies `Queryer`.
+var (
+	_ Queryer = (*sql.DB)(nil)
+	_ Queryer = (*sql.Tx)(nil)
+)
+
+const (
+	batchSavepoint         = "SAVEPOINT batch_operation"
+	batchRollbackSavepoint = "ROLLBACK TO SAVEPOINT batch_operation"
+	batchReleaseSavepoint  = "RELEASE SAVEPOINT batch_operation"
+)
+
+// Queryer is the subset of `*sql.DB` used by the author and book functions
+// in this package; it is also satisfied by `*sql.Tx` so that those functions
+// can be run inside a batch.
+type Queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// BatchOperation is a single operation in a batch; it must do all of its
+// reads and writes via `s`.
+type BatchOperation func(ctx context.Context, s Store) error
+
+// RunBatch runs `ops` in order inside a single transaction.
+//
+// Each operation runs under its own savepoint, so a failed operation does
+// not prevent later operations from running (and reporting their own
+// errors). If every operation succeeds the transaction is committed and
+// `nil` is returned. Otherwise the transaction is rolled back and a
+// `BatchError` with one entry per operation is returned.
+func RunBatch(ctx context.Context, pool *sql.DB, ops []BatchOperation) error {
+	defer observeQuery(ctx, "run_batch", time.Now())
+
+	tx, err := pool.BeginTx(ctx, nil)
+	if err != nil {
+		return translateError(err)
+	}
+	// NOTE: This is a no-op if the transaction has been committed.
+	defer tx.Rollback()
+
+	errs := make([]error, len(ops))
+	failed := false
+	for i, op := range ops {
+		_, err = tx.ExecContext(ctx, batchSavepoint)
+		if err != nil {
+			return translateError(err)
+		}
+
+		errs[i] = op(ctx, &SQLStore{q: tx})
+		endSavepoint := batchReleaseSavepoint
+		if errs[i] != nil {
+			failed = true
+			endSavepoint = batchRollbackSavepoint
+		}
+
+		_, err = tx.ExecContext(ctx, endSavepoint)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+
+	if failed {
+		return &BatchError{Errors: errs}
+	}
+
+	return translateError(tx.Commit())
+}
+
+// BatchError is returned by `RunBatch()` when at least one operation failed.
+type BatchError struct {
+	// Errors has one entry per operation; it is `nil` for operations that
+	// succeeded (before the batch was rolled back).
+	Errors []error
+}
+
+// Error satisfies the `error` interface.
+func (be *BatchError) Error() string {
+	for _, err := range be.Errors {
+		if err != nil {
+			return "batch failed: " + err.Error()
+		}
+	}
+	return "batch failed"
+}
+
+// Unwrap allows `errors.Is()` to match the first failed operation.
+func (be *BatchError) Unwrap() error {
+	for _, err := range be.Errors {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
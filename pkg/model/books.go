@@ -18,6 +18,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -30,7 +32,7 @@ SELECT
   $1, $2, $3, $4
 WHERE
   EXISTS (
-    SELECT 1 FROM authors AS a WHERE a.id = $2 FOR UPDATE
+    SELECT 1 FROM authors AS a WHERE a.id = $2 AND a.deleted_at IS NULL FOR UPDATE
   )
 `
 	updateBook = `
@@ -39,46 +41,253 @@ UPDATE
 SET
   author_id = $2,
   title = $3,
-  publish_date = $4
+  publish_date = $4,
+  version = version + 1
 WHERE
-  id = $1
+  id = $1 AND
+  deleted_at IS NULL AND
+  ($5::BIGINT = 0 OR version = $5) AND
+  EXISTS (
+    SELECT 1 FROM authors AS a WHERE a.id = $2 AND a.deleted_at IS NULL FOR UPDATE
+  )
+RETURNING
+  version
+`
+	patchBook = `
+UPDATE
+  books
+SET
+  author_id = COALESCE($2, author_id),
+  title = COALESCE($3, title),
+  publish_date = COALESCE($4, publish_date),
+  version = version + 1
+WHERE
+  id = $1 AND
+  deleted_at IS NULL AND
+  ($5::BIGINT = 0 OR version = $5) AND
+  EXISTS (
+    SELECT 1 FROM authors AS a WHERE a.id = COALESCE($2, books.author_id) AND a.deleted_at IS NULL FOR UPDATE
+  )
+RETURNING
+  version
 `
 	getBookByID = `
 SELECT
-  id, author_id, title, publish_date
+  id, author_id, title, publish_date, version
 FROM
   books
 WHERE
-  id = $1
+  id = $1 AND
+  deleted_at IS NULL
 `
-	getAllBooksByAuthor = `
+	getBooksByTitle = `
 SELECT
-  id, author_id, title, publish_date
+  b.id, b.author_id, b.title, b.publish_date, b.version
 FROM
-  books
+  books AS b
+INNER JOIN
+  authors AS a
+ON
+  b.author_id = a.id
 WHERE
-  author_id = $1
+  b.deleted_at IS NULL AND
+  ($2::UUID IS NULL OR b.author_id = $2) AND
+  ($3::TEXT = '' OR b.title ILIKE '%' || $3 || '%') AND
+  (
+    $4::TEXT = '' OR
+    a.first_name ILIKE $4 || '%' OR
+    a.last_name ILIKE $4 || '%' OR
+    (a.first_name || ' ' || a.last_name) ILIKE $4 || '%'
+  ) AND
+  ($5::TIMESTAMPTZ IS NULL OR b.publish_date >= $5) AND
+  ($6::TIMESTAMPTZ IS NULL OR b.publish_date < $6) AND
+  (
+    NOT $7::BOOLEAN OR
+    (b.title, b.id) > ($8::TEXT, $9::UUID)
+  )
+ORDER BY
+  b.title, b.id
+LIMIT
+  $1
+`
+	getBooksByTitleDesc = `
+SELECT
+  b.id, b.author_id, b.title, b.publish_date, b.version
+FROM
+  books AS b
+INNER JOIN
+  authors AS a
+ON
+  b.author_id = a.id
+WHERE
+  b.deleted_at IS NULL AND
+  ($2::UUID IS NULL OR b.author_id = $2) AND
+  ($3::TEXT = '' OR b.title ILIKE '%' || $3 || '%') AND
+  (
+    $4::TEXT = '' OR
+    a.first_name ILIKE $4 || '%' OR
+    a.last_name ILIKE $4 || '%' OR
+    (a.first_name || ' ' || a.last_name) ILIKE $4 || '%'
+  ) AND
+  ($5::TIMESTAMPTZ IS NULL OR b.publish_date >= $5) AND
+  ($6::TIMESTAMPTZ IS NULL OR b.publish_date < $6) AND
+  (
+    NOT $7::BOOLEAN OR
+    (b.title, b.id) < ($8::TEXT, $9::UUID)
+  )
+ORDER BY
+  b.title DESC, b.id DESC
+LIMIT
+  $1
+`
+	getBooksByPublishDate = `
+SELECT
+  b.id, b.author_id, b.title, b.publish_date, b.version
+FROM
+  books AS b
+INNER JOIN
+  authors AS a
+ON
+  b.author_id = a.id
+WHERE
+  b.deleted_at IS NULL AND
+  ($2::UUID IS NULL OR b.author_id = $2) AND
+  ($3::TEXT = '' OR b.title ILIKE '%' || $3 || '%') AND
+  (
+    $4::TEXT = '' OR
+    a.first_name ILIKE $4 || '%' OR
+    a.last_name ILIKE $4 || '%' OR
+    (a.first_name || ' ' || a.last_name) ILIKE $4 || '%'
+  ) AND
+  ($5::TIMESTAMPTZ IS NULL OR b.publish_date >= $5) AND
+  ($6::TIMESTAMPTZ IS NULL OR b.publish_date < $6) AND
+  (
+    NOT $7::BOOLEAN OR
+    (b.publish_date, b.id) > ($8::TIMESTAMPTZ, $9::UUID)
+  )
+ORDER BY
+  b.publish_date, b.id
+LIMIT
+  $1
+`
+	getBooksByPublishDateDesc = `
+SELECT
+  b.id, b.author_id, b.title, b.publish_date, b.version
+FROM
+  books AS b
+INNER JOIN
+  authors AS a
+ON
+  b.author_id = a.id
+WHERE
+  b.deleted_at IS NULL AND
+  ($2::UUID IS NULL OR b.author_id = $2) AND
+  ($3::TEXT = '' OR b.title ILIKE '%' || $3 || '%') AND
+  (
+    $4::TEXT = '' OR
+    a.first_name ILIKE $4 || '%' OR
+    a.last_name ILIKE $4 || '%' OR
+    (a.first_name || ' ' || a.last_name) ILIKE $4 || '%'
+  ) AND
+  ($5::TIMESTAMPTZ IS NULL OR b.publish_date >= $5) AND
+  ($6::TIMESTAMPTZ IS NULL OR b.publish_date < $6) AND
+  (
+    NOT $7::BOOLEAN OR
+    (b.publish_date, b.id) < ($8::TIMESTAMPTZ, $9::UUID)
+  )
+ORDER BY
+  b.publish_date DESC, b.id DESC
+LIMIT
+  $1
 `
 	deleteBookByID = `
+UPDATE
+  books
+SET
+  deleted_at = NOW(),
+  version = version + 1
+WHERE
+  id = $1 AND
+  deleted_at IS NULL AND
+  ($2::BIGINT = 0 OR version = $2)
+`
+	restoreBookByID = `
+UPDATE
+  books AS b
+SET
+  deleted_at = NULL,
+  version = b.version + 1
+WHERE
+  b.id = $1 AND
+  b.deleted_at IS NOT NULL AND
+  EXISTS (
+    SELECT 1 FROM authors AS a WHERE a.id = b.author_id AND a.deleted_at IS NULL FOR UPDATE
+  )
+RETURNING
+  b.version
+`
+	getDeletedBooks = `
+SELECT
+  id, author_id, title, publish_date, version, deleted_at
+FROM
+  books
+WHERE
+  deleted_at IS NOT NULL
+ORDER BY
+  deleted_at DESC, id
+`
+	getDeletedBookByID = `
+SELECT
+  id, author_id, title, publish_date, version, deleted_at
+FROM
+  books
+WHERE
+  id = $1 AND
+  deleted_at IS NOT NULL
+`
+	purgeDeletedBooks = `
 DELETE FROM
   books
 WHERE
-  id = $1
+  deleted_at < $1
 `
 )
 
-// InsertBook inserts a book into the database.
-func InsertBook(ctx context.Context, pool *sql.DB, b Book) (uuid.UUID, error) {
-	id, err := uuid.NewRandom()
-	if err != nil {
-		return uuid.Nil, err
+// InsertBook inserts a book into the database. If `b.ID` is set it is
+// used as the ID, otherwise a random ID is generated.
+func InsertBook(ctx context.Context, pool Queryer, b Book) (uuid.UUID, error) {
+	defer observeQuery(ctx, "insert_book", time.Now())
+
+	var err error
+	id := b.ID
+	if id == uuid.Nil {
+		id, err = uuid.NewRandom()
+		if err != nil {
+			return uuid.Nil, err
+		}
 	}
 
 	// NOTE: Instead of doing two round trips, the `insertBook` makes sure
 	//       the author ID exists via a subquery. This is effectively the
 	//       same cost as using a foreign key, but does not **require** the
 	//       use of a foreign key.
-	_, err = pool.ExecContext(ctx, insertBook, id, b.AuthorID, b.Title, b.PublishDate)
+	err = audited(ctx, pool, func(tx Queryer) error {
+		result, err := tx.ExecContext(ctx, insertBook, id, b.AuthorID, b.Title, b.PublishDate)
+		if err != nil {
+			return translateError(err)
+		}
+
+		insertCount, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if insertCount == 0 {
+			return fmt.Errorf("could not insert book, author %w", ErrInvalidReference)
+		}
+
+		return nil
+	})
 	if err != nil {
 		return uuid.Nil, err
 	}
@@ -86,52 +295,110 @@ func InsertBook(ctx context.Context, pool *sql.DB, b Book) (uuid.UUID, error) {
 	return id, nil
 }
 
-// UpdateBook updates a book from the database directly by ID.
-func UpdateBook(ctx context.Context, pool *sql.DB, b Book) error {
-	result, err := pool.ExecContext(ctx, updateBook, b.ID, b.AuthorID, b.Title, b.PublishDate)
-	if err != nil {
-		return err
-	}
+// UpdateBook updates a book from the database directly by ID and returns
+// the new version. If `b.Version` is non-zero, the update only succeeds if
+// the stored version matches.
+func UpdateBook(ctx context.Context, pool Queryer, b Book) (int64, error) {
+	defer observeQuery(ctx, "update_book", time.Now())
 
-	updateCount, err := result.RowsAffected()
+	var version int64
+	err := audited(ctx, pool, func(tx Queryer) error {
+		row := tx.QueryRowContext(ctx, updateBook, b.ID, b.AuthorID, b.Title, b.PublishDate, b.Version)
+		err := row.Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return updateBookFailure(ctx, tx, b.ID, b.Version)
+		}
+		return translateError(err)
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	if updateCount == 0 {
-		return errors.New("could not update book, does not exist")
+	return version, nil
+}
+
+// PatchBook partially updates a book from the database directly by ID and
+// returns the new version. Only the non-`nil` fields in `p` are updated. If
+// `version` is non-zero, the update only succeeds if the stored version
+// matches.
+func PatchBook(ctx context.Context, pool Queryer, id uuid.UUID, p BookPatch, version int64) (int64, error) {
+	defer observeQuery(ctx, "patch_book", time.Now())
+
+	var newVersion int64
+	err := audited(ctx, pool, func(tx Queryer) error {
+		row := tx.QueryRowContext(ctx, patchBook, id, p.AuthorID, p.Title, p.PublishDate, version)
+		err := row.Scan(&newVersion)
+		if errors.Is(err, sql.ErrNoRows) {
+			return updateBookFailure(ctx, tx, id, version)
+		}
+		return translateError(err)
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return newVersion, nil
 }
 
 // GetBookByID gets a book from the database by ID.
-func GetBookByID(ctx context.Context, pool *sql.DB, id uuid.UUID) (*Book, error) {
+func GetBookByID(ctx context.Context, pool Queryer, id uuid.UUID) (*Book, error) {
+	defer observeQuery(ctx, "get_book_by_id", time.Now())
+
 	row := pool.QueryRowContext(ctx, getBookByID, id)
 
 	b := Book{}
-	err := row.Scan(&b.ID, &b.AuthorID, &b.Title, &b.PublishDate)
+	err := row.Scan(&b.ID, &b.AuthorID, &b.Title, &b.PublishDate, &b.Version)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	return &b, nil
 }
 
-// GetAllBooksByAuthor gets (all) books by an author from the database.
+// GetBooks gets one page of books from the database.
 //
-// This function does not use paging (but it would in a real application).
-func GetAllBooksByAuthor(ctx context.Context, pool *sql.DB, authorID uuid.UUID) ([]Book, error) {
-	rows, err := pool.QueryContext(ctx, getAllBooksByAuthor, authorID)
+// Books are filtered and ordered according to `q`; every ordering ends with
+// the ID so it is stable and `after` can be used as a keyset cursor. If
+// `after` is `nil`, the first page is returned. At most `limit` books are
+// returned.
+func GetBooks(ctx context.Context, pool Queryer, q BookQuery, limit int, after *BookCursor) ([]Book, error) {
+	defer observeQuery(ctx, "get_books", time.Now())
+
+	cursor := BookCursor{}
+	if after != nil {
+		cursor = *after
+	}
+	args := []interface{}{
+		limit,
+		q.AuthorID,
+		escapeLike(q.TitleContains),
+		escapeLike(q.AuthorNamePrefix),
+		q.PublishedAfter,
+		q.PublishedBefore,
+		after != nil,
+	}
+
+	var rows *sql.Rows
+	var err error
+	switch {
+	case q.OrderBy == BookOrderPublishDate && q.Descending:
+		rows, err = pool.QueryContext(ctx, getBooksByPublishDateDesc, append(args, cursor.PublishDate, cursor.ID)...)
+	case q.OrderBy == BookOrderPublishDate:
+		rows, err = pool.QueryContext(ctx, getBooksByPublishDate, append(args, cursor.PublishDate, cursor.ID)...)
+	case q.Descending:
+		rows, err = pool.QueryContext(ctx, getBooksByTitleDesc, append(args, cursor.Title, cursor.ID)...)
+	default:
+		rows, err = pool.QueryContext(ctx, getBooksByTitle, append(args, cursor.Title, cursor.ID)...)
+	}
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	defer rows.Close()
 
 	books := []Book{}
 	for rows.Next() {
 		b := Book{}
-		err = rows.Scan(&b.ID, &b.AuthorID, &b.Title, &b.PublishDate)
+		err = rows.Scan(&b.ID, &b.AuthorID, &b.Title, &b.PublishDate, &b.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -146,21 +413,143 @@ func GetAllBooksByAuthor(ctx context.Context, pool *sql.DB, authorID uuid.UUID)
 	return books, nil
 }
 
-// DeleteBookByID deletes a book from the database by ID.
-func DeleteBookByID(ctx context.Context, pool *sql.DB, id uuid.UUID) error {
-	result, err := pool.ExecContext(ctx, deleteBookByID, id)
+// DeleteBookByID deletes a book from the database by ID. This is a soft
+// delete: the book is moved to the trash (`deleted_at` is set) and can be
+// restored until it is purged. If `version` is non-zero, the delete only
+// succeeds if the stored version matches.
+func DeleteBookByID(ctx context.Context, pool Queryer, id uuid.UUID, version int64) error {
+	defer observeQuery(ctx, "delete_book_by_id", time.Now())
+
+	return audited(ctx, pool, func(tx Queryer) error {
+		result, err := tx.ExecContext(ctx, deleteBookByID, id, version)
+		if err != nil {
+			return err
+		}
+
+		deleteCount, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if deleteCount == 0 {
+			return deleteBookFailure(ctx, tx, id)
+		}
+
+		return nil
+	})
+}
+
+// RestoreBookByID restores a deleted book from the trash and returns the new
+// version. The book's author must not be deleted.
+func RestoreBookByID(ctx context.Context, pool Queryer, id uuid.UUID) (int64, error) {
+	defer observeQuery(ctx, "restore_book_by_id", time.Now())
+
+	var version int64
+	err := audited(ctx, pool, func(tx Queryer) error {
+		row := tx.QueryRowContext(ctx, restoreBookByID, id)
+		err := row.Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return restoreBookFailure(ctx, tx, id)
+		}
+		if err != nil {
+			return fmt.Errorf("could not restore book, %w", translateError(err))
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	deleteCount, err := result.RowsAffected()
+	return version, nil
+}
+
+// GetDeletedBooks gets all books in the trash, most recently deleted first.
+func GetDeletedBooks(ctx context.Context, pool Queryer) ([]Book, error) {
+	defer observeQuery(ctx, "get_deleted_books", time.Now())
+
+	rows, err := pool.QueryContext(ctx, getDeletedBooks)
 	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	books := []Book{}
+	for rows.Next() {
+		b := Book{}
+		err = rows.Scan(&b.ID, &b.AuthorID, &b.Title, &b.PublishDate, &b.Version, &b.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, b)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// PurgeDeletedBooks permanently deletes books that were deleted before
+// `deletedBefore` and returns the number purged.
+func PurgeDeletedBooks(ctx context.Context, pool Queryer, deletedBefore time.Time) (int64, error) {
+	defer observeQuery(ctx, "purge_deleted_books", time.Now())
+
+	var purged int64
+	err := audited(ctx, pool, func(tx Queryer) error {
+		result, err := tx.ExecContext(ctx, purgeDeletedBooks, deletedBefore)
+		if err != nil {
+			return translateError(err)
+		}
+
+		purged, err = result.RowsAffected()
 		return err
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	if deleteCount == 0 {
-		return errors.New("could not delete book, does not exist")
+	return purged, nil
+}
+
+// updateBookFailure determines why `updateBook` did not update a row: either
+// the book does not exist, its version did not match or the (new) author
+// does not exist.
+func updateBookFailure(ctx context.Context, pool Queryer, id uuid.UUID, version int64) error {
+	b, err := GetBookByID(ctx, pool, id)
+	if err != nil {
+		return fmt.Errorf("could not update book, %w", err)
+	}
+
+	if version != 0 && b.Version != version {
+		return fmt.Errorf("could not update book, %w", ErrVersionMismatch)
+	}
+
+	return fmt.Errorf("could not update book, author %w", ErrInvalidReference)
+}
+
+// deleteBookFailure determines why `deleteBookByID` did not delete a row:
+// either the book does not exist or its version did not match.
+func deleteBookFailure(ctx context.Context, pool Queryer, id uuid.UUID) error {
+	_, err := GetBookByID(ctx, pool, id)
+	if err != nil {
+		return fmt.Errorf("could not delete book, %w", err)
+	}
+
+	return fmt.Errorf("could not delete book, %w", ErrVersionMismatch)
+}
+
+// restoreBookFailure determines why `restoreBookByID` did not restore a row:
+// either the book is not in the trash or its author is deleted.
+func restoreBookFailure(ctx context.Context, pool Queryer, id uuid.UUID) error {
+	row := pool.QueryRowContext(ctx, getDeletedBookByID, id)
+
+	b := Book{}
+	err := row.Scan(&b.ID, &b.AuthorID, &b.Title, &b.PublishDate, &b.Version, &b.DeletedAt)
+	if err != nil {
+		return fmt.Errorf("could not restore book, %w", translateError(err))
 	}
 
-	return nil
+	return fmt.Errorf("could not restore book, author %w", ErrInvalidReference)
 }
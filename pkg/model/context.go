@@ -17,6 +17,9 @@ package model
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type poolKey struct{}
@@ -32,3 +35,57 @@ func GetPool(ctx context.Context) *sql.DB {
 	pool, _ := raw.(*sql.DB)
 	return pool // Will be `nil` if type assertion fails
 }
+
+// Actor identifies who is making changes, for the audit log.
+type Actor struct {
+	// Name is the name recorded in the audit log.
+	Name string
+	// APITokenID is the API token used to authenticate, if any.
+	APITokenID uuid.UUID
+}
+
+type actorKey struct{}
+
+// WithActor adds the actor making changes to a context.
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// GetActor gets the actor making changes from a context.
+func GetActor(ctx context.Context) Actor {
+	raw := ctx.Value(actorKey{})
+	a, _ := raw.(Actor)
+	return a // Will be the zero value if type assertion fails
+}
+
+// QueryObserver receives the duration of every query made by this package,
+// e.g. to export them as metrics.
+type QueryObserver interface {
+	ObserveQuery(name string, duration time.Duration)
+}
+
+type queryObserverKey struct{}
+
+// WithQueryObserver adds a query observer to a context.
+func WithQueryObserver(ctx context.Context, qo QueryObserver) context.Context {
+	return context.WithValue(ctx, queryObserverKey{}, qo)
+}
+
+// GetQueryObserver gets a query observer from a context.
+func GetQueryObserver(ctx context.Context) QueryObserver {
+	raw := ctx.Value(queryObserverKey{})
+	qo, _ := raw.(QueryObserver)
+	return qo // Will be `nil` if type assertion fails
+}
+
+// observeQuery reports the time since `start` to the query observer in the
+// context, if there is one. It is intended to be used as
+// `defer observeQuery(ctx, name, time.Now())`.
+func observeQuery(ctx context.Context, name string, start time.Time) {
+	qo := GetQueryObserver(ctx)
+	if qo == nil {
+		return
+	}
+
+	qo.ObserveQuery(name, time.Since(start))
+}
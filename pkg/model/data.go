@@ -25,6 +25,12 @@ type Author struct {
 	ID        uuid.UUID `db:"id"`
 	FirstName string    `db:"first_name"`
 	LastName  string    `db:"last_name"`
+	// Version is incremented on every update. When updating or deleting, a
+	// non-zero version is a precondition: the write only succeeds if the
+	// stored version matches.
+	Version int64 `db:"version"`
+	// DeletedAt is set when the author is in the trash.
+	DeletedAt *time.Time `db:"deleted_at"`
 
 	// BookCount is not actually in the `authors` table, but can be supplied
 	// by doing a `COUNT(*)` in the `books` table.
@@ -37,4 +43,90 @@ type Book struct {
 	AuthorID    uuid.UUID  `db:"author_id"`
 	Title       string     `db:"title"`
 	PublishDate *time.Time `db:"publish_date"`
+	// Version is incremented on every update. When updating or deleting, a
+	// non-zero version is a precondition: the write only succeeds if the
+	// stored version matches.
+	Version int64 `db:"version"`
+	// DeletedAt is set when the book is in the trash.
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+// AuthorPatch is a partial update to an author; `nil` fields are left
+// unchanged.
+type AuthorPatch struct {
+	FirstName *string
+	LastName  *string
+}
+
+// BookPatch is a partial update to a book; `nil` fields are left unchanged.
+type BookPatch struct {
+	AuthorID    *uuid.UUID
+	Title       *string
+	PublishDate *time.Time
+}
+
+// APIToken represents a row in the `api_tokens` table.
+type APIToken struct {
+	ID        uuid.UUID  `db:"id"`
+	Name      string     `db:"name"`
+	TokenHash []byte     `db:"token_hash"`
+	Role      string     `db:"role"`
+	CreatedAt time.Time  `db:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+// IdempotencyKey represents a row in the `idempotency_keys` table.
+type IdempotencyKey struct {
+	APITokenID  uuid.UUID `db:"api_token_id"`
+	Key         string    `db:"key"`
+	RequestHash []byte    `db:"request_hash"`
+	// StatusCode and ResponseBody are `nil` while the original request is
+	// still in progress.
+	StatusCode   *int      `db:"status_code"`
+	ResponseBody []byte    `db:"response_body"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// AuditEvent represents a row in the `audit_log` table.
+type AuditEvent struct {
+	ID         int64     `db:"id"`
+	OccurredAt time.Time `db:"occurred_at"`
+	Actor      string    `db:"actor"`
+	// APITokenID is `nil` for changes not made via the API, e.g. purges.
+	APITokenID *uuid.UUID `db:"api_token_id"`
+	Action     string     `db:"action"`
+	EntityType string     `db:"entity_type"`
+	EntityID   uuid.UUID  `db:"entity_id"`
+	// Before and After are JSON snapshots of the row; `Before` is `nil` for
+	// a create and `After` is `nil` for a purge.
+	Before []byte `db:"before"`
+	After  []byte `db:"after"`
+}
+
+// Webhook represents a row in the `webhooks` table.
+type Webhook struct {
+	ID  uuid.UUID `db:"id"`
+	URL string    `db:"url"`
+	// Secret is the key used to sign deliveries (with HMAC-SHA256).
+	Secret    string    `db:"secret"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// WebhookDelivery represents a row in the `webhook_deliveries` table.
+type WebhookDelivery struct {
+	ID            int64      `db:"id"`
+	WebhookID     uuid.UUID  `db:"webhook_id"`
+	AuditEventID  int64      `db:"audit_event_id"`
+	Attempts      int        `db:"attempts"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	LastError     *string    `db:"last_error"`
+	DeliveredAt   *time.Time `db:"delivered_at"`
+	// DeadAt is set once the delivery has failed too many times; it will
+	// not be retried.
+	DeadAt *time.Time `db:"dead_at"`
+
+	// Webhook and Event are not actually in the `webhook_deliveries` table,
+	// but can be supplied by joining the `webhooks` and `audit_log` tables.
+	Webhook Webhook    `db:"-"`
+	Event   AuditEvent `db:"-"`
 }
@@ -0,0 +1,78 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgconn"
+)
+
+var (
+	// ErrNotFound is returned when a row being read, updated or deleted
+	// does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a write would violate a unique constraint.
+	ErrConflict = errors.New("conflicts with an existing record")
+	// ErrHasBooks is returned when an author cannot be deleted because
+	// there are still books that reference it.
+	ErrHasBooks = errors.New("author still has books")
+	// ErrInvalidReference is returned when a write refers to a row (e.g. a
+	// book's author) that does not exist.
+	ErrInvalidReference = errors.New("referenced record does not exist")
+	// ErrVersionMismatch is returned when a write is conditioned on a row
+	// version, but the row has since been modified.
+	ErrVersionMismatch = errors.New("has been modified")
+	// ErrTimeout is returned when a query is canceled because the request
+	// deadline or the PostgreSQL `statement_timeout` was exceeded.
+	ErrTimeout = errors.New("query timed out")
+)
+
+const (
+	// pgUniqueViolation is the PostgreSQL error code for `unique_violation`.
+	pgUniqueViolation = "23505"
+	// pgQueryCanceled is the PostgreSQL error code for `query_canceled`, which
+	// is used when `statement_timeout` is exceeded.
+	pgQueryCanceled = "57014"
+)
+
+// translateError converts driver-specific errors into the sentinel errors
+// in this package; other errors are returned unchanged.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return ErrConflict
+		case pgQueryCanceled:
+			return ErrTimeout
+		}
+	}
+
+	return err
+}
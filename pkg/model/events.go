@@ -0,0 +1,75 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v4/stdlib"
+)
+
+const (
+	// AuditLogChannel is the channel notified (via a trigger) whenever new
+	// audit log rows are committed.
+	AuditLogChannel = "audit_log_events"
+
+	listenAuditLog   = "LISTEN " + AuditLogChannel
+	unlistenAuditLog = "UNLISTEN " + AuditLogChannel
+)
+
+// ListenForAuditEvents calls `notify` each time new audit events are
+// committed, by any server using the same database, until `ctx` is done or
+// the connection fails. Notifications carry no payload; use
+// an `AuditFeed` to read the new events.
+//
+// This holds one connection from `pool` for as long as it runs.
+func ListenForAuditEvents(ctx context.Context, pool *sql.DB, notify func()) error {
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return translateError(err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		sc, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("database connection does not support LISTEN")
+		}
+		pc := sc.Conn()
+
+		_, err := pc.Exec(ctx, listenAuditLog)
+		if err != nil {
+			return translateError(err)
+		}
+		// NOTE: The connection goes back to the pool when this returns, so
+		//       stop listening unless it has already been closed (which is
+		//       what happens when `ctx` is canceled while waiting).
+		defer func() {
+			if !pc.IsClosed() {
+				_, _ = pc.Exec(context.Background(), unlistenAuditLog)
+			}
+		}()
+
+		for {
+			_, err = pc.WaitForNotification(ctx)
+			if err != nil {
+				return translateError(err)
+			}
+			notify()
+		}
+	})
+}
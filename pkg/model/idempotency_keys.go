@@ -0,0 +1,145 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	reserveIdempotencyKey = `
+INSERT INTO
+  idempotency_keys (api_token_id, key, request_hash, created_at)
+VALUES
+  ($1, $2, $3, NOW())
+ON CONFLICT (api_token_id, key) DO UPDATE
+SET
+  request_hash = EXCLUDED.request_hash,
+  status_code = NULL,
+  response_body = NULL,
+  created_at = EXCLUDED.created_at
+WHERE
+  idempotency_keys.created_at < $4
+`
+	getIdempotencyKey = `
+SELECT
+  api_token_id,
+  key,
+  request_hash,
+  status_code,
+  response_body,
+  created_at
+FROM
+  idempotency_keys
+WHERE
+  api_token_id = $1 AND
+  key = $2
+`
+	completeIdempotencyKey = `
+UPDATE
+  idempotency_keys
+SET
+  status_code = $3,
+  response_body = $4
+WHERE
+  api_token_id = $1 AND
+  key = $2
+`
+	releaseIdempotencyKey = `
+DELETE FROM
+  idempotency_keys
+WHERE
+  api_token_id = $1 AND
+  key = $2 AND
+  status_code IS NULL
+`
+	deleteExpiredIdempotencyKeys = `
+DELETE FROM
+  idempotency_keys
+WHERE
+  created_at < $1
+`
+)
+
+// ReserveIdempotencyKey records that a request with an idempotency key is
+// in progress. It returns `false` if the key has already been used (by the
+// same API token) since `expiredBefore`; keys used before then are expired
+// and can be reused.
+func ReserveIdempotencyKey(ctx context.Context, pool *sql.DB, ik IdempotencyKey, expiredBefore time.Time) (bool, error) {
+	defer observeQuery(ctx, "reserve_idempotency_key", time.Now())
+
+	result, err := pool.ExecContext(ctx, reserveIdempotencyKey, ik.APITokenID, ik.Key, ik.RequestHash, expiredBefore)
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	reserveCount, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return reserveCount == 1, nil
+}
+
+// GetIdempotencyKey gets an idempotency key (used by an API token) from the
+// database.
+func GetIdempotencyKey(ctx context.Context, pool *sql.DB, apiTokenID uuid.UUID, key string) (*IdempotencyKey, error) {
+	defer observeQuery(ctx, "get_idempotency_key", time.Now())
+
+	row := pool.QueryRowContext(ctx, getIdempotencyKey, apiTokenID, key)
+
+	ik := IdempotencyKey{}
+	err := row.Scan(&ik.APITokenID, &ik.Key, &ik.RequestHash, &ik.StatusCode, &ik.ResponseBody, &ik.CreatedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &ik, nil
+}
+
+// CompleteIdempotencyKey stores the response for a reserved idempotency key
+// so that it can be replayed.
+func CompleteIdempotencyKey(ctx context.Context, pool *sql.DB, ik IdempotencyKey) error {
+	defer observeQuery(ctx, "complete_idempotency_key", time.Now())
+
+	_, err := pool.ExecContext(ctx, completeIdempotencyKey, ik.APITokenID, ik.Key, ik.StatusCode, ik.ResponseBody)
+	return translateError(err)
+}
+
+// ReleaseIdempotencyKey removes a reserved (but not completed) idempotency
+// key, e.g. because the request failed in a way that is safe to retry.
+func ReleaseIdempotencyKey(ctx context.Context, pool *sql.DB, apiTokenID uuid.UUID, key string) error {
+	defer observeQuery(ctx, "release_idempotency_key", time.Now())
+
+	_, err := pool.ExecContext(ctx, releaseIdempotencyKey, apiTokenID, key)
+	return translateError(err)
+}
+
+// DeleteExpiredIdempotencyKeys deletes all idempotency keys used before
+// `expiredBefore` and returns the number deleted.
+func DeleteExpiredIdempotencyKeys(ctx context.Context, pool *sql.DB, expiredBefore time.Time) (int64, error) {
+	defer observeQuery(ctx, "delete_expired_idempotency_keys", time.Now())
+
+	result, err := pool.ExecContext(ctx, deleteExpiredIdempotencyKeys, expiredBefore)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return result.RowsAffected()
+}
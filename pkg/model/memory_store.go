@@ -0,0 +1,784 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// NOTE: Ensure that
+//       * `MemoryStore` satisfies `Store`.
+var (
+	_ Store = (*MemoryStore)(nil)
+)
+
+// MemoryStore is a `Store` that keeps authors and books in memory, e.g. for
+// local development without PostgreSQL. It is safe for concurrent use.
+//
+// Changes are not recorded in the audit log (there is none) and the full-text
+// search only approximates the PostgreSQL one: every term in the query must
+// be a prefix of a word in the title or name (or, for `-term`, must not be).
+type MemoryStore struct {
+	// mu is `nil` for the copy of a store used inside a batch; the batch
+	// holds the lock of the original store throughout.
+	mu      *sync.RWMutex
+	authors map[uuid.UUID]Author
+	books   map[uuid.UUID]Book
+}
+
+// NewMemoryStore returns an empty in-memory `Store`.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mu:      &sync.RWMutex{},
+		authors: map[uuid.UUID]Author{},
+		books:   map[uuid.UUID]Book{},
+	}
+}
+
+// InsertAuthor satisfies the `Store` interface.
+func (ms *MemoryStore) InsertAuthor(_ context.Context, a Author) (uuid.UUID, error) {
+	defer ms.lock()()
+
+	id, err := newID(a.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if _, ok := ms.authors[id]; ok {
+		return uuid.Nil, ErrConflict
+	}
+	if ms.authorNameTaken(a.FirstName, a.LastName, id) {
+		return uuid.Nil, ErrConflict
+	}
+
+	ms.authors[id] = Author{ID: id, FirstName: a.FirstName, LastName: a.LastName, Version: 1}
+	return id, nil
+}
+
+// UpdateAuthor satisfies the `Store` interface.
+func (ms *MemoryStore) UpdateAuthor(ctx context.Context, a Author) (int64, error) {
+	return ms.PatchAuthor(ctx, a.ID, AuthorPatch{FirstName: &a.FirstName, LastName: &a.LastName}, a.Version)
+}
+
+// PatchAuthor satisfies the `Store` interface.
+func (ms *MemoryStore) PatchAuthor(_ context.Context, id uuid.UUID, p AuthorPatch, version int64) (int64, error) {
+	defer ms.lock()()
+
+	a, ok := ms.liveAuthor(id)
+	if !ok {
+		return 0, fmt.Errorf("could not update author, %w", ErrNotFound)
+	}
+	if version != 0 && a.Version != version {
+		return 0, fmt.Errorf("could not update author, %w", ErrVersionMismatch)
+	}
+
+	if p.FirstName != nil {
+		a.FirstName = *p.FirstName
+	}
+	if p.LastName != nil {
+		a.LastName = *p.LastName
+	}
+	if ms.authorNameTaken(a.FirstName, a.LastName, id) {
+		return 0, ErrConflict
+	}
+
+	a.Version++
+	ms.authors[id] = a
+	return a.Version, nil
+}
+
+// GetAuthorByID satisfies the `Store` interface.
+func (ms *MemoryStore) GetAuthorByID(_ context.Context, id uuid.UUID) (*Author, error) {
+	defer ms.rlock()()
+
+	a, ok := ms.liveAuthor(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	a.BookCount = ms.bookCount(id)
+	return &a, nil
+}
+
+// GetAuthorByName satisfies the `Store` interface.
+func (ms *MemoryStore) GetAuthorByName(_ context.Context, firstName, lastName string) (*Author, error) {
+	defer ms.rlock()()
+
+	for _, a := range ms.authors {
+		if a.DeletedAt == nil && a.FirstName == firstName && a.LastName == lastName {
+			a.BookCount = ms.bookCount(a.ID)
+			return &a, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// GetAuthors satisfies the `Store` interface.
+func (ms *MemoryStore) GetAuthors(_ context.Context, q AuthorQuery, limit int, after *AuthorCursor) ([]Author, error) {
+	defer ms.rlock()()
+
+	less := func(a, b AuthorCursor) bool {
+		c := compareAuthors(a, b, q.OrderBy)
+		if q.Descending {
+			return c > 0
+		}
+		return c < 0
+	}
+
+	authors := []Author{}
+	for _, a := range ms.authors {
+		if a.DeletedAt != nil || !nameHasPrefix(a.FirstName, a.LastName, q.NamePrefix) {
+			continue
+		}
+		a.BookCount = ms.bookCount(a.ID)
+		if after != nil && !less(*after, AuthorCursorFrom(a)) {
+			continue
+		}
+		authors = append(authors, a)
+	}
+
+	sort.Slice(authors, func(i, j int) bool {
+		return less(AuthorCursorFrom(authors[i]), AuthorCursorFrom(authors[j]))
+	})
+	if len(authors) > limit {
+		authors = authors[:limit]
+	}
+	return authors, nil
+}
+
+// DeleteAuthorByID satisfies the `Store` interface.
+func (ms *MemoryStore) DeleteAuthorByID(_ context.Context, id uuid.UUID, version int64) error {
+	defer ms.lock()()
+
+	a, err := ms.deletableAuthor(id, version)
+	if err != nil {
+		return err
+	}
+	if ms.bookCount(id) > 0 {
+		return fmt.Errorf("could not delete author, %w", ErrHasBooks)
+	}
+
+	ms.authors[id] = trashAuthor(a, time.Now())
+	return nil
+}
+
+// DeleteAuthorByIDCascade satisfies the `Store` interface.
+func (ms *MemoryStore) DeleteAuthorByIDCascade(_ context.Context, id uuid.UUID, version int64) (int64, error) {
+	defer ms.lock()()
+
+	a, err := ms.deletableAuthor(id, version)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	ms.authors[id] = trashAuthor(a, now)
+	var booksDeleted int64
+	for _, b := range ms.books {
+		if b.AuthorID == id && b.DeletedAt == nil {
+			ms.books[b.ID] = trashBook(b, now)
+			booksDeleted++
+		}
+	}
+
+	return booksDeleted, nil
+}
+
+// RestoreAuthorByID satisfies the `Store` interface.
+func (ms *MemoryStore) RestoreAuthorByID(_ context.Context, id uuid.UUID) (int64, error) {
+	defer ms.lock()()
+
+	a, ok := ms.authors[id]
+	if !ok || a.DeletedAt == nil {
+		return 0, fmt.Errorf("could not restore author, %w", ErrNotFound)
+	}
+	if ms.authorNameTaken(a.FirstName, a.LastName, id) {
+		return 0, fmt.Errorf("could not restore author, %w", ErrConflict)
+	}
+
+	a.DeletedAt = nil
+	a.Version++
+	ms.authors[id] = a
+	return a.Version, nil
+}
+
+// GetDeletedAuthors satisfies the `Store` interface.
+func (ms *MemoryStore) GetDeletedAuthors(_ context.Context) ([]Author, error) {
+	defer ms.rlock()()
+
+	authors := []Author{}
+	for _, a := range ms.authors {
+		if a.DeletedAt != nil {
+			authors = append(authors, a)
+		}
+	}
+
+	sort.Slice(authors, func(i, j int) bool {
+		return trashLess(authors[i].DeletedAt, authors[i].ID, authors[j].DeletedAt, authors[j].ID)
+	})
+	return authors, nil
+}
+
+// PurgeDeletedAuthors satisfies the `Store` interface.
+func (ms *MemoryStore) PurgeDeletedAuthors(_ context.Context, deletedBefore time.Time) (int64, error) {
+	defer ms.lock()()
+
+	hasBooks := map[uuid.UUID]bool{}
+	for _, b := range ms.books {
+		hasBooks[b.AuthorID] = true
+	}
+
+	var purged int64
+	for id, a := range ms.authors {
+		if a.DeletedAt != nil && a.DeletedAt.Before(deletedBefore) && !hasBooks[id] {
+			delete(ms.authors, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// InsertBook satisfies the `Store` interface.
+func (ms *MemoryStore) InsertBook(_ context.Context, b Book) (uuid.UUID, error) {
+	defer ms.lock()()
+
+	id, err := newID(b.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if _, ok := ms.liveAuthor(b.AuthorID); !ok {
+		return uuid.Nil, fmt.Errorf("could not insert book, author %w", ErrInvalidReference)
+	}
+	if _, ok := ms.books[id]; ok {
+		return uuid.Nil, ErrConflict
+	}
+	if ms.bookTitleTaken(b.AuthorID, b.Title, id) {
+		return uuid.Nil, ErrConflict
+	}
+
+	ms.books[id] = Book{ID: id, AuthorID: b.AuthorID, Title: b.Title, PublishDate: copyTime(b.PublishDate), Version: 1}
+	return id, nil
+}
+
+// UpdateBook satisfies the `Store` interface.
+func (ms *MemoryStore) UpdateBook(ctx context.Context, b Book) (int64, error) {
+	p := BookPatch{AuthorID: &b.AuthorID, Title: &b.Title, PublishDate: b.PublishDate}
+	return ms.PatchBook(ctx, b.ID, p, b.Version)
+}
+
+// PatchBook satisfies the `Store` interface.
+func (ms *MemoryStore) PatchBook(_ context.Context, id uuid.UUID, p BookPatch, version int64) (int64, error) {
+	defer ms.lock()()
+
+	b, ok := ms.liveBook(id)
+	if !ok {
+		return 0, fmt.Errorf("could not update book, %w", ErrNotFound)
+	}
+	if version != 0 && b.Version != version {
+		return 0, fmt.Errorf("could not update book, %w", ErrVersionMismatch)
+	}
+
+	if p.AuthorID != nil {
+		b.AuthorID = *p.AuthorID
+	}
+	if p.Title != nil {
+		b.Title = *p.Title
+	}
+	if p.PublishDate != nil {
+		b.PublishDate = copyTime(p.PublishDate)
+	}
+	if _, ok := ms.liveAuthor(b.AuthorID); !ok {
+		return 0, fmt.Errorf("could not update book, author %w", ErrInvalidReference)
+	}
+	if ms.bookTitleTaken(b.AuthorID, b.Title, id) {
+		return 0, ErrConflict
+	}
+
+	b.Version++
+	ms.books[id] = b
+	return b.Version, nil
+}
+
+// GetBookByID satisfies the `Store` interface.
+func (ms *MemoryStore) GetBookByID(_ context.Context, id uuid.UUID) (*Book, error) {
+	defer ms.rlock()()
+
+	b, ok := ms.liveBook(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &b, nil
+}
+
+// GetBooks satisfies the `Store` interface.
+func (ms *MemoryStore) GetBooks(_ context.Context, q BookQuery, limit int, after *BookCursor) ([]Book, error) {
+	defer ms.rlock()()
+
+	less := func(a, b BookCursor) bool {
+		c := compareBooks(a, b, q.OrderBy)
+		if q.Descending {
+			return c > 0
+		}
+		return c < 0
+	}
+
+	books := []Book{}
+	for _, b := range ms.books {
+		if b.DeletedAt != nil || !ms.bookMatches(b, q) {
+			continue
+		}
+		if after != nil && !less(*after, BookCursorFrom(b)) {
+			continue
+		}
+		books = append(books, b)
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		return less(BookCursorFrom(books[i]), BookCursorFrom(books[j]))
+	})
+	if len(books) > limit {
+		books = books[:limit]
+	}
+	return books, nil
+}
+
+// DeleteBookByID satisfies the `Store` interface.
+func (ms *MemoryStore) DeleteBookByID(_ context.Context, id uuid.UUID, version int64) error {
+	defer ms.lock()()
+
+	b, ok := ms.liveBook(id)
+	if !ok {
+		return fmt.Errorf("could not delete book, %w", ErrNotFound)
+	}
+	if version != 0 && b.Version != version {
+		return fmt.Errorf("could not delete book, %w", ErrVersionMismatch)
+	}
+
+	ms.books[id] = trashBook(b, time.Now())
+	return nil
+}
+
+// RestoreBookByID satisfies the `Store` interface.
+func (ms *MemoryStore) RestoreBookByID(_ context.Context, id uuid.UUID) (int64, error) {
+	defer ms.lock()()
+
+	b, ok := ms.books[id]
+	if !ok || b.DeletedAt == nil {
+		return 0, fmt.Errorf("could not restore book, %w", ErrNotFound)
+	}
+	if _, ok := ms.liveAuthor(b.AuthorID); !ok {
+		return 0, fmt.Errorf("could not restore book, author %w", ErrInvalidReference)
+	}
+	if ms.bookTitleTaken(b.AuthorID, b.Title, id) {
+		return 0, fmt.Errorf("could not restore book, %w", ErrConflict)
+	}
+
+	b.DeletedAt = nil
+	b.Version++
+	ms.books[id] = b
+	return b.Version, nil
+}
+
+// GetDeletedBooks satisfies the `Store` interface.
+func (ms *MemoryStore) GetDeletedBooks(_ context.Context) ([]Book, error) {
+	defer ms.rlock()()
+
+	books := []Book{}
+	for _, b := range ms.books {
+		if b.DeletedAt != nil {
+			books = append(books, b)
+		}
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		return trashLess(books[i].DeletedAt, books[i].ID, books[j].DeletedAt, books[j].ID)
+	})
+	return books, nil
+}
+
+// PurgeDeletedBooks satisfies the `Store` interface.
+func (ms *MemoryStore) PurgeDeletedBooks(_ context.Context, deletedBefore time.Time) (int64, error) {
+	defer ms.lock()()
+
+	var purged int64
+	for id, b := range ms.books {
+		if b.DeletedAt != nil && b.DeletedAt.Before(deletedBefore) {
+			delete(ms.books, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// Search satisfies the `Store` interface.
+func (ms *MemoryStore) Search(_ context.Context, query string, limit int) ([]SearchResult, error) {
+	defer ms.rlock()()
+
+	include, exclude := searchTerms(query)
+	if len(include) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	results := []SearchResult{}
+	for _, b := range ms.books {
+		if b.DeletedAt != nil {
+			continue
+		}
+		sr, ok := searchMatch(b.Title, include, exclude)
+		if ok {
+			sr.Kind, sr.ID = SearchKindBook, b.ID
+			results = append(results, sr)
+		}
+	}
+	for _, a := range ms.authors {
+		if a.DeletedAt != nil {
+			continue
+		}
+		sr, ok := searchMatch(a.FirstName+" "+a.LastName, include, exclude)
+		if ok {
+			sr.Kind, sr.ID = SearchKindAuthor, a.ID
+			results = append(results, sr)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return bytes.Compare(results[i].ID[:], results[j].ID[:]) < 0
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// RunBatch satisfies the `Store` interface. The store is locked for the
+// whole batch and operations run against a copy of it, which replaces the
+// original only if every operation succeeds. Batches cannot be nested.
+func (ms *MemoryStore) RunBatch(ctx context.Context, ops []BatchOperation) error {
+	if ms.mu == nil {
+		return errors.New("cannot run a batch inside a batch")
+	}
+	defer ms.lock()()
+
+	tx := ms.copy()
+	errs := make([]error, len(ops))
+	failed := false
+	for i, op := range ops {
+		savepoint := tx.copy()
+		errs[i] = op(ctx, tx)
+		if errs[i] != nil {
+			failed = true
+			tx.authors, tx.books = savepoint.authors, savepoint.books
+		}
+	}
+
+	if failed {
+		return &BatchError{Errors: errs}
+	}
+
+	ms.authors, ms.books = tx.authors, tx.books
+	return nil
+}
+
+// lock acquires the write lock and returns the function that releases it.
+func (ms *MemoryStore) lock() func() {
+	if ms.mu == nil {
+		return func() {}
+	}
+	ms.mu.Lock()
+	return ms.mu.Unlock
+}
+
+// rlock acquires the read lock and returns the function that releases it.
+func (ms *MemoryStore) rlock() func() {
+	if ms.mu == nil {
+		return func() {}
+	}
+	ms.mu.RLock()
+	return ms.mu.RUnlock
+}
+
+// copy returns an unlocked copy of the store. The `Author` and `Book` values
+// are never modified in place, so copying the maps is sufficient.
+func (ms *MemoryStore) copy() *MemoryStore {
+	c := &MemoryStore{
+		authors: make(map[uuid.UUID]Author, len(ms.authors)),
+		books:   make(map[uuid.UUID]Book, len(ms.books)),
+	}
+	for id, a := range ms.authors {
+		c.authors[id] = a
+	}
+	for id, b := range ms.books {
+		c.books[id] = b
+	}
+	return c
+}
+
+func (ms *MemoryStore) liveAuthor(id uuid.UUID) (Author, bool) {
+	a, ok := ms.authors[id]
+	return a, ok && a.DeletedAt == nil
+}
+
+func (ms *MemoryStore) liveBook(id uuid.UUID) (Book, bool) {
+	b, ok := ms.books[id]
+	return b, ok && b.DeletedAt == nil
+}
+
+// deletableAuthor checks that an author exists and, if `version` is non-zero,
+// that its version matches.
+func (ms *MemoryStore) deletableAuthor(id uuid.UUID, version int64) (Author, error) {
+	a, ok := ms.liveAuthor(id)
+	if !ok {
+		return Author{}, fmt.Errorf("could not delete author, %w", ErrNotFound)
+	}
+	if version != 0 && a.Version != version {
+		return Author{}, fmt.Errorf("could not delete author, %w", ErrVersionMismatch)
+	}
+	return a, nil
+}
+
+// bookCount counts the books (that are not deleted) by an author.
+func (ms *MemoryStore) bookCount(authorID uuid.UUID) uint32 {
+	var count uint32
+	for _, b := range ms.books {
+		if b.AuthorID == authorID && b.DeletedAt == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// authorNameTaken determines if an author other than `id` (that is not
+// deleted) already has a full name.
+func (ms *MemoryStore) authorNameTaken(firstName, lastName string, id uuid.UUID) bool {
+	for _, a := range ms.authors {
+		if a.ID != id && a.DeletedAt == nil && a.FirstName == firstName && a.LastName == lastName {
+			return true
+		}
+	}
+	return false
+}
+
+// bookTitleTaken determines if a book other than `id` (that is not deleted)
+// by the same author already has a title.
+func (ms *MemoryStore) bookTitleTaken(authorID uuid.UUID, title string, id uuid.UUID) bool {
+	for _, b := range ms.books {
+		if b.ID != id && b.DeletedAt == nil && b.AuthorID == authorID && b.Title == title {
+			return true
+		}
+	}
+	return false
+}
+
+// bookMatches determines if a book matches the filters in `q`.
+func (ms *MemoryStore) bookMatches(b Book, q BookQuery) bool {
+	if q.AuthorID != nil && b.AuthorID != *q.AuthorID {
+		return false
+	}
+	if q.TitleContains != "" && !strings.Contains(strings.ToLower(b.Title), strings.ToLower(q.TitleContains)) {
+		return false
+	}
+	if q.AuthorNamePrefix != "" {
+		a := ms.authors[b.AuthorID]
+		if !nameHasPrefix(a.FirstName, a.LastName, q.AuthorNamePrefix) {
+			return false
+		}
+	}
+	if q.PublishedAfter != nil && (b.PublishDate == nil || b.PublishDate.Before(*q.PublishedAfter)) {
+		return false
+	}
+	if q.PublishedBefore != nil && (b.PublishDate == nil || !b.PublishDate.Before(*q.PublishedBefore)) {
+		return false
+	}
+	return true
+}
+
+// newID returns `id` if it is set, otherwise a random ID.
+func newID(id uuid.UUID) (uuid.UUID, error) {
+	if id != uuid.Nil {
+		return id, nil
+	}
+	return uuid.NewRandom()
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func trashAuthor(a Author, now time.Time) Author {
+	a.DeletedAt = &now
+	a.Version++
+	return a
+}
+
+func trashBook(b Book, now time.Time) Book {
+	b.DeletedAt = &now
+	b.Version++
+	return b
+}
+
+// trashLess orders items in the trash, most recently deleted first, then by
+// ID.
+func trashLess(deletedAt1 *time.Time, id1 uuid.UUID, deletedAt2 *time.Time, id2 uuid.UUID) bool {
+	if !deletedAt1.Equal(*deletedAt2) {
+		return deletedAt1.After(*deletedAt2)
+	}
+	return bytes.Compare(id1[:], id2[:]) < 0
+}
+
+// nameHasPrefix matches the first name, last name or full name of an author
+// against a (case-insensitive) prefix.
+func nameHasPrefix(firstName, lastName, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	prefix = strings.ToLower(prefix)
+	for _, name := range []string{firstName, lastName, firstName + " " + lastName} {
+		if strings.HasPrefix(strings.ToLower(name), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareAuthors compares two keyset positions in the (ascending) order for
+// `orderBy`, returning -1, 0 or 1.
+func compareAuthors(a, b AuthorCursor, orderBy AuthorOrder) int {
+	if orderBy == AuthorOrderBookCount {
+		if a.BookCount != b.BookCount {
+			if a.BookCount < b.BookCount {
+				return -1
+			}
+			return 1
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	}
+
+	if c := strings.Compare(a.LastName, b.LastName); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.FirstName, b.FirstName); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+// compareBooks compares two keyset positions in the (ascending) order for
+// `orderBy`, returning -1, 0 or 1.
+func compareBooks(a, b BookCursor, orderBy BookOrder) int {
+	if orderBy == BookOrderPublishDate {
+		var t1, t2 time.Time
+		if a.PublishDate != nil {
+			t1 = *a.PublishDate
+		}
+		if b.PublishDate != nil {
+			t2 = *b.PublishDate
+		}
+		if !t1.Equal(t2) {
+			if t1.Before(t2) {
+				return -1
+			}
+			return 1
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	}
+
+	if c := strings.Compare(a.Title, b.Title); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+// searchTerms splits a search query into lowercase terms to include and
+// (prefixed with `-`) to exclude.
+func searchTerms(query string) ([]string, []string) {
+	var include, exclude []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		negated := strings.HasPrefix(field, "-")
+		term := strings.TrimFunc(field, isNotWordRune)
+		if term == "" || term == "or" {
+			continue
+		}
+		if negated {
+			exclude = append(exclude, term)
+		} else {
+			include = append(include, term)
+		}
+	}
+	return include, exclude
+}
+
+// searchMatch matches `text` against search terms. Every included term must
+// be a prefix of some word, and no excluded term may be. The snippet is
+// HTML-escaped and wraps matching words in `<b>` and `</b>`; the score is the
+// fraction of words that match.
+func searchMatch(text string, include, exclude []string) (SearchResult, bool) {
+	words := strings.Fields(text)
+	matched := make([]bool, len(words))
+	for _, term := range include {
+		found := false
+		for i, word := range words {
+			if strings.HasPrefix(strings.ToLower(strings.TrimFunc(word, isNotWordRune)), term) {
+				matched[i] = true
+				found = true
+			}
+		}
+		if !found {
+			return SearchResult{}, false
+		}
+	}
+	for _, term := range exclude {
+		for _, word := range words {
+			if strings.HasPrefix(strings.ToLower(strings.TrimFunc(word, isNotWordRune)), term) {
+				return SearchResult{}, false
+			}
+		}
+	}
+
+	count := 0
+	snippet := make([]string, len(words))
+	for i, word := range words {
+		snippet[i] = html.EscapeString(word)
+		if matched[i] {
+			snippet[i] = "<b>" + snippet[i] + "</b>"
+			count++
+		}
+	}
+	return SearchResult{Snippet: strings.Join(snippet, " "), Score: float64(count) / float64(len(words))}, true
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
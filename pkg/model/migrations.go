@@ -0,0 +1,66 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dhermes/golembic"
+	"github.com/jackc/pgconn"
+)
+
+const (
+	// pgUndefinedTable is the PostgreSQL error code for `undefined_table`.
+	pgUndefinedTable = "42P01"
+
+	getLatestRevision = `
+SELECT
+  revision
+FROM
+  %s
+ORDER BY
+  serial_id DESC
+LIMIT 1
+`
+)
+
+// GetLatestRevision gets the most recently applied migration revision from
+// the golembic metadata table. If no migrations have been applied (or the
+// metadata table does not exist yet) the empty string is returned.
+func GetLatestRevision(ctx context.Context, pool *sql.DB, metadataTable string) (string, error) {
+	defer observeQuery(ctx, "get_latest_revision", time.Now())
+
+	query := fmt.Sprintf(getLatestRevision, golembic.QuoteIdentifier(metadataTable))
+	row := pool.QueryRowContext(ctx, query)
+
+	revision := ""
+	err := row.Scan(&revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUndefinedTable {
+		return "", nil
+	}
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	return revision, nil
+}
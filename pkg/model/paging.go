@@ -0,0 +1,61 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthorCursor is a keyset position in a stable ordering of authors. Only
+// the fields relevant to the `AuthorOrder` are used, i.e. last name, then
+// first name, then ID or book count, then ID.
+type AuthorCursor struct {
+	LastName  string
+	FirstName string
+	BookCount uint32
+	ID        uuid.UUID
+}
+
+// AuthorCursorFrom returns the keyset position of an author.
+func AuthorCursorFrom(a Author) AuthorCursor {
+	return AuthorCursor{LastName: a.LastName, FirstName: a.FirstName, BookCount: a.BookCount, ID: a.ID}
+}
+
+// BookCursor is a keyset position in a stable ordering of books. Only the
+// fields relevant to the `BookOrder` are used, i.e. title, then ID or
+// publish date, then ID.
+type BookCursor struct {
+	Title       string
+	PublishDate *time.Time
+	ID          uuid.UUID
+}
+
+// BookCursorFrom returns the keyset position of a book.
+func BookCursorFrom(b Book) BookCursor {
+	return BookCursor{Title: b.Title, PublishDate: b.PublishDate, ID: b.ID}
+}
+
+// AuditCursor is a keyset position in the (newest first) ordering of audit
+// events.
+type AuditCursor struct {
+	ID int64
+}
+
+// AuditCursorFrom returns the keyset position of an audit event.
+func AuditCursorFrom(e AuditEvent) AuditCursor {
+	return AuditCursor{ID: e.ID}
+}
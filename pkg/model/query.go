@@ -0,0 +1,94 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthorOrder is a column that a list of authors can be ordered by.
+type AuthorOrder string
+
+const (
+	// AuthorOrderLastName orders authors by last name, then first name.
+	AuthorOrderLastName AuthorOrder = "last_name"
+	// AuthorOrderBookCount orders authors by the number of books they have.
+	AuthorOrderBookCount AuthorOrder = "book_count"
+)
+
+// AuthorQuery filters and orders a list of authors. The zero value matches
+// every author, ordered by last name ascending.
+type AuthorQuery struct {
+	// NamePrefix matches authors whose first name, last name or full name
+	// starts with it (case-insensitive).
+	NamePrefix string
+	OrderBy    AuthorOrder
+	Descending bool
+}
+
+// BookOrder is a column that a list of books can be ordered by.
+type BookOrder string
+
+const (
+	// BookOrderTitle orders books by title.
+	BookOrderTitle BookOrder = "title"
+	// BookOrderPublishDate orders books by publish date.
+	BookOrderPublishDate BookOrder = "publish_date"
+)
+
+// BookQuery filters and orders a list of books. The zero value matches every
+// book, ordered by title ascending.
+type BookQuery struct {
+	// AuthorID matches books by a single author.
+	AuthorID *uuid.UUID
+	// TitleContains matches books whose title contains it (case-insensitive).
+	TitleContains string
+	// AuthorNamePrefix matches books whose author's first name, last name or
+	// full name starts with it (case-insensitive).
+	AuthorNamePrefix string
+	// PublishedAfter and PublishedBefore form a half-open range: books
+	// published at or after `PublishedAfter` and strictly before
+	// `PublishedBefore` match.
+	PublishedAfter  *time.Time
+	PublishedBefore *time.Time
+	OrderBy         BookOrder
+	Descending      bool
+}
+
+// AuditQuery filters a list of audit events. The zero value matches every
+// event. Events are always ordered newest first.
+type AuditQuery struct {
+	// EntityType matches events for one type of entity, e.g. `author`.
+	EntityType string
+	// EntityID matches events for a single entity.
+	EntityID *uuid.UUID
+	// Actor matches events made by one actor.
+	Actor string
+	// OccurredAfter and OccurredBefore form a half-open range, in the same
+	// way as `BookQuery.PublishedAfter` and `BookQuery.PublishedBefore`.
+	OccurredAfter  *time.Time
+	OccurredBefore *time.Time
+}
+
+// escapeLike escapes the `LIKE` / `ILIKE` wildcards in `s` (using the default
+// escape character `\`) so that user input only ever matches literally.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
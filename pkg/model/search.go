@@ -0,0 +1,127 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchKind is the type of resource that matched a search.
+type SearchKind string
+
+const (
+	// SearchKindAuthor indicates a search result is an author.
+	SearchKindAuthor SearchKind = "author"
+	// SearchKindBook indicates a search result is a book.
+	SearchKindBook SearchKind = "book"
+)
+
+// SearchResult is a single author or book that matched a full-text search.
+type SearchResult struct {
+	Kind SearchKind
+	ID   uuid.UUID
+	// Snippet is the matched text (a book title or an author's full name),
+	// HTML-escaped, with matching terms wrapped in `<b>` and `</b>`.
+	Snippet string
+	// Score is the relevance of the match; higher is more relevant.
+	Score float64
+}
+
+const (
+	// searchStartSel and searchStopSel mark the matching terms in the output
+	// of `ts_headline()`. They are control characters so that they are left
+	// alone when the text is HTML-escaped, and only then are they replaced
+	// with `<b>` and `</b>`.
+	searchStartSel = "\x02"
+	searchStopSel  = "\x03"
+	// searchHeadlineOptions are the `ts_headline()` options that select the
+	// markers.
+	searchHeadlineOptions = "StartSel=" + searchStartSel + ", StopSel=" + searchStopSel
+)
+
+// searchHighlighter replaces the markers in the (HTML-escaped) output of
+// `ts_headline()` with `<b>` and `</b>`.
+var searchHighlighter = strings.NewReplacer(searchStartSel, "<b>", searchStopSel, "</b>")
+
+const (
+	search = `
+WITH
+  query AS (
+    SELECT websearch_to_tsquery('english', $1) AS q
+  )
+SELECT
+  'book', b.id,
+  ts_headline('english', b.title, query.q, $3),
+  ts_rank(b.search_vector, query.q) AS score
+FROM
+  books AS b,
+  query
+WHERE
+  b.deleted_at IS NULL AND
+  b.search_vector @@ query.q
+UNION ALL
+SELECT
+  'author', a.id,
+  ts_headline('english', a.first_name || ' ' || a.last_name, query.q, $3),
+  ts_rank(a.search_vector, query.q) AS score
+FROM
+  authors AS a,
+  query
+WHERE
+  a.deleted_at IS NULL AND
+  a.search_vector @@ query.q
+ORDER BY
+  score DESC, 2
+LIMIT
+  $2
+`
+)
+
+// Search does a full-text search over book titles and author names. The
+// query uses web search syntax, e.g. `game thrones`, `"game of thrones"` or
+// `tolkien -hobbit`. At most `limit` results are returned, most relevant
+// first.
+func Search(ctx context.Context, pool Queryer, query string, limit int) ([]SearchResult, error) {
+	defer observeQuery(ctx, "search", time.Now())
+
+	rows, err := pool.QueryContext(ctx, search, query, limit, searchHeadlineOptions)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		sr := SearchResult{}
+		err = rows.Scan(&sr.Kind, &sr.ID, &sr.Snippet, &sr.Score)
+		if err != nil {
+			return nil, err
+		}
+		sr.Snippet = searchHighlighter.Replace(html.EscapeString(sr.Snippet))
+		results = append(results, sr)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NOTE: Ensure that
+//       * `SQLStore` satisfies `Store`.
+var (
+	_ Store = (*SQLStore)(nil)
+)
+
+// SQLStore is a `Store` backed by PostgreSQL; each method calls the
+// package-level function of the same name.
+type SQLStore struct {
+	// pool is `nil` when the store is bound to a transaction in a batch.
+	pool *sql.DB
+	q    Queryer
+}
+
+// NewSQLStore returns a `Store` that uses a database connection pool.
+func NewSQLStore(pool *sql.DB) *SQLStore {
+	return &SQLStore{pool: pool, q: pool}
+}
+
+// InsertAuthor satisfies the `Store` interface.
+func (ss *SQLStore) InsertAuthor(ctx context.Context, a Author) (uuid.UUID, error) {
+	return InsertAuthor(ctx, ss.q, a)
+}
+
+// UpdateAuthor satisfies the `Store` interface.
+func (ss *SQLStore) UpdateAuthor(ctx context.Context, a Author) (int64, error) {
+	return UpdateAuthor(ctx, ss.q, a)
+}
+
+// PatchAuthor satisfies the `Store` interface.
+func (ss *SQLStore) PatchAuthor(ctx context.Context, id uuid.UUID, p AuthorPatch, version int64) (int64, error) {
+	return PatchAuthor(ctx, ss.q, id, p, version)
+}
+
+// GetAuthorByID satisfies the `Store` interface.
+func (ss *SQLStore) GetAuthorByID(ctx context.Context, id uuid.UUID) (*Author, error) {
+	return GetAuthorByID(ctx, ss.q, id)
+}
+
+// GetAuthorByName satisfies the `Store` interface.
+func (ss *SQLStore) GetAuthorByName(ctx context.Context, firstName, lastName string) (*Author, error) {
+	return GetAuthorByName(ctx, ss.q, firstName, lastName)
+}
+
+// GetAuthors satisfies the `Store` interface.
+func (ss *SQLStore) GetAuthors(ctx context.Context, q AuthorQuery, limit int, after *AuthorCursor) ([]Author, error) {
+	return GetAuthors(ctx, ss.q, q, limit, after)
+}
+
+// DeleteAuthorByID satisfies the `Store` interface.
+func (ss *SQLStore) DeleteAuthorByID(ctx context.Context, id uuid.UUID, version int64) error {
+	return DeleteAuthorByID(ctx, ss.q, id, version)
+}
+
+// DeleteAuthorByIDCascade satisfies the `Store` interface.
+func (ss *SQLStore) DeleteAuthorByIDCascade(ctx context.Context, id uuid.UUID, version int64) (int64, error) {
+	return DeleteAuthorByIDCascade(ctx, ss.q, id, version)
+}
+
+// RestoreAuthorByID satisfies the `Store` interface.
+func (ss *SQLStore) RestoreAuthorByID(ctx context.Context, id uuid.UUID) (int64, error) {
+	return RestoreAuthorByID(ctx, ss.q, id)
+}
+
+// GetDeletedAuthors satisfies the `Store` interface.
+func (ss *SQLStore) GetDeletedAuthors(ctx context.Context) ([]Author, error) {
+	return GetDeletedAuthors(ctx, ss.q)
+}
+
+// PurgeDeletedAuthors satisfies the `Store` interface.
+func (ss *SQLStore) PurgeDeletedAuthors(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return PurgeDeletedAuthors(ctx, ss.q, deletedBefore)
+}
+
+// InsertBook satisfies the `Store` interface.
+func (ss *SQLStore) InsertBook(ctx context.Context, b Book) (uuid.UUID, error) {
+	return InsertBook(ctx, ss.q, b)
+}
+
+// UpdateBook satisfies the `Store` interface.
+func (ss *SQLStore) UpdateBook(ctx context.Context, b Book) (int64, error) {
+	return UpdateBook(ctx, ss.q, b)
+}
+
+// PatchBook satisfies the `Store` interface.
+func (ss *SQLStore) PatchBook(ctx context.Context, id uuid.UUID, p BookPatch, version int64) (int64, error) {
+	return PatchBook(ctx, ss.q, id, p, version)
+}
+
+// GetBookByID satisfies the `Store` interface.
+func (ss *SQLStore) GetBookByID(ctx context.Context, id uuid.UUID) (*Book, error) {
+	return GetBookByID(ctx, ss.q, id)
+}
+
+// GetBooks satisfies the `Store` interface.
+func (ss *SQLStore) GetBooks(ctx context.Context, q BookQuery, limit int, after *BookCursor) ([]Book, error) {
+	return GetBooks(ctx, ss.q, q, limit, after)
+}
+
+// DeleteBookByID satisfies the `Store` interface.
+func (ss *SQLStore) DeleteBookByID(ctx context.Context, id uuid.UUID, version int64) error {
+	return DeleteBookByID(ctx, ss.q, id, version)
+}
+
+// RestoreBookByID satisfies the `Store` interface.
+func (ss *SQLStore) RestoreBookByID(ctx context.Context, id uuid.UUID) (int64, error) {
+	return RestoreBookByID(ctx, ss.q, id)
+}
+
+// GetDeletedBooks satisfies the `Store` interface.
+func (ss *SQLStore) GetDeletedBooks(ctx context.Context) ([]Book, error) {
+	return GetDeletedBooks(ctx, ss.q)
+}
+
+// PurgeDeletedBooks satisfies the `Store` interface.
+func (ss *SQLStore) PurgeDeletedBooks(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return PurgeDeletedBooks(ctx, ss.q, deletedBefore)
+}
+
+// Search satisfies the `Store` interface.
+func (ss *SQLStore) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return Search(ctx, ss.q, query, limit)
+}
+
+// RunBatch satisfies the `Store` interface. Batches cannot be nested.
+func (ss *SQLStore) RunBatch(ctx context.Context, ops []BatchOperation) error {
+	if ss.pool == nil {
+		return errors.New("cannot run a batch inside a batch")
+	}
+	return RunBatch(ctx, ss.pool, ops)
+}
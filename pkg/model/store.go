@@ -0,0 +1,76 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store persists authors and books. Every implementation enforces the same
+// constraints and reports violations with the same sentinel errors: full
+// names are unique among authors that are not deleted, as are titles among
+// an author's books (`ErrConflict`); a book's author must exist and not be
+// deleted (`ErrInvalidReference`); an author with books can only be deleted
+// by cascading (`ErrHasBooks`); and a non-zero version makes a write
+// conditional (`ErrVersionMismatch`).
+//
+// The methods have the same semantics as the package-level functions of the
+// same name.
+type Store interface {
+	InsertAuthor(ctx context.Context, a Author) (uuid.UUID, error)
+	UpdateAuthor(ctx context.Context, a Author) (int64, error)
+	PatchAuthor(ctx context.Context, id uuid.UUID, p AuthorPatch, version int64) (int64, error)
+	GetAuthorByID(ctx context.Context, id uuid.UUID) (*Author, error)
+	GetAuthorByName(ctx context.Context, firstName, lastName string) (*Author, error)
+	GetAuthors(ctx context.Context, q AuthorQuery, limit int, after *AuthorCursor) ([]Author, error)
+	DeleteAuthorByID(ctx context.Context, id uuid.UUID, version int64) error
+	DeleteAuthorByIDCascade(ctx context.Context, id uuid.UUID, version int64) (int64, error)
+	RestoreAuthorByID(ctx context.Context, id uuid.UUID) (int64, error)
+	GetDeletedAuthors(ctx context.Context) ([]Author, error)
+	PurgeDeletedAuthors(ctx context.Context, deletedBefore time.Time) (int64, error)
+
+	InsertBook(ctx context.Context, b Book) (uuid.UUID, error)
+	UpdateBook(ctx context.Context, b Book) (int64, error)
+	PatchBook(ctx context.Context, id uuid.UUID, p BookPatch, version int64) (int64, error)
+	GetBookByID(ctx context.Context, id uuid.UUID) (*Book, error)
+	GetBooks(ctx context.Context, q BookQuery, limit int, after *BookCursor) ([]Book, error)
+	DeleteBookByID(ctx context.Context, id uuid.UUID, version int64) error
+	RestoreBookByID(ctx context.Context, id uuid.UUID) (int64, error)
+	GetDeletedBooks(ctx context.Context) ([]Book, error)
+	PurgeDeletedBooks(ctx context.Context, deletedBefore time.Time) (int64, error)
+
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	// RunBatch runs `ops` atomically; see `RunBatch()` for the semantics.
+	// Each operation is passed a `Store` that it must use for all of its
+	// reads and writes.
+	RunBatch(ctx context.Context, ops []BatchOperation) error
+}
+
+type storeKey struct{}
+
+// WithStore adds an author and book store to a context.
+func WithStore(ctx context.Context, s Store) context.Context {
+	return context.WithValue(ctx, storeKey{}, s)
+}
+
+// GetStore gets an author and book store from a context.
+func GetStore(ctx context.Context) Store {
+	raw := ctx.Value(storeKey{})
+	s, _ := raw.(Store)
+	return s // Will be `nil` if type assertion fails
+}
@@ -0,0 +1,550 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+// testDSNEnv names the environment variable with the DSN of a migrated
+// database; if it is not set, the tests only run against `MemoryStore`.
+const testDSNEnv = "BOOKS_TEST_DSN"
+
+// testPublishDate is used for every book, since `publish_date` is required
+// in PostgreSQL.
+var testPublishDate = time.Date(1968, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+// forEachStore runs `fn` against `MemoryStore` and, if `BOOKS_TEST_DSN` is
+// set, against `SQLStore`. Each call to `newStore()` returns an empty
+// `MemoryStore`, but always the same `SQLStore`; since the database may hold
+// other data, tests must not assume a store is empty.
+func forEachStore(t *testing.T, fn func(t *testing.T, newStore func() Store)) {
+	t.Run("memory", func(t *testing.T) {
+		fn(t, func() Store { return NewMemoryStore() })
+	})
+	t.Run("sql", func(t *testing.T) {
+		dsn := os.Getenv(testDSNEnv)
+		if dsn == "" {
+			t.Skipf("%s is not set", testDSNEnv)
+		}
+		pool, err := sql.Open("pgx", dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { pool.Close() })
+		s := NewSQLStore(pool)
+		fn(t, func() Store { return s })
+	})
+}
+
+// storeFixture is a set of authors and books, with names unique to one test,
+// in each of the states the sentinel errors depend on.
+type storeFixture struct {
+	// suffix is appended to every last name, so fixtures never collide.
+	suffix string
+	// author has one book, `book`.
+	author Author
+	book   Book
+	// other has no books other than `trashedBook`, which is deleted.
+	other       Author
+	trashedBook Book
+	// gone has been deleted, along with its one book, `goneBook`.
+	gone     Author
+	goneBook Book
+}
+
+func newStoreFixture(ctx context.Context, t *testing.T, s Store) storeFixture {
+	t.Helper()
+
+	f := storeFixture{suffix: uuid.NewString()[:8]}
+	f.author = mustInsertAuthor(ctx, t, s, "Ursula", "Le Guin "+f.suffix)
+	f.book = mustInsertBook(ctx, t, s, f.author.ID, "A Wizard of Earthsea")
+	f.other = mustInsertAuthor(ctx, t, s, "Octavia", "Butler "+f.suffix)
+	f.trashedBook = mustInsertBook(ctx, t, s, f.other.ID, "Kindred")
+	f.gone = mustInsertAuthor(ctx, t, s, "Iain", "Banks "+f.suffix)
+	f.goneBook = mustInsertBook(ctx, t, s, f.gone.ID, "Excession")
+
+	err := s.DeleteBookByID(ctx, f.trashedBook.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.DeleteAuthorByIDCascade(ctx, f.gone.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+func mustInsertAuthor(ctx context.Context, t *testing.T, s Store, firstName, lastName string) Author {
+	t.Helper()
+
+	id, err := s.InsertAuthor(ctx, Author{FirstName: firstName, LastName: lastName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := s.GetAuthorByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *a
+}
+
+func mustInsertBook(ctx context.Context, t *testing.T, s Store, authorID uuid.UUID, title string) Book {
+	t.Helper()
+
+	id, err := s.InsertBook(ctx, Book{AuthorID: authorID, Title: title, PublishDate: &testPublishDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.GetBookByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *b
+}
+
+func TestStoreErrors(t *testing.T) {
+	type testCase struct {
+		Name string
+		Op   func(ctx context.Context, s Store, f storeFixture) error
+		Want error
+	}
+
+	cases := []testCase{
+		{
+			Name: "insert author with taken name",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertAuthor(ctx, Author{FirstName: f.author.FirstName, LastName: f.author.LastName})
+				return err
+			},
+			Want: ErrConflict,
+		},
+		{
+			Name: "insert author with taken ID",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertAuthor(ctx, Author{ID: f.other.ID, FirstName: "Jo", LastName: "Walton " + f.suffix})
+				return err
+			},
+			Want: ErrConflict,
+		},
+		{
+			Name: "insert author with name of deleted author",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertAuthor(ctx, Author{FirstName: f.gone.FirstName, LastName: f.gone.LastName})
+				return err
+			},
+			Want: nil,
+		},
+		{
+			Name: "update author to taken name",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.UpdateAuthor(ctx, Author{ID: f.other.ID, FirstName: f.author.FirstName, LastName: f.author.LastName})
+				return err
+			},
+			Want: ErrConflict,
+		},
+		{
+			Name: "update missing author",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.UpdateAuthor(ctx, Author{ID: uuid.New(), FirstName: "Jo", LastName: "Walton " + f.suffix})
+				return err
+			},
+			Want: ErrNotFound,
+		},
+		{
+			Name: "patch deleted author",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				firstName := "Iain M."
+				_, err := s.PatchAuthor(ctx, f.gone.ID, AuthorPatch{FirstName: &firstName}, 0)
+				return err
+			},
+			Want: ErrNotFound,
+		},
+		{
+			Name: "patch author with stale version",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				firstName := "Ursula K."
+				_, err := s.PatchAuthor(ctx, f.author.ID, AuthorPatch{FirstName: &firstName}, f.author.Version+1)
+				return err
+			},
+			Want: ErrVersionMismatch,
+		},
+		{
+			Name: "patch author with current version",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				firstName := "Ursula K."
+				_, err := s.PatchAuthor(ctx, f.author.ID, AuthorPatch{FirstName: &firstName}, f.author.Version)
+				return err
+			},
+			Want: nil,
+		},
+		{
+			Name: "get deleted author",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.GetAuthorByID(ctx, f.gone.ID)
+				return err
+			},
+			Want: ErrNotFound,
+		},
+		{
+			Name: "delete author with books",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				return s.DeleteAuthorByID(ctx, f.author.ID, 0)
+			},
+			Want: ErrHasBooks,
+		},
+		{
+			Name: "delete author with only deleted books",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				return s.DeleteAuthorByID(ctx, f.other.ID, 0)
+			},
+			Want: nil,
+		},
+		{
+			Name: "delete author with stale version",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				return s.DeleteAuthorByID(ctx, f.other.ID, f.other.Version+1)
+			},
+			Want: ErrVersionMismatch,
+		},
+		{
+			Name: "cascade delete author with stale version",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.DeleteAuthorByIDCascade(ctx, f.author.ID, f.author.Version+1)
+				return err
+			},
+			Want: ErrVersionMismatch,
+		},
+		{
+			Name: "delete deleted author",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				return s.DeleteAuthorByID(ctx, f.gone.ID, 0)
+			},
+			Want: ErrNotFound,
+		},
+		{
+			Name: "restore author that is not deleted",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.RestoreAuthorByID(ctx, f.author.ID)
+				return err
+			},
+			Want: ErrNotFound,
+		},
+		{
+			Name: "restore author whose name was reused",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertAuthor(ctx, Author{FirstName: f.gone.FirstName, LastName: f.gone.LastName})
+				if err != nil {
+					return fmt.Errorf("setup: %w", err)
+				}
+				_, err = s.RestoreAuthorByID(ctx, f.gone.ID)
+				return err
+			},
+			Want: ErrConflict,
+		},
+		{
+			Name: "insert book by missing author",
+			Op: func(ctx context.Context, s Store, _ storeFixture) error {
+				_, err := s.InsertBook(ctx, Book{AuthorID: uuid.New(), Title: "The Dispossessed", PublishDate: &testPublishDate})
+				return err
+			},
+			Want: ErrInvalidReference,
+		},
+		{
+			Name: "insert book by deleted author",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertBook(ctx, Book{AuthorID: f.gone.ID, Title: "Use of Weapons", PublishDate: &testPublishDate})
+				return err
+			},
+			Want: ErrInvalidReference,
+		},
+		{
+			Name: "insert book with taken title",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertBook(ctx, Book{AuthorID: f.author.ID, Title: f.book.Title, PublishDate: &testPublishDate})
+				return err
+			},
+			Want: ErrConflict,
+		},
+		{
+			Name: "insert book with title taken by another author",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertBook(ctx, Book{AuthorID: f.other.ID, Title: f.book.Title, PublishDate: &testPublishDate})
+				return err
+			},
+			Want: nil,
+		},
+		{
+			Name: "insert book with taken ID",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertBook(ctx, Book{ID: f.book.ID, AuthorID: f.author.ID, Title: "The Dispossessed", PublishDate: &testPublishDate})
+				return err
+			},
+			Want: ErrConflict,
+		},
+		{
+			Name: "move book to deleted author",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.PatchBook(ctx, f.book.ID, BookPatch{AuthorID: &f.gone.ID}, 0)
+				return err
+			},
+			Want: ErrInvalidReference,
+		},
+		{
+			Name: "move book to author with taken title",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertBook(ctx, Book{AuthorID: f.other.ID, Title: f.book.Title, PublishDate: &testPublishDate})
+				if err != nil {
+					return fmt.Errorf("setup: %w", err)
+				}
+				_, err = s.PatchBook(ctx, f.book.ID, BookPatch{AuthorID: &f.other.ID}, 0)
+				return err
+			},
+			Want: ErrConflict,
+		},
+		{
+			Name: "update book with stale version",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				b := f.book
+				b.Title = "The Tombs of Atuan"
+				b.Version++
+				_, err := s.UpdateBook(ctx, b)
+				return err
+			},
+			Want: ErrVersionMismatch,
+		},
+		{
+			Name: "update deleted book",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.UpdateBook(ctx, f.trashedBook)
+				return err
+			},
+			Want: ErrNotFound,
+		},
+		{
+			Name: "get deleted book",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.GetBookByID(ctx, f.trashedBook.ID)
+				return err
+			},
+			Want: ErrNotFound,
+		},
+		{
+			Name: "delete book with stale version",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				return s.DeleteBookByID(ctx, f.book.ID, f.book.Version+1)
+			},
+			Want: ErrVersionMismatch,
+		},
+		{
+			Name: "delete missing book",
+			Op: func(ctx context.Context, s Store, _ storeFixture) error {
+				return s.DeleteBookByID(ctx, uuid.New(), 0)
+			},
+			Want: ErrNotFound,
+		},
+		{
+			Name: "restore book",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.RestoreBookByID(ctx, f.trashedBook.ID)
+				return err
+			},
+			Want: nil,
+		},
+		{
+			Name: "restore book that is not deleted",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.RestoreBookByID(ctx, f.book.ID)
+				return err
+			},
+			Want: ErrNotFound,
+		},
+		{
+			Name: "restore book by deleted author",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.RestoreBookByID(ctx, f.goneBook.ID)
+				return err
+			},
+			Want: ErrInvalidReference,
+		},
+		{
+			Name: "restore book whose title was reused",
+			Op: func(ctx context.Context, s Store, f storeFixture) error {
+				_, err := s.InsertBook(ctx, Book{AuthorID: f.other.ID, Title: f.trashedBook.Title, PublishDate: &testPublishDate})
+				if err != nil {
+					return fmt.Errorf("setup: %w", err)
+				}
+				_, err = s.RestoreBookByID(ctx, f.trashedBook.ID)
+				return err
+			},
+			Want: ErrConflict,
+		},
+	}
+
+	forEachStore(t, func(t *testing.T, newStore func() Store) {
+		for _, tc := range cases {
+			tc := tc
+			t.Run(tc.Name, func(t *testing.T) {
+				ctx := context.Background()
+				s := newStore()
+				f := newStoreFixture(ctx, t, s)
+
+				err := tc.Op(ctx, s, f)
+				if !errors.Is(err, tc.Want) {
+					t.Fatalf("got error %v, want %v", err, tc.Want)
+				}
+			})
+		}
+	})
+}
+
+func TestStoreRunBatchRollsBack(t *testing.T) {
+	forEachStore(t, func(t *testing.T, newStore func() Store) {
+		ctx := context.Background()
+		s := newStore()
+		f := newStoreFixture(ctx, t, s)
+
+		firstName, lastName := "Jo", "Walton "+f.suffix
+		ops := []BatchOperation{
+			func(ctx context.Context, s Store) error {
+				_, err := s.InsertAuthor(ctx, Author{FirstName: firstName, LastName: lastName})
+				return err
+			},
+			func(ctx context.Context, s Store) error {
+				return s.DeleteAuthorByID(ctx, f.author.ID, 0)
+			},
+		}
+		err := s.RunBatch(ctx, ops)
+		var be *BatchError
+		if !errors.As(err, &be) {
+			t.Fatalf("got error %v, want a batch error", err)
+		}
+		if len(be.Errors) != 2 || be.Errors[0] != nil || !errors.Is(be.Errors[1], ErrHasBooks) {
+			t.Fatalf("got batch errors %v, want [nil, %v]", be.Errors, ErrHasBooks)
+		}
+
+		_, err = s.GetAuthorByName(ctx, firstName, lastName)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("got error %v after a failed batch, want %v", err, ErrNotFound)
+		}
+	})
+}
+
+func TestStoreSearchEscapesSnippet(t *testing.T) {
+	forEachStore(t, func(t *testing.T, newStore func() Store) {
+		ctx := context.Background()
+		s := newStore()
+		f := newStoreFixture(ctx, t, s)
+
+		b := mustInsertBook(ctx, t, s, f.author.ID, "Tigana & Sons \"Ltd\" "+f.suffix)
+		results, err := s.Search(ctx, "tigana", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "<b>Tigana</b> &amp; Sons &#34;Ltd&#34; " + f.suffix
+		for _, sr := range results {
+			if sr.ID != b.ID {
+				continue
+			}
+			if sr.Snippet != want {
+				t.Fatalf("got snippet %q, want %q", sr.Snippet, want)
+			}
+			return
+		}
+		t.Fatalf("book %s is not in the search results %v", b.ID, results)
+	})
+}
+
+func TestStoreConcurrentUse(t *testing.T) {
+	const workers = 8
+
+	forEachStore(t, func(t *testing.T, newStore func() Store) {
+		ctx := context.Background()
+		s := newStore()
+		suffix := uuid.NewString()[:8]
+
+		// NOTE: Every worker adds the same author, then a book of its own and
+		//       reads while the others write, so that `go test -race` can
+		//       catch unsynchronized access.
+		authorIDs := make(chan uuid.UUID, workers)
+		errs := make(chan error, workers)
+		wg := sync.WaitGroup{}
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				id, err := s.InsertAuthor(ctx, Author{FirstName: "N. K.", LastName: "Jemisin " + suffix})
+				if errors.Is(err, ErrConflict) {
+					a, err := s.GetAuthorByName(ctx, "N. K.", "Jemisin "+suffix)
+					if err != nil {
+						errs <- err
+						return
+					}
+					id = a.ID
+				} else if err != nil {
+					errs <- err
+					return
+				} else {
+					authorIDs <- id
+				}
+
+				_, err = s.InsertBook(ctx, Book{AuthorID: id, Title: fmt.Sprintf("The Broken Earth, Part %d", i), PublishDate: &testPublishDate})
+				if err != nil {
+					errs <- err
+					return
+				}
+				_, err = s.GetBooks(ctx, BookQuery{AuthorID: &id}, workers, nil)
+				if err != nil {
+					errs <- err
+					return
+				}
+				_, err = s.Search(ctx, "broken", workers)
+				if err != nil {
+					errs <- err
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(authorIDs)
+		close(errs)
+
+		for err := range errs {
+			t.Error(err)
+		}
+		if len(authorIDs) != 1 {
+			t.Fatalf("%d workers inserted the author, want exactly 1", len(authorIDs))
+		}
+		a, err := s.GetAuthorByID(ctx, <-authorIDs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.BookCount != workers {
+			t.Fatalf("author has %d books, want %d", a.BookCount, workers)
+		}
+	})
+}
@@ -0,0 +1,337 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	insertWebhook = `
+INSERT INTO
+  webhooks (id, url, secret, created_at)
+VALUES
+  ($1, $2, $3, NOW())
+RETURNING
+  created_at
+`
+	getWebhooks = `
+SELECT
+  id,
+  url,
+  secret,
+  created_at
+FROM
+  webhooks
+ORDER BY
+  created_at, id
+`
+	deleteWebhookByID = `
+WITH deleted AS (
+  DELETE FROM
+    webhooks
+  WHERE
+    id = $1
+  RETURNING
+    id
+), deleted_deliveries AS (
+  DELETE FROM
+    webhook_deliveries
+  WHERE
+    webhook_id IN (SELECT id FROM deleted)
+)
+SELECT
+  COUNT(*)
+FROM
+  deleted
+`
+	// NOTE: Claimed deliveries are leased (by pushing back
+	//       `next_attempt_at`) rather than locked for the duration of the
+	//       HTTP request, so no transaction is held open while delivering
+	//       and a dispatcher that dies mid-delivery only delays a retry.
+	claimWebhookDeliveries = `
+WITH claimed AS (
+  SELECT
+    id
+  FROM
+    webhook_deliveries
+  WHERE
+    delivered_at IS NULL AND
+    dead_at IS NULL AND
+    next_attempt_at <= NOW()
+  ORDER BY
+    next_attempt_at, id
+  LIMIT
+    $1
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE
+  webhook_deliveries AS d
+SET
+  next_attempt_at = NOW() + make_interval(secs => $2)
+FROM
+  claimed AS c,
+  webhooks AS w,
+  audit_log AS e
+WHERE
+  d.id = c.id AND
+  w.id = d.webhook_id AND
+  e.id = d.audit_event_id
+RETURNING
+  d.id,
+  d.webhook_id,
+  d.audit_event_id,
+  d.attempts,
+  w.url,
+  w.secret,
+  e.occurred_at,
+  e.action,
+  e.entity_type,
+  e.entity_id,
+  e.before,
+  e.after
+`
+	markWebhookDelivered = `
+UPDATE
+  webhook_deliveries
+SET
+  attempts = attempts + 1,
+  delivered_at = NOW(),
+  last_error = NULL
+WHERE
+  id = $1
+`
+	markWebhookFailed = `
+UPDATE
+  webhook_deliveries
+SET
+  attempts = attempts + 1,
+  last_error = $2,
+  next_attempt_at = $3,
+  dead_at = CASE WHEN $4::BOOLEAN THEN NOW() END
+WHERE
+  id = $1
+`
+	getWebhookDeadLetters = `
+SELECT
+  l.id,
+  l.webhook_id,
+  l.audit_event_id,
+  l.attempts,
+  l.last_error,
+  l.dead_at,
+  e.occurred_at,
+  e.action,
+  e.entity_type,
+  e.entity_id,
+  e.before,
+  e.after
+FROM
+  webhook_dead_letters AS l
+INNER JOIN
+  audit_log AS e
+ON
+  e.id = l.audit_event_id
+WHERE
+  l.webhook_id = $1
+ORDER BY
+  l.dead_at DESC, l.id DESC
+LIMIT
+  $2
+`
+	deleteDeliveredWebhookDeliveries = `
+DELETE FROM
+  webhook_deliveries
+WHERE
+  delivered_at < $1
+`
+)
+
+// InsertWebhook inserts a new webhook into the database and returns it with
+// its ID and creation time populated.
+func InsertWebhook(ctx context.Context, pool *sql.DB, wh Webhook) (*Webhook, error) {
+	defer observeQuery(ctx, "insert_webhook", time.Now())
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	row := pool.QueryRowContext(ctx, insertWebhook, id, wh.URL, wh.Secret)
+	wh.ID = id
+	err = row.Scan(&wh.CreatedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &wh, nil
+}
+
+// GetWebhooks gets all webhooks from the database, oldest first.
+func GetWebhooks(ctx context.Context, pool *sql.DB) ([]Webhook, error) {
+	defer observeQuery(ctx, "get_webhooks", time.Now())
+
+	rows, err := pool.QueryContext(ctx, getWebhooks)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+	for rows.Next() {
+		wh := Webhook{}
+		err = rows.Scan(&wh.ID, &wh.URL, &wh.Secret, &wh.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhookByID deletes a webhook from the database by ID, along with
+// any of its deliveries (pending, delivered or dead).
+func DeleteWebhookByID(ctx context.Context, pool *sql.DB, id uuid.UUID) error {
+	defer observeQuery(ctx, "delete_webhook_by_id", time.Now())
+
+	row := pool.QueryRowContext(ctx, deleteWebhookByID, id)
+
+	var deleteCount int64
+	err := row.Scan(&deleteCount)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if deleteCount == 0 {
+		return fmt.Errorf("could not delete webhook, %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+// ClaimWebhookDeliveries claims up to `limit` deliveries that are due and
+// returns them with their webhook and event populated. A claimed delivery
+// is not due again until `lease` has passed, by which time it should have
+// been marked as delivered or failed.
+func ClaimWebhookDeliveries(ctx context.Context, pool *sql.DB, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	defer observeQuery(ctx, "claim_webhook_deliveries", time.Now())
+
+	rows, err := pool.QueryContext(ctx, claimWebhookDeliveries, limit, lease.Seconds())
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		d := WebhookDelivery{}
+		e := &d.Event
+		err = rows.Scan(
+			&d.ID, &d.WebhookID, &d.AuditEventID, &d.Attempts, &d.Webhook.URL, &d.Webhook.Secret,
+			&e.OccurredAt, &e.Action, &e.EntityType, &e.EntityID, &e.Before, &e.After,
+		)
+		if err != nil {
+			return nil, err
+		}
+		d.Webhook.ID = d.WebhookID
+		e.ID = d.AuditEventID
+		deliveries = append(deliveries, d)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// MarkWebhookDelivered records a successful delivery.
+func MarkWebhookDelivered(ctx context.Context, pool *sql.DB, id int64) error {
+	defer observeQuery(ctx, "mark_webhook_delivered", time.Now())
+
+	_, err := pool.ExecContext(ctx, markWebhookDelivered, id)
+	return translateError(err)
+}
+
+// MarkWebhookFailed records a failed delivery attempt. The delivery is
+// retried at `nextAttemptAt` unless `dead` is set, in which case it is moved
+// to the dead letters.
+func MarkWebhookFailed(ctx context.Context, pool *sql.DB, id int64, lastError string, nextAttemptAt time.Time, dead bool) error {
+	defer observeQuery(ctx, "mark_webhook_failed", time.Now())
+
+	_, err := pool.ExecContext(ctx, markWebhookFailed, id, lastError, nextAttemptAt, dead)
+	return translateError(err)
+}
+
+// GetWebhookDeadLetters gets the deliveries for a webhook that failed too
+// many times to be retried, most recent first, with their event populated.
+// At most `limit` deliveries are returned.
+func GetWebhookDeadLetters(ctx context.Context, pool *sql.DB, webhookID uuid.UUID, limit int) ([]WebhookDelivery, error) {
+	defer observeQuery(ctx, "get_webhook_dead_letters", time.Now())
+
+	rows, err := pool.QueryContext(ctx, getWebhookDeadLetters, webhookID, limit)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		d := WebhookDelivery{}
+		e := &d.Event
+		err = rows.Scan(
+			&d.ID, &d.WebhookID, &d.AuditEventID, &d.Attempts, &d.LastError, &d.DeadAt,
+			&e.OccurredAt, &e.Action, &e.EntityType, &e.EntityID, &e.Before, &e.After,
+		)
+		if err != nil {
+			return nil, err
+		}
+		e.ID = d.AuditEventID
+		deliveries = append(deliveries, d)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// DeleteDeliveredWebhookDeliveries deletes deliveries that succeeded before
+// `deliveredBefore` and returns the number deleted; dead letters are kept
+// until their webhook is deleted.
+func DeleteDeliveredWebhookDeliveries(ctx context.Context, pool *sql.DB, deliveredBefore time.Time) (int64, error) {
+	defer observeQuery(ctx, "delete_delivered_webhook_deliveries", time.Now())
+
+	result, err := pool.ExecContext(ctx, deleteDeliveredWebhookDeliveries, deliveredBefore)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return result.RowsAffected()
+}
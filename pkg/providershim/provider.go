@@ -36,6 +36,33 @@ func Provider() *schema.Provider {
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc(booksprovider.EnvVarBooksAPIAddr, nil),
 			},
+			"token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc(booksprovider.EnvVarBooksAPIToken, nil),
+			},
+			"ca_cert_pem": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(booksprovider.EnvVarBooksAPICACertPEM, nil),
+			},
+			"client_cert_pem": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(booksprovider.EnvVarBooksAPIClientCertPEM, nil),
+			},
+			"client_key_pem": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc(booksprovider.EnvVarBooksAPIClientKeyPEM, nil),
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(booksprovider.EnvVarBooksAPIInsecureSkipVerify, false),
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"books_api_author": resourceAuthor(),
@@ -0,0 +1,61 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+)
+
+// NOTE: Ensure that
+//       * `addAPIToken` satisfies `handleFunc`.
+var (
+	_ handleFunc = addAPIToken
+)
+
+func addAPIToken(w http.ResponseWriter, req *http.Request) {
+	var aatr addAPITokenRequest
+	if invalidJSONBody(w, req, &aatr) {
+		return
+	}
+
+	if aatr.Name == "" {
+		invalidArgument(w, req, "API token name is required", "name")
+		return
+	}
+	if !validRole(aatr.Role) {
+		invalidArgument(w, req, "API token role must be one of reader, editor or admin", "role")
+		return
+	}
+
+	ctx := req.Context()
+	token, id, err := MintAPIToken(ctx, aatr.Name, aatr.Role)
+	if err != nil {
+		modelError(w, req, err, "failed to mint API token")
+		return
+	}
+
+	response := addAPITokenResponse{TokenID: id.String(), Token: token}
+	serializeJSONResponse(w, req, response)
+}
+
+type addAPITokenRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type addAPITokenResponse struct {
+	TokenID string `json:"token_id"`
+	Token   string `json:"token"`
+}
@@ -15,77 +15,43 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
 
+	"github.com/google/uuid"
+
 	"github.com/dhermes/example-terraform-provider/pkg/model"
 )
 
 // NOTE: Ensure that
-//       * `oneAuthorDispatch` satisfies `handleFunc`.
 //       * `addAuthor` satisfies `handleFunc`.
 var (
-	_ handleFunc = oneAuthorDispatch
 	_ handleFunc = addAuthor
 )
 
-// oneAuthorDispatch dispatches to `addAuthor()` for a `POST` request,
-// to `getAuthorByName()` for a `GET` request and to `updateAuthor()` for a
-// `PUT` request.
-func oneAuthorDispatch(w http.ResponseWriter, req *http.Request) {
-	if req.Method == http.MethodPost {
-		addAuthor(w, req)
-		return
-	}
-
-	if req.Method == http.MethodGet {
-		getAuthorByName(w, req)
-		return
-	}
-
-	if req.Method == http.MethodPut {
-		updateAuthor(w, req)
-		return
-	}
-
-	notAllowed(w, req, req.Method+"-mismatch") // Ensure the method is wrong
-}
-
 func addAuthor(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodPost) {
-		return
-	}
-	if contentTypeNotJSON(w, req) {
-		return
-	}
-	if req.URL.Path != "/v1alpha1/author" {
-		notFound(w)
-		return
-	}
-
 	var aar addAuthorRequest
 	if invalidJSONBody(w, req, &aar) {
 		return
 	}
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	a := model.Author{FirstName: aar.FirstName, LastName: aar.LastName}
-	id, err := model.InsertAuthor(ctx, pool, a)
+	store := model.GetStore(ctx)
+	a := model.Author{ID: aar.ID, FirstName: aar.FirstName, LastName: aar.LastName}
+	id, err := store.InsertAuthor(ctx, a)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to insert author"}`+"\n")
+		modelError(w, req, err, "failed to insert author")
 		return
 	}
 
 	response := addAuthorResponse{AuthorID: id.String()}
-	serializeJSONResponse(w, response)
+	serializeJSONResponse(w, req, response)
 }
 
 type addAuthorRequest struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	// ID is optional; if it is not provided, a random ID is generated.
+	ID        uuid.UUID `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
 }
 
 type addAuthorResponse struct {
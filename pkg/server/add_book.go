@@ -15,7 +15,6 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
 	"time"
 
@@ -25,62 +24,37 @@ import (
 )
 
 // NOTE: Ensure that
-//       * `oneBookDispatch` satisfies `handleFunc`.
 //       * `addBook` satisfies `handleFunc`.
 var (
-	_ handleFunc = oneBookDispatch
-	_ handleFunc = getAuthorByID
+	_ handleFunc = addBook
 )
 
-// oneBookDispatch dispatches to `addBook()` for a `POST` request and to
-// `updateBook()` for a `PUT` request.
-func oneBookDispatch(w http.ResponseWriter, req *http.Request) {
-	if req.Method == http.MethodPost {
-		addBook(w, req)
-		return
-	}
-
-	if req.Method == http.MethodPut {
-		updateBook(w, req)
-		return
-	}
-
-	notAllowed(w, req, req.Method+"-mismatch") // Ensure the method is wrong
-}
-
 func addBook(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodPost) {
-		return
-	}
-	if contentTypeNotJSON(w, req) {
-		return
-	}
-	if req.URL.Path != "/v1alpha1/book" {
-		notFound(w)
-		return
-	}
-
 	var abr addBookRequest
 	if invalidJSONBody(w, req, &abr) {
 		return
 	}
+	if abr.PublishDate == nil {
+		invalidArgument(w, req, "publish date is required", "publish_date")
+		return
+	}
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	b := model.Book{AuthorID: abr.AuthorID, Title: abr.Title, PublishDate: abr.PublishDate}
-	id, err := model.InsertBook(ctx, pool, b)
+	store := model.GetStore(ctx)
+	b := model.Book{ID: abr.ID, AuthorID: abr.AuthorID, Title: abr.Title, PublishDate: abr.PublishDate}
+	id, err := store.InsertBook(ctx, b)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to insert book"}`+"\n")
+		modelError(w, req, err, "failed to insert book")
 		return
 	}
 
 	response := addBookResponse{BookID: id.String()}
-	serializeJSONResponse(w, response)
+	serializeJSONResponse(w, req, response)
 }
 
 type addBookRequest struct {
+	// ID is optional; if it is not provided, a random ID is generated.
+	ID          uuid.UUID  `json:"id"`
 	Title       string     `json:"title"`
 	AuthorID    uuid.UUID  `json:"author_id"`
 	PublishDate *time.Time `json:"publish_date"`
@@ -0,0 +1,85 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `addWebhook` satisfies `handleFunc`.
+var (
+	_ handleFunc = addWebhook
+)
+
+// addWebhook subscribes a URL to every change to authors and books. The
+// secret used to sign deliveries is only returned here.
+func addWebhook(w http.ResponseWriter, req *http.Request) {
+	var awr addWebhookRequest
+	if invalidJSONBody(w, req, &awr) {
+		return
+	}
+
+	if !validWebhookURL(awr.URL) {
+		invalidArgument(w, req, "webhook URL must be an absolute http or https URL", "url")
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		internalError(w, req, "could not generate webhook secret")
+		return
+	}
+
+	ctx := req.Context()
+	pool := model.GetPool(ctx)
+	wh, err := model.InsertWebhook(ctx, pool, model.Webhook{URL: awr.URL, Secret: secret})
+	if err != nil {
+		modelError(w, req, err, "failed to insert webhook")
+		return
+	}
+
+	response := addWebhookResponse{
+		webhookResponse: dbWebhookToResult(wh),
+		Secret:          wh.Secret,
+	}
+	serializeJSONResponse(w, req, response)
+}
+
+type addWebhookRequest struct {
+	URL string `json:"url"`
+}
+
+type webhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type addWebhookResponse struct {
+	webhookResponse
+	Secret string `json:"secret"`
+}
+
+func dbWebhookToResult(wh *model.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:        wh.ID.String(),
+		URL:       wh.URL,
+		CreatedAt: wh.CreatedAt.UTC(),
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+const (
+	// apiTokenPrefix makes API tokens easy to recognize, e.g. by secret
+	// scanners.
+	apiTokenPrefix = "books_"
+	// apiTokenBytes is the number of random bytes in an API token.
+	apiTokenBytes = 32
+	// bearerPrefix is the (case-insensitive) `Authorization` scheme for
+	// API tokens.
+	bearerPrefix = "bearer "
+)
+
+// MintAPIToken creates a new API token named `name` with the given role (one
+// of `RoleReader`, `RoleEditor` or `RoleAdmin`) and stores its hash in the
+// database attached to the context by `Context()`. The returned token is the
+// only copy; it cannot be recovered later.
+func MintAPIToken(ctx context.Context, name, role string) (string, uuid.UUID, error) {
+	if name == "" {
+		return "", uuid.Nil, errors.New("API token name is required")
+	}
+	if !validRole(role) {
+		return "", uuid.Nil, fmt.Errorf("invalid API token role %q", role)
+	}
+
+	raw := make([]byte, apiTokenBytes)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	pool := model.GetPool(ctx)
+	t := model.APIToken{Name: name, TokenHash: hashAPIToken(token), Role: role}
+	id, err := model.InsertAPIToken(ctx, pool, t)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+
+	return token, id, nil
+}
+
+// RevokeAPIToken revokes an API token in the database attached to the
+// context by `Context()`.
+func RevokeAPIToken(ctx context.Context, id uuid.UUID) error {
+	pool := model.GetPool(ctx)
+	return model.RevokeAPIToken(ctx, pool, id)
+}
+
+func hashAPIToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// devAPIToken is the API token that every request is treated as using with
+// `StorageMemory`, since there are no API tokens to check against.
+var devAPIToken = model.APIToken{Name: "dev", Role: RoleAdmin}
+
+// warnDevAPIToken warns that every request is treated as using `devAPIToken`
+// and calls out each of `addrs` that is reachable from other hosts, since
+// anyone who can connect has full access.
+func warnDevAPIToken(w io.Writer, addrs ...string) {
+	fmt.Fprintf(w, "WARNING: storage is %q, so every request is treated as coming from an %q API token\n", StorageMemory, RoleAdmin)
+	for _, addr := range addrs {
+		if addr != "" && !isLoopbackAddr(addr) {
+			fmt.Fprintf(w, "WARNING: %q is not a loopback address; other hosts can connect with %q access\n", addr, RoleAdmin)
+		}
+	}
+}
+
+// isLoopbackAddr determines if a bind address only accepts connections from
+// this host. An empty host (e.g. `:7534`) binds every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+type apiTokenKey struct{}
+
+// requireAPIToken rejects requests that do not carry a valid (unrevoked)
+// bearer token in the `Authorization` header. The matching token is attached
+// to the request context (along with the actor for the audit log) and its ID
+// is recorded for the access log.
+func requireAPIToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		t, message, err := verifyAPIToken(ctx, req.Header.Get(HeaderAuthorization))
+		if err != nil {
+			modelError(w, req, err, "could not verify API token")
+			return
+		}
+		if t == nil {
+			unauthenticated(w, req, message)
+			return
+		}
+
+		setRequestAPIToken(ctx, t.ID)
+		ctx = context.WithValue(ctx, apiTokenKey{}, t)
+		ctx = model.WithActor(ctx, requestActor(req, t))
+		h.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// verifyAPIToken looks up the (unrevoked) API token sent as a bearer token in
+// an `Authorization` header. If there is no valid token, the returned token
+// is `nil` and the message describes why the request is unauthenticated.
+// With `StorageMemory`, every request uses `devAPIToken`, with or without an
+// `Authorization` header.
+func verifyAPIToken(ctx context.Context, authorization string) (*model.APIToken, string, error) {
+	pool := model.GetPool(ctx)
+	if pool == nil {
+		t := devAPIToken
+		return &t, "", nil
+	}
+
+	if authorization == "" {
+		return nil, "missing API token", nil
+	}
+	if len(authorization) <= len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return nil, "malformed Authorization header", nil
+	}
+	token := strings.TrimSpace(authorization[len(bearerPrefix):])
+
+	t, err := model.GetAPITokenByHash(ctx, pool, hashAPIToken(token))
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, "invalid API token", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return t, "", nil
+}
+
+// requestActor determines the actor recorded in the audit log for changes
+// made by a request: the `X-Actor` header if set, otherwise the name of the
+// API token. The API token ID is always recorded alongside it.
+func requestActor(req *http.Request, t *model.APIToken) model.Actor {
+	name := strings.TrimSpace(req.Header.Get(HeaderActor))
+	if name == "" {
+		name = t.Name
+	}
+	return model.Actor{Name: name, APITokenID: t.ID}
+}
+
+// getAPIToken gets the API token attached to a context by `requireAPIToken`.
+func getAPIToken(ctx context.Context) *model.APIToken {
+	raw := ctx.Value(apiTokenKey{})
+	t, _ := raw.(*model.APIToken)
+	return t // Will be `nil` if type assertion fails
+}
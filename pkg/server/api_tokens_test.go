@@ -0,0 +1,60 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIsLoopbackAddr(t *testing.T) {
+	type testCase struct {
+		Addr string
+		Want bool
+	}
+
+	cases := []testCase{
+		{Addr: "127.0.0.1:7534", Want: true},
+		{Addr: "127.1.2.3:7534", Want: true},
+		{Addr: "[::1]:7534", Want: true},
+		{Addr: "localhost:7534", Want: true},
+		{Addr: ":7534", Want: false},
+		{Addr: "0.0.0.0:7534", Want: false},
+		{Addr: "[::]:7534", Want: false},
+		{Addr: "10.0.0.5:7534", Want: false},
+		{Addr: "books.example.com:7534", Want: false},
+		{Addr: "127.0.0.1", Want: false},
+	}
+	for _, tc := range cases {
+		got := isLoopbackAddr(tc.Addr)
+		if got != tc.Want {
+			t.Errorf("isLoopbackAddr(%q) = %t, want %t", tc.Addr, got, tc.Want)
+		}
+	}
+}
+
+func TestWarnDevAPIToken(t *testing.T) {
+	w := &bytes.Buffer{}
+	warnDevAPIToken(w, "127.0.0.1:7534", ":7535", "")
+
+	lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d warnings, want 2:\n%s", len(lines), w.String())
+	}
+	if !strings.Contains(lines[1], `":7535"`) {
+		t.Fatalf("second warning %q does not name the non-loopback address", lines[1])
+	}
+}
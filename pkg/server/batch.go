@@ -0,0 +1,422 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `runBatch` satisfies `handleFunc`.
+//       * `*invalidBatchOperation` satisfies `error`.
+var (
+	_ handleFunc = runBatch
+	_ error      = (*invalidBatchOperation)(nil)
+)
+
+const (
+	// maxBatchOperations is the largest number of operations a batch may
+	// contain.
+	maxBatchOperations = 100
+)
+
+var (
+	// batchReference matches a string that refers to a field in the result
+	// of an earlier operation in the same batch, e.g. `$ops[0].author_id`.
+	batchReference = regexp.MustCompile(`^\$ops\[(\d+)\]\.([a-z_]+)$`)
+)
+
+func runBatch(w http.ResponseWriter, req *http.Request) {
+	var br batchRequest
+	if invalidJSONBody(w, req, &br) {
+		return
+	}
+	if len(br.Operations) == 0 || len(br.Operations) > maxBatchOperations {
+		invalidArgument(w, req, fmt.Sprintf("batch must have between 1 and %d operations", maxBatchOperations), "operations")
+		return
+	}
+
+	// NOTE: The route only requires `permissionWrite`; deletes additionally
+	//       require `permissionDelete`, just like the single-item routes.
+	t := getAPIToken(req.Context())
+	for i, bo := range br.Operations {
+		if bo.Action == batchActionDelete && !hasPermission(t.Role, permissionDelete) {
+			message := fmt.Sprintf("API token role %q does not have %q permission for operation %d", t.Role, permissionDelete, i)
+			permissionDenied(w, req, message)
+			return
+		}
+	}
+
+	results := make([]map[string]interface{}, len(br.Operations))
+	ops := make([]model.BatchOperation, len(br.Operations))
+	for i, bo := range br.Operations {
+		op, err := bo.prepare(i, results)
+		if err != nil {
+			invalidArgument(w, req, err.Error(), fmt.Sprintf("operations[%d].action", i))
+			return
+		}
+		ops[i] = op
+	}
+
+	ctx := req.Context()
+	store := model.GetStore(ctx)
+	err := store.RunBatch(ctx, ops)
+	var be *model.BatchError
+	if errors.As(err, &be) {
+		batchError(w, req, be)
+		return
+	}
+	if err != nil {
+		modelError(w, req, err, "failed to run batch")
+		return
+	}
+
+	serializeJSONResponse(w, req, batchResponse{Results: results})
+}
+
+// batchError writes an error response for a batch with at least one failed
+// operation. The top-level error describes the first failure; every failure
+// is listed in `operations`.
+func batchError(w http.ResponseWriter, req *http.Request, be *model.BatchError) {
+	setRequestError(req.Context(), be)
+	ber := batchErrorResponse{}
+	status := 0
+	for i, err := range be.Errors {
+		if err == nil {
+			continue
+		}
+
+		opStatus, er := batchOperationErrorResponse(i, err)
+		if status == 0 {
+			status = opStatus
+			ber.errorResponse = er
+			ber.Message = fmt.Sprintf("operation %d failed: %s", i, er.Message)
+			ber.RequestID = getRequestID(req.Context())
+		}
+		ber.Operations = append(ber.Operations, batchOperationError{Index: i, errorResponse: er})
+	}
+
+	responseBody, err := json.Marshal(ber)
+	if err != nil {
+		internalError(w, req, "could not serialize response")
+		return
+	}
+
+	w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "%s\n", responseBody)
+}
+
+func batchOperationErrorResponse(i int, err error) (int, errorResponse) {
+	var ibo *invalidBatchOperation
+	if errors.As(err, &ibo) {
+		return http.StatusBadRequest, errorResponse{Code: ErrorCodeInvalidArgument, Message: ibo.message, Field: ibo.field}
+	}
+
+	return modelErrorResponse(err, fmt.Sprintf("failed to run operation %d", i))
+}
+
+// prepare validates the action and resource of a batch operation and
+// returns a `model.BatchOperation` that runs it. The body is only decoded
+// when the operation runs, since it may refer to the results of earlier
+// operations; on success the result is stored in `results[i]`.
+func (bo batchOperation) prepare(i int, results []map[string]interface{}) (model.BatchOperation, error) {
+	var run func(context.Context, model.Store, []byte) (map[string]interface{}, error)
+	switch {
+	case bo.Action == batchActionCreate && bo.Resource == batchResourceAuthor:
+		run = batchAddAuthor
+	case bo.Action == batchActionUpdate && bo.Resource == batchResourceAuthor:
+		run = bo.updateAuthor
+	case bo.Action == batchActionDelete && bo.Resource == batchResourceAuthor:
+		run = bo.deleteAuthor
+	case bo.Action == batchActionCreate && bo.Resource == batchResourceBook:
+		run = batchAddBook
+	case bo.Action == batchActionUpdate && bo.Resource == batchResourceBook:
+		run = bo.updateBook
+	case bo.Action == batchActionDelete && bo.Resource == batchResourceBook:
+		run = bo.deleteBook
+	default:
+		return nil, fmt.Errorf("unsupported action %q for resource %q", bo.Action, bo.Resource)
+	}
+
+	op := func(ctx context.Context, store model.Store) error {
+		body, err := resolveBatchReferences(bo.Body, i, results)
+		if err != nil {
+			return err
+		}
+
+		result, err := run(ctx, store, body)
+		if err != nil {
+			return err
+		}
+
+		results[i] = result
+		return nil
+	}
+	return op, nil
+}
+
+// resolveBatchReferences replaces every string in `body` of the form
+// `$ops[N].field` with `field` from the result of operation `N`, which must
+// be an earlier operation that succeeded.
+func resolveBatchReferences(body json.RawMessage, i int, results []map[string]interface{}) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var v interface{}
+	err := d.Decode(&v)
+	if err != nil {
+		return nil, &invalidBatchOperation{message: "invalid operation body", field: fmt.Sprintf("operations[%d].body", i)}
+	}
+
+	resolved, err := resolveBatchValue(v, i, results)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(resolved)
+}
+
+func resolveBatchValue(v interface{}, i int, results []map[string]interface{}) (interface{}, error) {
+	switch typed := v.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			resolved, err := resolveBatchValue(value, i, results)
+			if err != nil {
+				return nil, err
+			}
+			typed[key] = resolved
+		}
+		return typed, nil
+	case []interface{}:
+		for j, value := range typed {
+			resolved, err := resolveBatchValue(value, i, results)
+			if err != nil {
+				return nil, err
+			}
+			typed[j] = resolved
+		}
+		return typed, nil
+	case string:
+		return resolveBatchString(typed, i, results)
+	default:
+		return v, nil
+	}
+}
+
+func resolveBatchString(s string, i int, results []map[string]interface{}) (interface{}, error) {
+	match := batchReference.FindStringSubmatch(s)
+	if match == nil {
+		return s, nil
+	}
+
+	field := fmt.Sprintf("operations[%d].body", i)
+	j, err := strconv.Atoi(match[1])
+	if err != nil || j >= i {
+		return nil, &invalidBatchOperation{message: fmt.Sprintf("%s does not refer to an earlier operation", s), field: field}
+	}
+	if results[j] == nil {
+		return nil, &invalidBatchOperation{message: fmt.Sprintf("%s refers to a failed operation", s), field: field}
+	}
+	value, ok := results[j][match[2]]
+	if !ok {
+		return nil, &invalidBatchOperation{message: fmt.Sprintf("%s refers to a field that operation %d did not return", s, j), field: field}
+	}
+
+	return value, nil
+}
+
+// decodeBatchBody strictly decodes the (resolved) body of a batch operation.
+func decodeBatchBody(body []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.DisallowUnknownFields()
+	err := d.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	ibo := &invalidBatchOperation{message: "invalid operation body"}
+	var ute *json.UnmarshalTypeError
+	if errors.As(err, &ute) {
+		ibo.field = ute.Field
+	}
+	return ibo
+}
+
+func batchAddAuthor(ctx context.Context, store model.Store, body []byte) (map[string]interface{}, error) {
+	var aar addAuthorRequest
+	err := decodeBatchBody(body, &aar)
+	if err != nil {
+		return nil, err
+	}
+
+	a := model.Author{ID: aar.ID, FirstName: aar.FirstName, LastName: aar.LastName}
+	id, err := store.InsertAuthor(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"author_id": id.String()}, nil
+}
+
+func (bo batchOperation) updateAuthor(ctx context.Context, store model.Store, body []byte) (map[string]interface{}, error) {
+	var uar updateAuthorRequest
+	err := decodeBatchBody(body, &uar)
+	if err != nil {
+		return nil, err
+	}
+
+	a := model.Author{ID: uar.ID, FirstName: uar.FirstName, LastName: uar.LastName, Version: bo.Version}
+	version, err := store.UpdateAuthor(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"version": version}, nil
+}
+
+func (bo batchOperation) deleteAuthor(ctx context.Context, store model.Store, body []byte) (map[string]interface{}, error) {
+	var bdr batchDeleteRequest
+	err := decodeBatchBody(body, &bdr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.DeleteAuthorByID(ctx, bdr.ID, bo.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{}, nil
+}
+
+func batchAddBook(ctx context.Context, store model.Store, body []byte) (map[string]interface{}, error) {
+	var abr addBookRequest
+	err := decodeBatchBody(body, &abr)
+	if err != nil {
+		return nil, err
+	}
+	if abr.PublishDate == nil {
+		return nil, &invalidBatchOperation{message: "publish date is required", field: "publish_date"}
+	}
+
+	b := model.Book{ID: abr.ID, AuthorID: abr.AuthorID, Title: abr.Title, PublishDate: abr.PublishDate}
+	id, err := store.InsertBook(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"book_id": id.String()}, nil
+}
+
+func (bo batchOperation) updateBook(ctx context.Context, store model.Store, body []byte) (map[string]interface{}, error) {
+	var ubr updateBookRequest
+	err := decodeBatchBody(body, &ubr)
+	if err != nil {
+		return nil, err
+	}
+	if ubr.PublishDate == nil {
+		return nil, &invalidBatchOperation{message: "publish date is required", field: "publish_date"}
+	}
+
+	b := model.Book{ID: ubr.ID, AuthorID: ubr.AuthorID, Title: ubr.Title, PublishDate: ubr.PublishDate, Version: bo.Version}
+	version, err := store.UpdateBook(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"version": version}, nil
+}
+
+func (bo batchOperation) deleteBook(ctx context.Context, store model.Store, body []byte) (map[string]interface{}, error) {
+	var bdr batchDeleteRequest
+	err := decodeBatchBody(body, &bdr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.DeleteBookByID(ctx, bdr.ID, bo.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{}, nil
+}
+
+// invalidBatchOperation is returned from a batch operation that could not be
+// run because its body is invalid, as opposed to an error from `model`.
+type invalidBatchOperation struct {
+	message string
+	field   string
+}
+
+// Error satisfies the `error` interface.
+func (ibo *invalidBatchOperation) Error() string {
+	return ibo.message
+}
+
+const (
+	batchActionCreate = "create"
+	batchActionUpdate = "update"
+	batchActionDelete = "delete"
+
+	batchResourceAuthor = "author"
+	batchResourceBook   = "book"
+)
+
+type batchRequest struct {
+	Operations []batchOperation `json:"operations"`
+}
+
+// batchOperation is a single operation in a batch. The `body` is the same
+// as the body of the equivalent single request (`{"id": ...}` for a delete);
+// any string value in it can instead be a reference to a field in the
+// result of an earlier operation, e.g. `"$ops[0].author_id"`.
+type batchOperation struct {
+	Action   string          `json:"action"`
+	Resource string          `json:"resource"`
+	Body     json.RawMessage `json:"body"`
+	// Version, if set, is the `If-Match` precondition for an update or
+	// delete.
+	Version int64 `json:"version,omitempty"`
+}
+
+type batchDeleteRequest struct {
+	ID uuid.UUID `json:"id"`
+}
+
+type batchResponse struct {
+	Results []map[string]interface{} `json:"results"`
+}
+
+type batchOperationError struct {
+	Index int `json:"index"`
+	errorResponse
+}
+
+type batchErrorResponse struct {
+	errorResponse
+	Operations []batchOperationError `json:"operations"`
+}
@@ -14,17 +14,105 @@
 
 package server
 
+import (
+	"time"
+
+	"github.com/dhermes/golembic"
+)
+
+const (
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultReadTimeout       = 30 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultShutdownTimeout   = 30 * time.Second
+	defaultRequestTimeout    = 20 * time.Second
+	defaultStatementTimeout  = 15 * time.Second
+	defaultIdempotencyKeyTTL = 24 * time.Hour
+	defaultTrashRetention    = 30 // days
+)
+
+const (
+	// StorageSQL stores authors and books (and everything else) in the
+	// PostgreSQL database at `Config.DSN`.
+	StorageSQL = "sql"
+	// StorageMemory stores authors and books in memory, so nothing is
+	// persisted across restarts. The features that need a database (API
+	// tokens, idempotency keys, the audit log, events and webhooks) are
+	// unavailable and every request is treated as coming from an admin.
+	StorageMemory = "memory"
+)
+
 // Config provides the core set of (CLI) inputs needed to run the Books
 // API server.
 type Config struct {
 	Addr string
 	DSN  string
+	// DBPasswordFile is a file containing the database password; if it is
+	// set, its contents (without a trailing newline) take the place of any
+	// password in `DSN`, so the DSN need not contain a secret.
+	DBPasswordFile string
+	// Storage is the backend for authors and books, either `StorageSQL` or
+	// `StorageMemory`; `DSN` is not used with `StorageMemory`.
+	Storage string
+	// GRPCAddr is the bind address for the gRPC transport of the Books API;
+	// if it is empty, only HTTP/JSON is served.
+	GRPCAddr string
+	// TLSCertFile and TLSKeyFile are the PEM encoded certificate (chain) and
+	// private key used to serve HTTPS (and gRPC over TLS); if they are empty,
+	// plaintext is served.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile is a PEM encoded bundle of CAs; if it is set, clients
+	// must present a certificate signed by one of them (mutual TLS).
+	TLSClientCAFile string
+
+	// ReadHeaderTimeout, ReadTimeout, WriteTimeout and IdleTimeout are
+	// passed through to the `http.Server` with the same names.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout is how long in-flight requests are given to drain
+	// after a SIGINT / SIGTERM before the server stops forcefully.
+	ShutdownTimeout time.Duration
+	// RequestTimeout is the deadline attached to each request context (and
+	// hence to every database query made while handling it).
+	RequestTimeout time.Duration
+	// StatementTimeout is set as the PostgreSQL `statement_timeout` for
+	// every connection in the pool; zero disables it.
+	StatementTimeout time.Duration
+	// MetricsEnabled determines if Prometheus metrics are collected and
+	// served at `/metrics`.
+	MetricsEnabled bool
+	// MetadataTable is the golembic metadata table that records applied
+	// migrations; it is used to determine readiness at `/readyz`.
+	MetadataTable string
+	// IdempotencyKeyTTL is how long the response for an `Idempotency-Key`
+	// is retained (and replayed for repeated requests with the same key).
+	IdempotencyKeyTTL time.Duration
+	// TrashRetentionDays is how long deleted authors and books stay in the
+	// trash (and can be restored) before they are permanently deleted; zero
+	// keeps them forever.
+	TrashRetentionDays int
 }
 
 // NewConfig returns a new `Config` with all relevant defaults provided and
 // options for overriding.
 func NewConfig(opts ...Option) (Config, error) {
-	c := Config{}
+	c := Config{
+		Storage:            StorageSQL,
+		ReadHeaderTimeout:  defaultReadHeaderTimeout,
+		ReadTimeout:        defaultReadTimeout,
+		WriteTimeout:       defaultWriteTimeout,
+		IdleTimeout:        defaultIdleTimeout,
+		ShutdownTimeout:    defaultShutdownTimeout,
+		RequestTimeout:     defaultRequestTimeout,
+		StatementTimeout:   defaultStatementTimeout,
+		MetadataTable:      golembic.DefaultMetadataTable,
+		IdempotencyKeyTTL:  defaultIdempotencyKeyTTL,
+		TrashRetentionDays: defaultTrashRetention,
+	}
 	for _, opt := range opts {
 		err := opt(&c)
 		if err != nil {
@@ -0,0 +1,329 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	// EnvPrefix is the prefix of the environment variables that override
+	// config keys; e.g. `BOOKS_READ_TIMEOUT` overrides `read_timeout`.
+	EnvPrefix = "BOOKS_"
+	// redacted replaces secrets when a config is printed; it matches the
+	// placeholder used by `url.URL.Redacted()`.
+	redacted = "xxxxx"
+)
+
+var (
+	// dsnPasswordPattern matches the password in a key / value DSN such as
+	// `host=127.0.0.1 user=books_app password='secret'`.
+	dsnPasswordPattern = regexp.MustCompile(`(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S*)`)
+)
+
+// configKey describes a single key that can be set in a config file, via
+// an environment variable or via a flag.
+type configKey struct {
+	Name string
+	Set  func(c *Config, value string) error
+	Get  func(c Config) interface{}
+}
+
+// configKeys are all of the keys that can be set, in the order they are
+// printed by `WriteConfig()`.
+var configKeys = []configKey{
+	stringKey("addr", func(c *Config) *string { return &c.Addr }),
+	stringKey("dsn", func(c *Config) *string { return &c.DSN }),
+	stringKey("db_password_file", func(c *Config) *string { return &c.DBPasswordFile }),
+	stringKey("storage", func(c *Config) *string { return &c.Storage }),
+	stringKey("grpc_addr", func(c *Config) *string { return &c.GRPCAddr }),
+	stringKey("tls_cert", func(c *Config) *string { return &c.TLSCertFile }),
+	stringKey("tls_key", func(c *Config) *string { return &c.TLSKeyFile }),
+	stringKey("tls_client_ca", func(c *Config) *string { return &c.TLSClientCAFile }),
+	durationKey("read_header_timeout", func(c *Config) *time.Duration { return &c.ReadHeaderTimeout }),
+	durationKey("read_timeout", func(c *Config) *time.Duration { return &c.ReadTimeout }),
+	durationKey("write_timeout", func(c *Config) *time.Duration { return &c.WriteTimeout }),
+	durationKey("idle_timeout", func(c *Config) *time.Duration { return &c.IdleTimeout }),
+	durationKey("shutdown_timeout", func(c *Config) *time.Duration { return &c.ShutdownTimeout }),
+	durationKey("request_timeout", func(c *Config) *time.Duration { return &c.RequestTimeout }),
+	durationKey("statement_timeout", func(c *Config) *time.Duration { return &c.StatementTimeout }),
+	{
+		Name: "metrics",
+		Set: func(c *Config, value string) error {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			c.MetricsEnabled = enabled
+			return nil
+		},
+		Get: func(c Config) interface{} { return c.MetricsEnabled },
+	},
+	stringKey("metadata_table", func(c *Config) *string { return &c.MetadataTable }),
+	durationKey("idempotency_key_ttl", func(c *Config) *time.Duration { return &c.IdempotencyKeyTTL }),
+	{
+		Name: "trash_retention_days",
+		Set: func(c *Config, value string) error {
+			days, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			c.TrashRetentionDays = days
+			return nil
+		},
+		Get: func(c Config) interface{} { return c.TrashRetentionDays },
+	},
+}
+
+func stringKey(name string, field func(c *Config) *string) configKey {
+	return configKey{
+		Name: name,
+		Set: func(c *Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+		Get: func(c Config) interface{} { return *field(&c) },
+	}
+}
+
+func durationKey(name string, field func(c *Config) *time.Duration) configKey {
+	return configKey{
+		Name: name,
+		Set: func(c *Config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			*field(c) = d
+			return nil
+		},
+		Get: func(c Config) interface{} { return field(&c).String() },
+	}
+}
+
+func lookupConfigKey(name string) (configKey, bool) {
+	for _, ck := range configKeys {
+		if ck.Name == name {
+			return ck, true
+		}
+	}
+	return configKey{}, false
+}
+
+// ConfigKeys returns the names of all keys that can be set in a config file,
+// e.g. `read_timeout`. The matching environment variable is the upper case
+// name with `EnvPrefix`, e.g. `BOOKS_READ_TIMEOUT`.
+func ConfigKeys() []string {
+	names := make([]string, len(configKeys))
+	for i, ck := range configKeys {
+		names[i] = ck.Name
+	}
+	return names
+}
+
+// EnvVar returns the environment variable that overrides a config key.
+func EnvVar(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+// Set sets a config key from its string form, e.g. `"30s"` for
+// `read_timeout`. The error names the key if it is unknown or if the value
+// is invalid.
+func (c *Config) Set(key, value string) error {
+	ck, ok := lookupConfigKey(key)
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	err := ck.Set(c, value)
+	if err != nil {
+		return fmt.Errorf("invalid %q: %w", key, err)
+	}
+	return nil
+}
+
+// LoadConfig returns a config with the defaults from `NewConfig()`,
+// overridden by the TOML config file at `path` (if `path` is not empty) and
+// then by `BOOKS_*` environment variables (via `lookupEnv`, usually
+// `os.LookupEnv`). Flags are expected to be applied on top with
+// `Config.Set()`.
+func LoadConfig(path string, lookupEnv func(string) (string, bool)) (Config, error) {
+	c, err := NewConfig()
+	if err != nil {
+		return Config{}, err
+	}
+
+	if path != "" {
+		err = c.loadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, ck := range configKeys {
+		name := EnvVar(ck.Name)
+		value, ok := lookupEnv(name)
+		if !ok {
+			continue
+		}
+		err = ck.Set(&c, value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	values := map[string]interface{}{}
+	_, err := toml.DecodeFile(path, &values)
+	if err != nil {
+		return fmt.Errorf("could not read config file %q, %w", path, err)
+	}
+
+	// NOTE: Keys are applied in a fixed order (rather than map order) so the
+	//       first error reported for a file is deterministic.
+	for _, ck := range configKeys {
+		raw, ok := values[ck.Name]
+		if !ok {
+			continue
+		}
+		delete(values, ck.Name)
+
+		var value string
+		switch v := raw.(type) {
+		case string:
+			value = v
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			return fmt.Errorf("config file %q: invalid %q: unsupported value %v", path, ck.Name, raw)
+		}
+		err = ck.Set(c, value)
+		if err != nil {
+			return fmt.Errorf("config file %q: invalid %q: %w", path, ck.Name, err)
+		}
+	}
+
+	if len(values) > 0 {
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("config file %q: unknown key %q", path, names[0])
+	}
+	return nil
+}
+
+// Validate checks the values in a config that can be checked without
+// reading any files; the error names the offending key.
+func (c Config) Validate() error {
+	if c.Storage != StorageSQL && c.Storage != StorageMemory {
+		return fmt.Errorf("invalid %q: must be %q or %q", "storage", StorageSQL, StorageMemory)
+	}
+	durations := []struct {
+		Key   string
+		Value time.Duration
+	}{
+		{Key: "read_header_timeout", Value: c.ReadHeaderTimeout},
+		{Key: "read_timeout", Value: c.ReadTimeout},
+		{Key: "write_timeout", Value: c.WriteTimeout},
+		{Key: "idle_timeout", Value: c.IdleTimeout},
+		{Key: "shutdown_timeout", Value: c.ShutdownTimeout},
+		{Key: "request_timeout", Value: c.RequestTimeout},
+		{Key: "statement_timeout", Value: c.StatementTimeout},
+		{Key: "idempotency_key_ttl", Value: c.IdempotencyKeyTTL},
+	}
+	for _, d := range durations {
+		if d.Value < 0 {
+			return fmt.Errorf("invalid %q: must not be negative", d.Key)
+		}
+	}
+	if c.TrashRetentionDays < 0 {
+		return fmt.Errorf("invalid %q: must not be negative", "trash_retention_days")
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New(`invalid "tls_cert" / "tls_key": must be set together`)
+	}
+	if c.TLSClientCAFile != "" && c.TLSCertFile == "" {
+		return errors.New(`invalid "tls_client_ca": requires "tls_cert" and "tls_key"`)
+	}
+	if c.Storage == StorageSQL && c.DSN == "" {
+		return fmt.Errorf("%q is required unless %q is %q", "dsn", "storage", StorageMemory)
+	}
+	return nil
+}
+
+// WriteConfig writes a config in the TOML format read by `LoadConfig()`.
+// Secrets (i.e. a password in the DSN) are redacted.
+func WriteConfig(w io.Writer, c Config) error {
+	c.DSN = redactDSN(c.DSN)
+	for _, ck := range configKeys {
+		var err error
+		switch v := ck.Get(c).(type) {
+		case string:
+			_, err = fmt.Fprintf(w, "%s = %s\n", ck.Name, strconv.Quote(v))
+		default:
+			_, err = fmt.Fprintf(w, "%s = %v\n", ck.Name, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactDSN replaces the password in a URL or key / value DSN.
+func redactDSN(dsn string) string {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsnPasswordPattern.ReplaceAllString(dsn, "${1}"+redacted)
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		// NOTE: An unparseable DSN may still contain a password.
+		return redacted
+	}
+	q := u.Query()
+	if q.Has("password") {
+		q.Set("password", redacted)
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
+
+// readDBPassword reads the database password from a file, dropping the
+// trailing newline most editors (and `echo`) add.
+func readDBPassword(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read database password file, %w", err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -17,6 +17,64 @@ package server
 const (
 	// HeaderContentType is the canonicalized header for content type.
 	HeaderContentType = "Content-Type"
+	// HeaderAllow is the canonicalized header for allowed methods.
+	HeaderAllow = "Allow"
+	// HeaderRequestID is the canonicalized header for a request ID.
+	HeaderRequestID = "X-Request-Id"
+	// HeaderAuthorization is the canonicalized header for credentials.
+	HeaderAuthorization = "Authorization"
+	// HeaderWWWAuthenticate is the canonicalized header for the
+	// authentication scheme expected on a 401 response.
+	HeaderWWWAuthenticate = "Www-Authenticate"
+	// HeaderETag is the canonicalized header for an entity tag, i.e. the
+	// version of a resource.
+	HeaderETag = "Etag"
+	// HeaderIfMatch is the canonicalized header for a conditional write
+	// precondition.
+	HeaderIfMatch = "If-Match"
+	// HeaderIdempotencyKey is the canonicalized header for a client-chosen
+	// key that makes a create request safe to retry.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is the canonicalized header set on a response
+	// that was replayed for a repeated idempotency key.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	// HeaderActor is the canonicalized header for the person or system on
+	// whose behalf a request is made; it is recorded in the audit log in
+	// place of the API token name.
+	HeaderActor = "X-Actor"
+	// HeaderLastEventID is the canonicalized header a server-sent events
+	// client uses to resume a stream after the last event it received.
+	HeaderLastEventID = "Last-Event-Id"
+	// HeaderCacheControl is the canonicalized header for caching directives.
+	HeaderCacheControl = "Cache-Control"
+	// HeaderWebhookEventID is the canonicalized header carrying the ID of the
+	// event in a webhook delivery; receivers can use it to ignore repeated
+	// deliveries.
+	HeaderWebhookEventID = "X-Books-Event-Id"
+	// HeaderWebhookTimestamp is the canonicalized header carrying the Unix
+	// time a webhook delivery was signed.
+	HeaderWebhookTimestamp = "X-Books-Timestamp"
+	// HeaderWebhookSignature is the canonicalized header carrying the
+	// signature of a webhook delivery, `sha256=` followed by the hex encoded
+	// HMAC-SHA256 (keyed by the webhook secret) of the timestamp, a `.` and
+	// the body.
+	HeaderWebhookSignature = "X-Books-Signature"
+	// MetadataError is the gRPC trailer key carrying the JSON error body (the
+	// same as for the HTTP/JSON API) of a failed RPC.
+	MetadataError = "books-error-bin"
+	// MetadataStatus is the gRPC trailer key carrying the HTTP status code
+	// equivalent to the status of a failed RPC; it is also sent (as `200`) in
+	// the header of an accepted `Watch` stream.
+	MetadataStatus = "books-status"
+	// MetadataLastEventID is the gRPC header key carrying the ID of the event
+	// an accepted `Watch` stream starts after, so a client can resume from
+	// there even if no event has been sent yet.
+	MetadataLastEventID = "books-last-event-id"
 	// ContentTypeApplicationJSON is the content type to use for JSON.
 	ContentTypeApplicationJSON = "application/json"
+	// ContentTypeMergePatchJSON is the content type for an RFC 7396 JSON
+	// merge patch.
+	ContentTypeMergePatchJSON = "application/merge-patch+json"
+	// ContentTypeEventStream is the content type for server-sent events.
+	ContentTypeEventStream = "text/event-stream"
 )
@@ -16,24 +16,70 @@ package server
 
 import (
 	"context"
-	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
 
 	"github.com/dhermes/example-terraform-provider/pkg/model"
 )
 
 // Context uses `context.WithValue()` to attach configurated values that will
 // be constant within the scope of the application.
+//
+// With `StorageSQL`, the database pool attached here should be released via
+// `Close()` once the server has stopped. With `StorageMemory` there is no
+// pool; only an (empty) in-memory store is attached.
 func Context(ctx context.Context, c Config) (context.Context, error) {
-	pool, err := sql.Open("pgx", c.DSN)
+	switch c.Storage {
+	case StorageSQL:
+	case StorageMemory:
+		ctx = model.WithStore(ctx, model.NewMemoryStore())
+		if c.MetricsEnabled {
+			ctx = withMetrics(ctx, newServerMetrics(nil))
+		}
+		return ctx, nil
+	default:
+		return nil, fmt.Errorf("invalid storage %q", c.Storage)
+	}
+
+	cc, err := pgx.ParseConfig(c.DSN)
 	if err != nil {
 		return nil, err
 	}
+	if c.DBPasswordFile != "" {
+		password, err := readDBPassword(c.DBPasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		cc.Password = password
+	}
+	if c.StatementTimeout > 0 {
+		cc.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+	pool := stdlib.OpenDB(*cc)
 
 	err = pool.PingContext(ctx)
 	if err != nil {
+		pool.Close()
 		return nil, err
 	}
 
 	ctx = model.WithPool(ctx, pool)
+	ctx = model.WithStore(ctx, model.NewSQLStore(pool))
+	if c.MetricsEnabled {
+		ctx = withMetrics(ctx, newServerMetrics(pool))
+	}
 	return ctx, nil
 }
+
+// Close releases the resources attached to a context by `Context()`.
+func Close(ctx context.Context) error {
+	pool := model.GetPool(ctx)
+	if pool == nil {
+		return nil
+	}
+
+	return pool.Close()
+}
@@ -21,9 +21,9 @@ import (
 // NOTE: Ensure that
 //       * `defaultHandler` satisfies `handleFunc`.
 var (
-	_ handleFunc = getAuthorByID
+	_ handleFunc = defaultHandler
 )
 
 func defaultHandler(w http.ResponseWriter, req *http.Request) {
-	notFound(w)
+	routeNotFound(w, req)
 }
@@ -0,0 +1,40 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+)
+
+// NOTE: Ensure that
+//       * `deleteAPITokenByID` satisfies `handleFunc`.
+var (
+	_ handleFunc = deleteAPITokenByID
+)
+
+// deleteAPITokenByID revokes an API token; the row is kept so the token ID
+// remains meaningful in access logs.
+func deleteAPITokenByID(w http.ResponseWriter, req *http.Request) {
+	id := uuidParam(req, "token_id")
+
+	ctx := req.Context()
+	err := RevokeAPIToken(ctx, id)
+	if err != nil {
+		modelError(w, req, err, "failed to revoke API token")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
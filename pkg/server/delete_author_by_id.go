@@ -15,11 +15,8 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
-	"strings"
-
-	"github.com/google/uuid"
+	"strconv"
 
 	"github.com/dhermes/example-terraform-provider/pkg/model"
 )
@@ -31,33 +28,48 @@ var (
 )
 
 func deleteAuthorByID(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodDelete) {
-		return
+	id := uuidParam(req, "author_id")
+
+	cascade := false
+	if raw := req.URL.Query().Get("cascade"); raw != "" {
+		var err error
+		cascade, err = strconv.ParseBool(raw)
+		if err != nil {
+			invalidArgument(w, req, "invalid cascade", "cascade")
+			return
+		}
 	}
-	if !strings.HasPrefix(req.URL.Path, "/v1alpha1/authors/") {
-		notFound(w)
+
+	var version int64
+	if invalidIfMatch(w, req, &version) {
 		return
 	}
 
-	suffix := strings.TrimPrefix(req.URL.Path, "/v1alpha1/authors/")
-	id, err := uuid.Parse(suffix)
-	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "invalid ID"}`+"\n")
+	ctx := req.Context()
+	store := model.GetStore(ctx)
+	if cascade {
+		booksDeleted, err := store.DeleteAuthorByIDCascade(ctx, id, version)
+		if err != nil {
+			modelError(w, req, err, "failed to delete author by ID")
+			return
+		}
+
+		response := deleteAuthorResponse{BooksDeleted: booksDeleted}
+		serializeJSONResponse(w, req, response)
 		return
 	}
 
-	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	err = model.DeleteAuthorByID(ctx, pool, id)
+	err := store.DeleteAuthorByID(ctx, id, version)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		// TODO: Consider supporting a 404 here
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to delete author by ID"}`+"\n")
+		modelError(w, req, err, "failed to delete author by ID")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// deleteAuthorResponse is only sent for a cascading delete; a plain delete
+// has no response body.
+type deleteAuthorResponse struct {
+	BooksDeleted int64 `json:"books_deleted"`
+}
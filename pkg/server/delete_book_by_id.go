@@ -15,11 +15,7 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
-	"strings"
-
-	"github.com/google/uuid"
 
 	"github.com/dhermes/example-terraform-provider/pkg/model"
 )
@@ -31,31 +27,18 @@ var (
 )
 
 func deleteBookByID(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodDelete) {
-		return
-	}
-	if !strings.HasPrefix(req.URL.Path, "/v1alpha1/books/") {
-		notFound(w)
-		return
-	}
+	id := uuidParam(req, "book_id")
 
-	suffix := strings.TrimPrefix(req.URL.Path, "/v1alpha1/books/")
-	id, err := uuid.Parse(suffix)
-	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "invalid ID"}`+"\n")
+	var version int64
+	if invalidIfMatch(w, req, &version) {
 		return
 	}
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	err = model.DeleteBookByID(ctx, pool, id)
+	store := model.GetStore(ctx)
+	err := store.DeleteBookByID(ctx, id, version)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		// TODO: Consider supporting a 404 here
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to delete book by ID"}`+"\n")
+		modelError(w, req, err, "failed to delete book by ID")
 		return
 	}
 
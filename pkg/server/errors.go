@@ -16,19 +16,118 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
 )
 
-func notAllowed(w http.ResponseWriter, req *http.Request, method string) bool {
-	if req.Method == method {
-		return false
+const (
+	// ErrorCodeInvalidArgument indicates a malformed or missing input.
+	ErrorCodeInvalidArgument = "invalid_argument"
+	// ErrorCodeUnauthenticated indicates a missing, malformed or revoked
+	// API token.
+	ErrorCodeUnauthenticated = "unauthenticated"
+	// ErrorCodePermissionDenied indicates the API token is valid, but its
+	// role does not allow the request.
+	ErrorCodePermissionDenied = "permission_denied"
+	// ErrorCodeNotFound indicates the requested resource does not exist.
+	ErrorCodeNotFound = "not_found"
+	// ErrorCodeRouteNotFound indicates no route matches the request path; it
+	// is distinct from `ErrorCodeNotFound` so that a misconfigured client
+	// (e.g. one with a path prefix in its address) does not conclude that
+	// every resource it asks for is gone.
+	ErrorCodeRouteNotFound = "route_not_found"
+	// ErrorCodeMethodNotAllowed indicates an unsupported HTTP method.
+	ErrorCodeMethodNotAllowed = "method_not_allowed"
+	// ErrorCodeAlreadyExists indicates a uniqueness conflict.
+	ErrorCodeAlreadyExists = "already_exists"
+	// ErrorCodeHasDependents indicates a resource cannot be removed because
+	// other resources still refer to it.
+	ErrorCodeHasDependents = "has_dependents"
+	// ErrorCodeInvalidReference indicates the request refers to a resource
+	// that does not exist.
+	ErrorCodeInvalidReference = "invalid_reference"
+	// ErrorCodeFailedPrecondition indicates an `If-Match` precondition did not
+	// hold, i.e. the resource was modified since it was last read.
+	ErrorCodeFailedPrecondition = "failed_precondition"
+	// ErrorCodeRequestInProgress indicates a request with the same
+	// idempotency key is still being handled; it is safe to retry later.
+	ErrorCodeRequestInProgress = "request_in_progress"
+	// ErrorCodeDeadlineExceeded indicates the request did not complete
+	// before its deadline.
+	ErrorCodeDeadlineExceeded = "deadline_exceeded"
+	// ErrorCodeUnimplemented indicates the request needs a feature that is
+	// not available with the configured storage.
+	ErrorCodeUnimplemented = "unimplemented"
+	// ErrorCodeInternal indicates an unexpected server-side failure.
+	ErrorCodeInternal = "internal"
+)
+
+// errorResponse is the JSON body sent for every failed request.
+type errorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Field     string `json:"field,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, req *http.Request, status int, code, message, field string) {
+	setRequestError(req.Context(), errors.New(message))
+	er := errorResponse{
+		Code:      code,
+		Message:   message,
+		Field:     field,
+		RequestID: getRequestID(req.Context()),
 	}
+	// NOTE: Marshaling a struct of strings cannot fail.
+	responseBody, _ := json.Marshal(er)
 
 	w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-	w.WriteHeader(http.StatusMethodNotAllowed)
-	fmt.Fprintf(w, `{"error": "not allowed"}`+"\n")
-	return true
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "%s\n", responseBody)
+}
+
+func invalidArgument(w http.ResponseWriter, req *http.Request, message, field string) {
+	writeError(w, req, http.StatusBadRequest, ErrorCodeInvalidArgument, message, field)
+}
+
+func unimplemented(w http.ResponseWriter, req *http.Request) {
+	writeError(w, req, http.StatusNotImplemented, ErrorCodeUnimplemented, "requires database storage", "")
+}
+
+func internalError(w http.ResponseWriter, req *http.Request, message string) {
+	writeError(w, req, http.StatusInternalServerError, ErrorCodeInternal, message, "")
+}
+
+// modelError writes an error response for an error returned from the `model`
+// package, mapping the sentinel errors there onto HTTP status codes.
+func modelError(w http.ResponseWriter, req *http.Request, err error, message string) {
+	setRequestError(req.Context(), err)
+	status, er := modelErrorResponse(err, message)
+	writeError(w, req, status, er.Code, er.Message, er.Field)
+}
+
+// modelErrorResponse maps an error returned from the `model` package onto an
+// HTTP status code and error response body (without a request ID).
+func modelErrorResponse(err error, message string) (int, errorResponse) {
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		return http.StatusNotFound, errorResponse{Code: ErrorCodeNotFound, Message: message + ": not found"}
+	case errors.Is(err, model.ErrConflict):
+		return http.StatusConflict, errorResponse{Code: ErrorCodeAlreadyExists, Message: message + ": already exists"}
+	case errors.Is(err, model.ErrHasBooks):
+		return http.StatusConflict, errorResponse{Code: ErrorCodeHasDependents, Message: message + ": author still has books"}
+	case errors.Is(err, model.ErrInvalidReference):
+		return http.StatusUnprocessableEntity, errorResponse{Code: ErrorCodeInvalidReference, Message: message + ": author does not exist", Field: "author_id"}
+	case errors.Is(err, model.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, errorResponse{Code: ErrorCodeFailedPrecondition, Message: message + ": has been modified", Field: HeaderIfMatch}
+	case errors.Is(err, model.ErrTimeout):
+		return http.StatusGatewayTimeout, errorResponse{Code: ErrorCodeDeadlineExceeded, Message: message + ": timed out"}
+	default:
+		return http.StatusInternalServerError, errorResponse{Code: ErrorCodeInternal, Message: message}
+	}
 }
 
 func contentTypeNotJSON(w http.ResponseWriter, req *http.Request) bool {
@@ -36,16 +135,21 @@ func contentTypeNotJSON(w http.ResponseWriter, req *http.Request) bool {
 		return false
 	}
 
-	w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-	w.WriteHeader(http.StatusBadRequest)
-	fmt.Fprintf(w, `{"error": "JSON requests only"}`+"\n")
+	invalidArgument(w, req, "JSON requests only", HeaderContentType)
 	return true
 }
 
-func notFound(w http.ResponseWriter) {
-	w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-	w.WriteHeader(http.StatusNotFound)
-	fmt.Fprintf(w, `{"error": "not found"}`+"\n")
+func unauthenticated(w http.ResponseWriter, req *http.Request, message string) {
+	w.Header().Set(HeaderWWWAuthenticate, `Bearer realm="books"`)
+	writeError(w, req, http.StatusUnauthorized, ErrorCodeUnauthenticated, message, "")
+}
+
+func permissionDenied(w http.ResponseWriter, req *http.Request, message string) {
+	writeError(w, req, http.StatusForbidden, ErrorCodePermissionDenied, message, "")
+}
+
+func routeNotFound(w http.ResponseWriter, req *http.Request) {
+	writeError(w, req, http.StatusNotFound, ErrorCodeRouteNotFound, "no route matches the request path", "")
 }
 
 func invalidJSONBody(w http.ResponseWriter, req *http.Request, v interface{}) bool {
@@ -57,26 +161,27 @@ func invalidJSONBody(w http.ResponseWriter, req *http.Request, v interface{}) bo
 		return false
 	}
 
-	w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-	w.WriteHeader(http.StatusBadRequest)
-	fmt.Fprintf(w, `{"error": "invalid request body"}`+"\n")
+	field := ""
+	var ute *json.UnmarshalTypeError
+	if errors.As(err, &ute) {
+		field = ute.Field
+	}
+	invalidArgument(w, req, "invalid request body", field)
 	return true
 }
 
-func serializeJSONResponse(w http.ResponseWriter, v interface{}) bool {
+func serializeJSONResponse(w http.ResponseWriter, req *http.Request, v interface{}) bool {
 	// NOTE: It'd be nice to just do `json.NewEncoder(w).Encode(v)`, but we
 	//       can't do this because we need to write the status code header before
 	//       writing the response body.
 	responseBody, err := json.Marshal(v)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "could not serialize response"}`+"\n")
+		internalError(w, req, "could not serialize response")
 		return false
 	}
 
 	w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
 	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, string(responseBody)+"\n")
+	fmt.Fprintf(w, "%s\n", responseBody)
 	return true
 }
@@ -0,0 +1,49 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// formatETag formats a row version as a (strong) entity tag.
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// invalidIfMatch parses the `If-Match` header into a row version. A missing
+// header or `*` produce a version of zero, i.e. an unconditional write. Only
+// a single entity tag is supported; weak tags are rejected since `If-Match`
+// requires a strong comparison.
+func invalidIfMatch(w http.ResponseWriter, req *http.Request, version *int64) bool {
+	ifMatch := strings.TrimSpace(req.Header.Get(HeaderIfMatch))
+	if ifMatch == "" || ifMatch == "*" {
+		*version = 0
+		return false
+	}
+
+	if len(ifMatch) >= 2 && strings.HasPrefix(ifMatch, `"`) && strings.HasSuffix(ifMatch, `"`) {
+		parsed, err := strconv.ParseInt(ifMatch[1:len(ifMatch)-1], 10, 64)
+		if err == nil && parsed > 0 {
+			*version = parsed
+			return false
+		}
+	}
+
+	invalidArgument(w, req, "invalid If-Match header; expected a single entity tag", HeaderIfMatch)
+	return true
+}
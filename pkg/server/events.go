@@ -0,0 +1,288 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+const (
+	// eventsPath is the route for the event stream.
+	eventsPath = "/v1alpha1/events"
+	// eventsBatchSize is the most events read from the database at once.
+	eventsBatchSize = 100
+	// eventsHeartbeat is how often an idle stream sends a comment (so that
+	// proxies do not close it) and re-checks for new events, in case a
+	// notification was missed.
+	eventsHeartbeat = 15 * time.Second
+	// eventsListenRetry is how long to wait before listening again after
+	// the notification connection fails.
+	eventsListenRetry = 5 * time.Second
+	// eventsRetry is the reconnection delay suggested to clients.
+	eventsRetry = time.Second
+)
+
+// eventTypes maps an audit log action to the (past tense) name used in an
+// event type, e.g. `author.created`.
+var eventTypes = map[string]string{
+	"create":  "created",
+	"update":  "updated",
+	"delete":  "deleted",
+	"restore": "restored",
+	"purge":   "purged",
+}
+
+// eventBroker wakes every open event stream on this server when new audit
+// events are committed, by this or any other server.
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+	// done is closed when the broker stops listening, i.e. when the server
+	// is shutting down, so that open streams end.
+	done chan struct{}
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{
+		subscribers: map[chan struct{}]struct{}{},
+		done:        make(chan struct{}),
+	}
+}
+
+// subscribe returns a channel that receives a value whenever there may be
+// new events. Wake-ups are coalesced, so the subscriber must read every new
+// event from the database when woken.
+func (eb *eventBroker) subscribe() chan struct{} {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	wake := make(chan struct{}, 1)
+	eb.subscribers[wake] = struct{}{}
+	return wake
+}
+
+func (eb *eventBroker) unsubscribe(wake chan struct{}) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	delete(eb.subscribers, wake)
+}
+
+func (eb *eventBroker) publish() {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	for wake := range eb.subscribers {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// listen publishes a wake-up for every audit log notification until `ctx` is
+// done, listening again (after a short wait) if the connection fails.
+func (eb *eventBroker) listen(ctx context.Context) {
+	defer close(eb.done)
+
+	pool := model.GetPool(ctx)
+	if pool == nil {
+		// NOTE: With `StorageMemory` there is no audit log to listen to.
+		<-ctx.Done()
+		return
+	}
+	for {
+		_ = model.ListenForAuditEvents(ctx, pool, eb.publish)
+		if ctx.Err() != nil {
+			return
+		}
+		// NOTE: Notifications may have been missed while not listening.
+		eb.publish()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventsListenRetry):
+		}
+	}
+}
+
+// streamEvents returns a handler that streams audit events as server-sent
+// events. A client that sends `Last-Event-ID` (or the `last_event_id` query
+// parameter) receives every event after it; otherwise only new events are
+// sent.
+//
+// The stream starts with an `id:` line carrying the position it starts from,
+// so a client that reconnects before any event arrives still resumes from
+// there. The stream is exempt from the request deadline and the server read
+// and write timeouts; it only ends when the client goes away or the server
+// shuts down.
+func streamEvents(eb *eventBroker) handleFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		raw := req.Header.Get(HeaderLastEventID)
+		if raw == "" {
+			raw = req.URL.Query().Get("last_event_id")
+		}
+		var last int64
+		var err error
+		if raw != "" {
+			last, err = strconv.ParseInt(raw, 10, 64)
+			if err != nil || last < 0 {
+				invalidArgument(w, req, "invalid last event ID", "last_event_id")
+				return
+			}
+		}
+
+		rc := http.NewResponseController(w)
+		err = clearDeadlines(rc)
+		if err != nil {
+			internalError(w, req, "streaming is not supported")
+			return
+		}
+
+		// NOTE: Subscribe before reading the latest ID so that an event
+		//       committed in between still wakes this stream.
+		wake := eb.subscribe()
+		defer eb.unsubscribe(wake)
+
+		ctx := req.Context()
+		pool := model.GetPool(ctx)
+		if raw == "" {
+			last, err = model.GetLatestAuditEventID(ctx, pool)
+			if err != nil {
+				modelError(w, req, err, "failed to get latest event")
+				return
+			}
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeEventStream)
+		w.Header().Set(HeaderCacheControl, "no-cache")
+		w.WriteHeader(http.StatusOK)
+		_, err = fmt.Fprintf(w, "retry: %d\nid: %d\n\n", eventsRetry.Milliseconds(), last)
+		if err != nil {
+			return
+		}
+		err = rc.Flush()
+		if err != nil {
+			return
+		}
+
+		feed := model.NewAuditFeed(last)
+		heartbeat := time.NewTicker(eventsHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			// NOTE: Once the stream has started there is no way to report
+			//       an error; ending the stream makes the client resume.
+			err = writeEvents(ctx, w, pool, feed)
+			if err != nil {
+				return
+			}
+			err = rc.Flush()
+			if err != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-eb.done:
+				return
+			case <-wake:
+			case <-heartbeat.C:
+				_, err = io.WriteString(w, ": heartbeat\n\n")
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// clearDeadlines removes the server read and write deadlines for a
+// long-lived response. Without clearing the read deadline, the server
+// cancels the request context once it passes.
+func clearDeadlines(rc *http.ResponseController) error {
+	err := rc.SetReadDeadline(time.Time{})
+	if err != nil {
+		return err
+	}
+	return rc.SetWriteDeadline(time.Time{})
+}
+
+// writeEvents writes every audit event that `feed` has ready to `w`.
+func writeEvents(ctx context.Context, w io.Writer, pool *sql.DB, feed *model.AuditFeed) error {
+	for {
+		eventsDB, err := feed.Next(ctx, pool, eventsBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range eventsDB {
+			data, err := json.Marshal(dbAuditEventToEvent(&e))
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, eventType(&e), data)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(eventsDB) < eventsBatchSize {
+			return nil
+		}
+	}
+}
+
+type eventResponse struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func eventType(e *model.AuditEvent) string {
+	return e.EntityType + "." + eventTypes[e.Action]
+}
+
+// dbAuditEventToEvent converts an audit event to a change feed event; the
+// data is the row after the change, or before it for a purge. Unlike the
+// audit log, the actor is not included.
+func dbAuditEventToEvent(e *model.AuditEvent) eventResponse {
+	data := e.After
+	if data == nil {
+		data = e.Before
+	}
+	return eventResponse{
+		ID:         e.ID,
+		Type:       eventType(e),
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID.String(),
+		OccurredAt: e.OccurredAt.UTC(),
+		Data:       data,
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `getAudit` satisfies `handleFunc`.
+var (
+	_ handleFunc = getAudit
+)
+
+func getAudit(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	aq := model.AuditQuery{
+		EntityType: q.Get("entity_type"),
+		Actor:      q.Get("actor"),
+	}
+
+	if aq.EntityType != "" && aq.EntityType != "author" && aq.EntityType != "book" {
+		invalidArgument(w, req, "invalid entity type", "entity_type")
+		return
+	}
+	if idStr := q.Get("entity_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			invalidArgument(w, req, "invalid ID", "entity_id")
+			return
+		}
+		aq.EntityID = &id
+	}
+
+	occurredAfter, err := parseTimeParam(q, "occurred_after")
+	if err != nil {
+		invalidArgument(w, req, "invalid occurred after", "occurred_after")
+		return
+	}
+	aq.OccurredAfter = occurredAfter
+	occurredBefore, err := parseTimeParam(q, "occurred_before")
+	if err != nil {
+		invalidArgument(w, req, "invalid occurred before", "occurred_before")
+		return
+	}
+	aq.OccurredBefore = occurredBefore
+
+	pageSize, err := parsePageSize(q)
+	if err != nil {
+		invalidArgument(w, req, "invalid page size", "page_size")
+		return
+	}
+	after, err := parseAuditPageToken(q)
+	if err != nil {
+		invalidArgument(w, req, "invalid page token", "page_token")
+		return
+	}
+
+	ctx := req.Context()
+	pool := model.GetPool(ctx)
+	// NOTE: Fetch one extra row to determine if there is a next page.
+	eventsDB, err := model.GetAuditEvents(ctx, pool, aq, pageSize+1, after)
+	if err != nil {
+		modelError(w, req, err, "failed to get audit events")
+		return
+	}
+
+	nextPageToken := ""
+	if len(eventsDB) > pageSize {
+		eventsDB = eventsDB[:pageSize]
+		nextPageToken, err = auditNextPageToken(eventsDB[pageSize-1])
+		if err != nil {
+			internalError(w, req, "could not create page token")
+			return
+		}
+	}
+
+	events := make([]auditEventResponse, len(eventsDB))
+	for i, e := range eventsDB {
+		events[i] = dbAuditEventToResult(&e)
+	}
+	response := auditResponse{Events: events, NextPageToken: nextPageToken}
+	serializeJSONResponse(w, req, response)
+}
+
+type auditEventResponse struct {
+	ID         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	APITokenID string          `json:"api_token_id,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+type auditResponse struct {
+	Events        []auditEventResponse `json:"events"`
+	NextPageToken string               `json:"next_page_token,omitempty"`
+}
+
+func dbAuditEventToResult(e *model.AuditEvent) auditEventResponse {
+	aer := auditEventResponse{
+		ID:         e.ID,
+		OccurredAt: e.OccurredAt.UTC(),
+		Actor:      e.Actor,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID.String(),
+		Before:     e.Before,
+		After:      e.After,
+	}
+	if e.APITokenID != nil {
+		aer.APITokenID = e.APITokenID.String()
+	}
+	return aer
+}
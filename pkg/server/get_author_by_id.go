@@ -15,72 +15,30 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
-	"strings"
-
-	"github.com/google/uuid"
 
 	"github.com/dhermes/example-terraform-provider/pkg/model"
 )
 
 // NOTE: Ensure that
-//       * `authorByIDDispatch` satisfies `handleFunc`.
-//       * `getAuthor` satisfies `handleFunc`.
+//       * `getAuthorByID` satisfies `handleFunc`.
 var (
-	_ handleFunc = authorByIDDispatch
 	_ handleFunc = getAuthorByID
 )
 
-// authorByIDDispatch dispatches to `getAuthorByID()` for a `GET` request and
-// to `deleteAuthorByID()` for a `DELETE` request.
-func authorByIDDispatch(w http.ResponseWriter, req *http.Request) {
-	if req.Method == http.MethodGet {
-		getAuthorByID(w, req)
-		return
-	}
-
-	if req.Method == http.MethodDelete {
-		deleteAuthorByID(w, req)
-		return
-	}
-
-	notAllowed(w, req, req.Method+"-mismatch") // Ensure the method is wrong
-}
-
 func getAuthorByID(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodGet) {
-		return
-	}
-	if contentTypeNotJSON(w, req) {
-		return
-	}
-	if !strings.HasPrefix(req.URL.Path, "/v1alpha1/authors/") {
-		notFound(w)
-		return
-	}
-
-	suffix := strings.TrimPrefix(req.URL.Path, "/v1alpha1/authors/")
-	id, err := uuid.Parse(suffix)
-	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "invalid ID"}`+"\n")
-		return
-	}
+	id := uuidParam(req, "author_id")
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	a, err := model.GetAuthorByID(ctx, pool, id)
+	store := model.GetStore(ctx)
+	a, err := store.GetAuthorByID(ctx, id)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		// TODO: Consider supporting a 404 here
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to get author by ID"}`+"\n")
+		modelError(w, req, err, "failed to get author by ID")
 		return
 	}
 
-	serializeJSONResponse(w, dbAuthorToResult(a))
+	w.Header().Set(HeaderETag, formatETag(a.Version))
+	serializeJSONResponse(w, req, dbAuthorToResult(a))
 }
 
 type authorResponse struct {
@@ -88,6 +46,7 @@ type authorResponse struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	BookCount uint32 `json:"book_count"`
+	Version   int64  `json:"version"`
 }
 
 func dbAuthorToResult(a *model.Author) authorResponse {
@@ -96,5 +55,6 @@ func dbAuthorToResult(a *model.Author) authorResponse {
 		FirstName: a.FirstName,
 		LastName:  a.LastName,
 		BookCount: a.BookCount,
+		Version:   a.Version,
 	}
 }
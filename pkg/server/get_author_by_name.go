@@ -15,7 +15,6 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/dhermes/example-terraform-provider/pkg/model"
@@ -28,38 +27,27 @@ var (
 )
 
 func getAuthorByName(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodGet) {
-		return
-	}
-	if contentTypeNotJSON(w, req) {
-		return
-	}
-	if req.URL.Path != "/v1alpha1/author" {
-		notFound(w)
-		return
-	}
-
 	// NOTE: We could be much more restrictive here with known / unkown inputs.
 	q := req.URL.Query()
 	firstName := q.Get("first_name")
 	lastName := q.Get("last_name")
 	if firstName == "" || lastName == "" {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "missing author first or last name query parameter"}`+"\n")
+		field := "first_name"
+		if firstName != "" {
+			field = "last_name"
+		}
+		invalidArgument(w, req, "missing author first or last name query parameter", field)
 		return
 	}
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	a, err := model.GetAuthorByName(ctx, pool, firstName, lastName)
+	store := model.GetStore(ctx)
+	a, err := store.GetAuthorByName(ctx, firstName, lastName)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		// TODO: Consider supporting a 404 here
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to get author by name"}`+"\n")
+		modelError(w, req, err, "failed to get author by name")
 		return
 	}
 
-	serializeJSONResponse(w, dbAuthorToResult(a))
+	w.Header().Set(HeaderETag, formatETag(a.Version))
+	serializeJSONResponse(w, req, dbAuthorToResult(a))
 }
@@ -15,7 +15,6 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/dhermes/example-terraform-provider/pkg/model"
@@ -24,39 +23,60 @@ import (
 // NOTE: Ensure that
 //       * `getAuthors` satisfies `handleFunc`.
 var (
-	_ handleFunc = getAuthorByID
+	_ handleFunc = getAuthors
 )
 
 func getAuthors(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodGet) {
+	q := req.URL.Query()
+	pageSize, err := parsePageSize(q)
+	if err != nil {
+		invalidArgument(w, req, "invalid page size", "page_size")
 		return
 	}
-	if contentTypeNotJSON(w, req) {
+	ob, err := parseOrderBy(q, string(model.AuthorOrderLastName), string(model.AuthorOrderBookCount))
+	if err != nil {
+		invalidArgument(w, req, "invalid order by", "order_by")
 		return
 	}
-	if req.URL.Path != "/v1alpha1/authors" {
-		notFound(w)
+	after, err := parseAuthorsPageToken(q, ob)
+	if err != nil {
+		invalidArgument(w, req, "invalid page token", "page_token")
 		return
 	}
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	authorsDB, err := model.GetAllAuthors(ctx, pool)
+	store := model.GetStore(ctx)
+	// NOTE: Fetch one extra row to determine if there is a next page.
+	aq := model.AuthorQuery{
+		NamePrefix: q.Get("name_prefix"),
+		OrderBy:    model.AuthorOrder(ob.Field),
+		Descending: ob.Descending,
+	}
+	authorsDB, err := store.GetAuthors(ctx, aq, pageSize+1, after)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to get all authors"}`+"\n")
+		modelError(w, req, err, "failed to get authors")
 		return
 	}
 
+	nextPageToken := ""
+	if len(authorsDB) > pageSize {
+		authorsDB = authorsDB[:pageSize]
+		nextPageToken, err = authorsNextPageToken(authorsDB[pageSize-1], ob)
+		if err != nil {
+			internalError(w, req, "could not create page token")
+			return
+		}
+	}
+
 	authors := make([]authorResponse, len(authorsDB))
 	for i, a := range authorsDB {
 		authors[i] = dbAuthorToResult(&a)
 	}
-	response := authorsResponse{Authors: authors}
-	serializeJSONResponse(w, response)
+	response := authorsResponse{Authors: authors, NextPageToken: nextPageToken}
+	serializeJSONResponse(w, req, response)
 }
 
 type authorsResponse struct {
-	Authors []authorResponse `json:"authors"`
+	Authors       []authorResponse `json:"authors"`
+	NextPageToken string           `json:"next_page_token,omitempty"`
 }
@@ -15,73 +15,31 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
-	"github.com/google/uuid"
-
 	"github.com/dhermes/example-terraform-provider/pkg/model"
 )
 
 // NOTE: Ensure that
-//       * `bookByIDDispatch` satisfies `handleFunc`.
-//       * `getBook` satisfies `handleFunc`.
+//       * `getBookByID` satisfies `handleFunc`.
 var (
-	_ handleFunc = bookByIDDispatch
 	_ handleFunc = getBookByID
 )
 
-// bookByIDDispatch dispatches to `getBookByID()` for a `GET` request and
-// to `deleteBookByID()` for a `DELETE` request.
-func bookByIDDispatch(w http.ResponseWriter, req *http.Request) {
-	if req.Method == http.MethodGet {
-		getBookByID(w, req)
-		return
-	}
-
-	if req.Method == http.MethodDelete {
-		deleteBookByID(w, req)
-		return
-	}
-
-	notAllowed(w, req, req.Method+"-mismatch") // Ensure the method is wrong
-}
-
 func getBookByID(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodGet) {
-		return
-	}
-	if contentTypeNotJSON(w, req) {
-		return
-	}
-	if !strings.HasPrefix(req.URL.Path, "/v1alpha1/books/") {
-		notFound(w)
-		return
-	}
-
-	suffix := strings.TrimPrefix(req.URL.Path, "/v1alpha1/books/")
-	id, err := uuid.Parse(suffix)
-	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "invalid ID"}`+"\n")
-		return
-	}
+	id := uuidParam(req, "book_id")
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	b, err := model.GetBookByID(ctx, pool, id)
+	store := model.GetStore(ctx)
+	b, err := store.GetBookByID(ctx, id)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		// TODO: Consider supporting a 404 here
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to get book by ID"}`+"\n")
+		modelError(w, req, err, "failed to get book by ID")
 		return
 	}
 
-	serializeJSONResponse(w, dbBookToResult(b))
+	w.Header().Set(HeaderETag, formatETag(b.Version))
+	serializeJSONResponse(w, req, dbBookToResult(b))
 }
 
 type bookResponse struct {
@@ -89,6 +47,7 @@ type bookResponse struct {
 	AuthorID    string     `json:"author_id"`
 	Title       string     `json:"title"`
 	PublishDate *time.Time `json:"publish_date,omitempty"`
+	Version     int64      `json:"version"`
 }
 
 func dbBookToResult(b *model.Book) bookResponse {
@@ -96,6 +55,7 @@ func dbBookToResult(b *model.Book) bookResponse {
 		ID:       b.ID.String(),
 		AuthorID: b.AuthorID.String(),
 		Title:    b.Title,
+		Version:  b.Version,
 	}
 	if b.PublishDate != nil {
 		t := b.PublishDate.UTC()
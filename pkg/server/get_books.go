@@ -15,8 +15,9 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -26,58 +27,104 @@ import (
 // NOTE: Ensure that
 //       * `getBooks` satisfies `handleFunc`.
 var (
-	_ handleFunc = getAuthorByID
+	_ handleFunc = getBooks
 )
 
 func getBooks(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodGet) {
-		return
+	// NOTE: We could be much more restrictive here with known / unkown inputs.
+	q := req.URL.Query()
+	bq := model.BookQuery{
+		TitleContains:    q.Get("title_contains"),
+		AuthorNamePrefix: q.Get("author_name_prefix"),
 	}
-	if contentTypeNotJSON(w, req) {
+
+	if idStr := q.Get("author_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			invalidArgument(w, req, "invalid ID", "author_id")
+			return
+		}
+		bq.AuthorID = &id
+	}
+
+	publishedAfter, err := parseTimeParam(q, "published_after")
+	if err != nil {
+		invalidArgument(w, req, "invalid published after", "published_after")
 		return
 	}
-	if req.URL.Path != "/v1alpha1/books" {
-		notFound(w)
+	bq.PublishedAfter = publishedAfter
+	publishedBefore, err := parseTimeParam(q, "published_before")
+	if err != nil {
+		invalidArgument(w, req, "invalid published before", "published_before")
 		return
 	}
+	bq.PublishedBefore = publishedBefore
 
-	// NOTE: We could be much more restrictive here with known / unkown inputs.
-	q := req.URL.Query()
-	idStr := q.Get("author_id")
-	if idStr == "" {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "missing author ID query parameter"}`+"\n")
+	pageSize, err := parsePageSize(q)
+	if err != nil {
+		invalidArgument(w, req, "invalid page size", "page_size")
 		return
 	}
-
-	id, err := uuid.Parse(idStr)
+	ob, err := parseOrderBy(q, string(model.BookOrderTitle), string(model.BookOrderPublishDate))
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "invalid ID"}`+"\n")
+		invalidArgument(w, req, "invalid order by", "order_by")
+		return
+	}
+	bq.OrderBy = model.BookOrder(ob.Field)
+	bq.Descending = ob.Descending
+	after, err := parseBooksPageToken(q, ob)
+	if err != nil {
+		invalidArgument(w, req, "invalid page token", "page_token")
 		return
 	}
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	booksDB, err := model.GetAllBooksByAuthor(ctx, pool, id)
+	store := model.GetStore(ctx)
+	// NOTE: Fetch one extra row to determine if there is a next page.
+	booksDB, err := store.GetBooks(ctx, bq, pageSize+1, after)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to get all books by author"}`+"\n")
+		modelError(w, req, err, "failed to get books")
 		return
 	}
 
+	nextPageToken := ""
+	if len(booksDB) > pageSize {
+		booksDB = booksDB[:pageSize]
+		nextPageToken, err = booksNextPageToken(booksDB[pageSize-1], ob)
+		if err != nil {
+			internalError(w, req, "could not create page token")
+			return
+		}
+	}
+
 	books := make([]bookResponse, len(booksDB))
 	for i, b := range booksDB {
 		books[i] = dbBookToResult(&b)
 	}
-	response := booksResponse{Books: books}
-	serializeJSONResponse(w, response)
-
+	response := booksResponse{Books: books, NextPageToken: nextPageToken}
+	serializeJSONResponse(w, req, response)
 }
 
 type booksResponse struct {
-	Books []bookResponse `json:"books"`
+	Books         []bookResponse `json:"books"`
+	NextPageToken string         `json:"next_page_token,omitempty"`
+}
+
+// parseTimeParam parses an (optional) timestamp query parameter; either a
+// date (`YYYY-MM-DD`, midnight UTC) or an RFC 3339 timestamp is accepted.
+func parseTimeParam(q url.Values, name string) (*time.Time, error) {
+	raw := q.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
 }
@@ -0,0 +1,82 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `getTrash` satisfies `handleFunc`.
+var (
+	_ handleFunc = getTrash
+)
+
+func getTrash(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	store := model.GetStore(ctx)
+	authorsDB, err := store.GetDeletedAuthors(ctx)
+	if err != nil {
+		modelError(w, req, err, "failed to get deleted authors")
+		return
+	}
+	booksDB, err := store.GetDeletedBooks(ctx)
+	if err != nil {
+		modelError(w, req, err, "failed to get deleted books")
+		return
+	}
+
+	response := trashResponse{
+		Authors: make([]trashAuthorResponse, len(authorsDB)),
+		Books:   make([]trashBookResponse, len(booksDB)),
+	}
+	for i, a := range authorsDB {
+		response.Authors[i] = trashAuthorResponse{
+			ID:        a.ID.String(),
+			FirstName: a.FirstName,
+			LastName:  a.LastName,
+			Version:   a.Version,
+			DeletedAt: a.DeletedAt.UTC(),
+		}
+	}
+	for i, b := range booksDB {
+		response.Books[i] = trashBookResponse{
+			bookResponse: dbBookToResult(&b),
+			DeletedAt:    b.DeletedAt.UTC(),
+		}
+	}
+	serializeJSONResponse(w, req, response)
+}
+
+type trashAuthorResponse struct {
+	ID        string    `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Version   int64     `json:"version"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type trashBookResponse struct {
+	bookResponse
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type trashResponse struct {
+	Authors []trashAuthorResponse `json:"authors"`
+	Books   []trashBookResponse   `json:"books"`
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `getWebhookDeadLetters` satisfies `handleFunc`.
+var (
+	_ handleFunc = getWebhookDeadLetters
+)
+
+// getWebhookDeadLetters lists the deliveries for a webhook that failed too
+// many times to be retried, most recent first.
+func getWebhookDeadLetters(w http.ResponseWriter, req *http.Request) {
+	id := uuidParam(req, "webhook_id")
+
+	q := req.URL.Query()
+	pageSize, err := parsePageSize(q)
+	if err != nil {
+		invalidArgument(w, req, "invalid page size", "page_size")
+		return
+	}
+
+	ctx := req.Context()
+	pool := model.GetPool(ctx)
+	deliveriesDB, err := model.GetWebhookDeadLetters(ctx, pool, id, pageSize)
+	if err != nil {
+		modelError(w, req, err, "failed to get webhook dead letters")
+		return
+	}
+
+	deadLetters := make([]deadLetterResponse, len(deliveriesDB))
+	for i, d := range deliveriesDB {
+		deadLetters[i] = deadLetterResponse{
+			ID:       d.ID,
+			Event:    dbAuditEventToEvent(&d.Event),
+			Attempts: d.Attempts,
+			DeadAt:   d.DeadAt.UTC(),
+		}
+		if d.LastError != nil {
+			deadLetters[i].LastError = *d.LastError
+		}
+	}
+	response := deadLettersResponse{DeadLetters: deadLetters}
+	serializeJSONResponse(w, req, response)
+}
+
+type deadLetterResponse struct {
+	ID        int64         `json:"id"`
+	Event     eventResponse `json:"event"`
+	Attempts  int           `json:"attempts"`
+	LastError string        `json:"last_error,omitempty"`
+	DeadAt    time.Time     `json:"dead_at"`
+}
+
+type deadLettersResponse struct {
+	DeadLetters []deadLetterResponse `json:"dead_letters"`
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `getWebhooks` satisfies `handleFunc`.
+var (
+	_ handleFunc = getWebhooks
+)
+
+func getWebhooks(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	pool := model.GetPool(ctx)
+	webhooksDB, err := model.GetWebhooks(ctx, pool)
+	if err != nil {
+		modelError(w, req, err, "failed to get webhooks")
+		return
+	}
+
+	webhooks := make([]webhookResponse, len(webhooksDB))
+	for i, wh := range webhooksDB {
+		webhooks[i] = dbWebhookToResult(&wh)
+	}
+	response := webhooksResponse{Webhooks: webhooks}
+	serializeJSONResponse(w, req, response)
+}
+
+type webhooksResponse struct {
+	Webhooks []webhookResponse `json:"webhooks"`
+}
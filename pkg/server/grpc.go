@@ -0,0 +1,309 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dhermes/example-terraform-provider/pkg/bookspb"
+)
+
+// NOTE: Ensure that
+//       * `*grpcServer` satisfies `bookspb.BooksServer`.
+//       * `*grpcResponse` satisfies `http.ResponseWriter`.
+var (
+	_ bookspb.BooksServer = (*grpcServer)(nil)
+	_ http.ResponseWriter = (*grpcResponse)(nil)
+)
+
+// grpcRequestHeaders are the HTTP request headers that are set from gRPC
+// metadata; the metadata keys are the header names in lower case.
+var grpcRequestHeaders = []string{
+	HeaderAuthorization,
+	HeaderRequestID,
+	HeaderIdempotencyKey,
+	HeaderActor,
+}
+
+// grpcResponseHeaders are the HTTP response headers that are sent as gRPC
+// header metadata.
+var grpcResponseHeaders = []string{
+	HeaderRequestID,
+	HeaderETag,
+	HeaderIdempotentReplayed,
+}
+
+// grpcCodes maps error codes onto gRPC status codes.
+var grpcCodes = map[string]codes.Code{
+	ErrorCodeInvalidArgument:    codes.InvalidArgument,
+	ErrorCodeUnauthenticated:    codes.Unauthenticated,
+	ErrorCodePermissionDenied:   codes.PermissionDenied,
+	ErrorCodeNotFound:           codes.NotFound,
+	ErrorCodeRouteNotFound:      codes.Unimplemented,
+	ErrorCodeMethodNotAllowed:   codes.Unimplemented,
+	ErrorCodeAlreadyExists:      codes.AlreadyExists,
+	ErrorCodeHasDependents:      codes.FailedPrecondition,
+	ErrorCodeInvalidReference:   codes.FailedPrecondition,
+	ErrorCodeFailedPrecondition: codes.FailedPrecondition,
+	ErrorCodeRequestInProgress:  codes.Aborted,
+	ErrorCodeDeadlineExceeded:   codes.DeadlineExceeded,
+	ErrorCodeUnimplemented:      codes.Unimplemented,
+	ErrorCodeInternal:           codes.Internal,
+}
+
+// grpcServer is the gRPC transport for the Books API.
+//
+// Every unary RPC is translated into the equivalent HTTP/JSON request and
+// handled in-process by `h`, the full HTTP handler (including authorization,
+// idempotency keys, the access log and metrics), so that the two transports
+// cannot drift apart. `Watch` is streamed natively rather than via the
+// server-sent events route.
+type grpcServer struct {
+	bookspb.UnimplementedBooksServer
+
+	h  http.Handler
+	eb *eventBroker
+}
+
+// newGRPCServer returns a gRPC server for the Books API. The RPC contexts
+// carry the values of `ctx`, e.g. the database pool, in the same way that
+// `http.Server.BaseContext` does for HTTP requests. If `tc` is not `nil`,
+// RPCs are served over TLS.
+func newGRPCServer(ctx context.Context, h http.Handler, eb *eventBroker, tc *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(rpcCtx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(serverContext{Context: rpcCtx, values: ctx}, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, serverStream{ServerStream: ss, ctx: serverContext{Context: ss.Context(), values: ctx}})
+		}),
+	}
+	if tc != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tc)))
+	}
+	s := grpc.NewServer(opts...)
+	bookspb.RegisterBooksServer(s, &grpcServer{h: h, eb: eb})
+	return s
+}
+
+// stopGRPCServer stops `s` gracefully, i.e. waits for pending RPCs, unless
+// `ctx` is done first.
+func stopGRPCServer(ctx context.Context, s *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
+
+// serverContext is an RPC context (deadline, cancellation and values) that
+// falls back to the values of the server context.
+type serverContext struct {
+	context.Context
+	values context.Context
+}
+
+// Value satisfies the `context.Context` interface.
+func (sc serverContext) Value(key interface{}) interface{} {
+	v := sc.Context.Value(key)
+	if v != nil {
+		return v
+	}
+	return sc.values.Value(key)
+}
+
+// serverStream replaces the context of a gRPC server stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context satisfies the `grpc.ServerStream` interface.
+func (ss serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+// grpcCall is the HTTP/JSON request equivalent to a unary RPC.
+type grpcCall struct {
+	Method string
+	Path   string
+	Query  url.Values
+	// Body is serialized as JSON, if set.
+	Body interface{}
+	// ContentType defaults to JSON.
+	ContentType string
+	// Version, if set, is sent as `If-Match`.
+	Version int64
+}
+
+// roundTrip handles `call` in-process and, if it succeeds, decodes the JSON
+// response into `v` (unless it is `nil`). A failed call is returned as a
+// gRPC status error.
+func (gs *grpcServer) roundTrip(ctx context.Context, call grpcCall, v interface{}) error {
+	target := call.Path
+	if len(call.Query) > 0 {
+		target += "?" + call.Query.Encode()
+	}
+	var body io.Reader = http.NoBody
+	if call.Body != nil {
+		asJSON, err := json.Marshal(call.Body)
+		if err != nil {
+			return grpcError(ctx, http.StatusBadRequest, errorResponse{Code: ErrorCodeInvalidArgument, Message: "invalid request body"})
+		}
+		body = bytes.NewReader(asJSON)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, call.Method, target, body)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	contentType := call.ContentType
+	if contentType == "" {
+		contentType = ContentTypeApplicationJSON
+	}
+	req.Header.Set(HeaderContentType, contentType)
+	if call.Version != 0 {
+		req.Header.Set(HeaderIfMatch, formatETag(call.Version))
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, name := range grpcRequestHeaders {
+		if values := md.Get(name); len(values) > 0 {
+			req.Header.Set(name, values[0])
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	resp := &grpcResponse{header: http.Header{}}
+	gs.h.ServeHTTP(resp, req)
+
+	header := metadata.MD{}
+	for _, name := range grpcResponseHeaders {
+		if value := resp.header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	_ = grpc.SetHeader(ctx, header)
+
+	if resp.status >= http.StatusMultipleChoices {
+		return grpcErrorBody(ctx, resp.status, resp.body.Bytes())
+	}
+	if v == nil {
+		return nil
+	}
+	err = json.Unmarshal(resp.body.Bytes(), v)
+	if err != nil {
+		return status.Error(codes.Internal, "could not deserialize response")
+	}
+	return nil
+}
+
+// grpcResponse records the response for an RPC that was handled as an
+// HTTP/JSON request.
+type grpcResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header satisfies the `http.ResponseWriter` interface.
+func (gr *grpcResponse) Header() http.Header {
+	return gr.header
+}
+
+// WriteHeader satisfies the `http.ResponseWriter` interface.
+func (gr *grpcResponse) WriteHeader(status int) {
+	if gr.status == 0 {
+		gr.status = status
+	}
+}
+
+// Write satisfies the `http.ResponseWriter` interface.
+func (gr *grpcResponse) Write(b []byte) (int, error) {
+	gr.WriteHeader(http.StatusOK)
+	return gr.body.Write(b)
+}
+
+// grpcError converts an error response into a gRPC status error.
+func grpcError(ctx context.Context, httpStatus int, er errorResponse) error {
+	// NOTE: Marshaling a struct of strings cannot fail.
+	body, _ := json.Marshal(er)
+	return grpcErrorBody(ctx, httpStatus, body)
+}
+
+// grpcErrorBody converts a failed HTTP/JSON response into a gRPC status error.
+// The status code is determined by the error code and the body is sent (as
+// is) in the `books-error-bin` trailer, so that e.g. the field and the failed
+// batch operations are available to gRPC clients.
+func grpcErrorBody(ctx context.Context, httpStatus int, body []byte) error {
+	er := errorResponse{}
+	_ = json.Unmarshal(body, &er)
+
+	code, ok := grpcCodes[er.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	message := er.Message
+	if message == "" {
+		message = http.StatusText(httpStatus)
+	}
+
+	trailer := metadata.Pairs(
+		MetadataError, string(bytes.TrimSpace(body)),
+		MetadataStatus, strconv.Itoa(httpStatus),
+	)
+	_ = grpc.SetTrailer(ctx, trailer)
+	return status.Error(code, message)
+}
+
+// toTimestamp converts an optional time to a protobuf timestamp.
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// fromTimestamp converts an optional protobuf timestamp to a time.
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
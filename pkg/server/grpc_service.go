@@ -0,0 +1,624 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dhermes/example-terraform-provider/pkg/bookspb"
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// AddAuthor satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) AddAuthor(ctx context.Context, a *bookspb.Author) (*bookspb.AddAuthorResponse, error) {
+	body := map[string]interface{}{"first_name": a.FirstName, "last_name": a.LastName}
+	setNonEmpty(body, "id", a.Id)
+	call := grpcCall{Method: http.MethodPost, Path: "/v1alpha1/author", Body: body}
+
+	var aar addAuthorResponse
+	err := gs.roundTrip(ctx, call, &aar)
+	if err != nil {
+		return nil, err
+	}
+	return &bookspb.AddAuthorResponse{AuthorId: aar.AuthorID}, nil
+}
+
+// UpdateAuthor satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) UpdateAuthor(ctx context.Context, a *bookspb.Author) (*emptypb.Empty, error) {
+	body := map[string]interface{}{"id": a.Id, "first_name": a.FirstName, "last_name": a.LastName}
+	call := grpcCall{Method: http.MethodPut, Path: "/v1alpha1/author", Body: body, Version: a.Version}
+	return &emptypb.Empty{}, gs.roundTrip(ctx, call, nil)
+}
+
+// PatchAuthor satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) PatchAuthor(ctx context.Context, par *bookspb.PatchAuthorRequest) (*emptypb.Empty, error) {
+	body := map[string]interface{}{}
+	if par.FirstName != nil {
+		body["first_name"] = *par.FirstName
+	}
+	if par.LastName != nil {
+		body["last_name"] = *par.LastName
+	}
+	call := grpcCall{
+		Method:      http.MethodPatch,
+		Path:        "/v1alpha1/authors/" + url.PathEscape(par.GetAuthorId()),
+		Body:        body,
+		ContentType: ContentTypeMergePatchJSON,
+		Version:     par.GetVersion(),
+	}
+	return &emptypb.Empty{}, gs.roundTrip(ctx, call, nil)
+}
+
+// GetAuthorByID satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) GetAuthorByID(ctx context.Context, gar *bookspb.GetAuthorByIDRequest) (*bookspb.Author, error) {
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/authors/" + url.PathEscape(gar.AuthorId)}
+
+	var ar authorResponse
+	err := gs.roundTrip(ctx, call, &ar)
+	if err != nil {
+		return nil, err
+	}
+	return authorToProto(ar), nil
+}
+
+// GetAuthorByName satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) GetAuthorByName(ctx context.Context, gar *bookspb.GetAuthorByNameRequest) (*bookspb.Author, error) {
+	q := url.Values{}
+	q.Set("first_name", gar.FirstName)
+	q.Set("last_name", gar.LastName)
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/author", Query: q}
+
+	var ar authorResponse
+	err := gs.roundTrip(ctx, call, &ar)
+	if err != nil {
+		return nil, err
+	}
+	return authorToProto(ar), nil
+}
+
+// GetAuthors satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) GetAuthors(ctx context.Context, gar *bookspb.GetAuthorsRequest) (*bookspb.GetAuthorsResponse, error) {
+	q := url.Values{}
+	setNonEmptyParam(q, "name_prefix", gar.NamePrefix)
+	setNonEmptyParam(q, "order_by", gar.OrderBy)
+	setPageParams(q, gar.PageSize, gar.PageToken)
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/authors", Query: q}
+
+	var ar authorsResponse
+	err := gs.roundTrip(ctx, call, &ar)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &bookspb.GetAuthorsResponse{
+		Authors:       make([]*bookspb.Author, len(ar.Authors)),
+		NextPageToken: ar.NextPageToken,
+	}
+	for i, a := range ar.Authors {
+		response.Authors[i] = authorToProto(a)
+	}
+	return response, nil
+}
+
+// DeleteAuthorByID satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) DeleteAuthorByID(ctx context.Context, dar *bookspb.DeleteAuthorRequest) (*bookspb.DeleteAuthorResponse, error) {
+	q := url.Values{}
+	if dar.Cascade {
+		q.Set("cascade", "true")
+	}
+	call := grpcCall{
+		Method:  http.MethodDelete,
+		Path:    "/v1alpha1/authors/" + url.PathEscape(dar.AuthorId),
+		Query:   q,
+		Version: dar.Version,
+	}
+
+	// NOTE: Only a cascading delete has a response body.
+	var response deleteAuthorResponse
+	var v interface{}
+	if dar.Cascade {
+		v = &response
+	}
+	err := gs.roundTrip(ctx, call, v)
+	if err != nil {
+		return nil, err
+	}
+	return &bookspb.DeleteAuthorResponse{BooksDeleted: response.BooksDeleted}, nil
+}
+
+// RestoreAuthorByID satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) RestoreAuthorByID(ctx context.Context, rar *bookspb.RestoreAuthorRequest) (*emptypb.Empty, error) {
+	call := grpcCall{Method: http.MethodPost, Path: "/v1alpha1/authors/" + url.PathEscape(rar.AuthorId) + ":restore"}
+	return &emptypb.Empty{}, gs.roundTrip(ctx, call, nil)
+}
+
+// AddBook satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) AddBook(ctx context.Context, b *bookspb.Book) (*bookspb.AddBookResponse, error) {
+	body := map[string]interface{}{"title": b.Title, "author_id": b.AuthorId}
+	setNonEmpty(body, "id", b.Id)
+	if b.PublishDate != nil {
+		body["publish_date"] = fromTimestamp(b.PublishDate)
+	}
+	call := grpcCall{Method: http.MethodPost, Path: "/v1alpha1/book", Body: body}
+
+	var abr addBookResponse
+	err := gs.roundTrip(ctx, call, &abr)
+	if err != nil {
+		return nil, err
+	}
+	return &bookspb.AddBookResponse{BookId: abr.BookID}, nil
+}
+
+// UpdateBook satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) UpdateBook(ctx context.Context, b *bookspb.Book) (*emptypb.Empty, error) {
+	body := map[string]interface{}{"id": b.Id, "title": b.Title, "author_id": b.AuthorId}
+	if b.PublishDate != nil {
+		body["publish_date"] = fromTimestamp(b.PublishDate)
+	}
+	call := grpcCall{Method: http.MethodPut, Path: "/v1alpha1/book", Body: body, Version: b.Version}
+	return &emptypb.Empty{}, gs.roundTrip(ctx, call, nil)
+}
+
+// PatchBook satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) PatchBook(ctx context.Context, pbr *bookspb.PatchBookRequest) (*emptypb.Empty, error) {
+	body := map[string]interface{}{}
+	if pbr.Title != nil {
+		body["title"] = *pbr.Title
+	}
+	if pbr.AuthorId != nil {
+		body["author_id"] = *pbr.AuthorId
+	}
+	if pbr.PublishDate != nil {
+		body["publish_date"] = fromTimestamp(pbr.PublishDate)
+	}
+	call := grpcCall{
+		Method:      http.MethodPatch,
+		Path:        "/v1alpha1/books/" + url.PathEscape(pbr.GetBookId()),
+		Body:        body,
+		ContentType: ContentTypeMergePatchJSON,
+		Version:     pbr.GetVersion(),
+	}
+	return &emptypb.Empty{}, gs.roundTrip(ctx, call, nil)
+}
+
+// GetBookByID satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) GetBookByID(ctx context.Context, gbr *bookspb.GetBookByIDRequest) (*bookspb.Book, error) {
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/books/" + url.PathEscape(gbr.BookId)}
+
+	var br bookResponse
+	err := gs.roundTrip(ctx, call, &br)
+	if err != nil {
+		return nil, err
+	}
+	return bookToProto(br), nil
+}
+
+// GetBooks satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) GetBooks(ctx context.Context, gbr *bookspb.GetBooksRequest) (*bookspb.GetBooksResponse, error) {
+	q := url.Values{}
+	setNonEmptyParam(q, "author_id", gbr.AuthorId)
+	setNonEmptyParam(q, "title_contains", gbr.TitleContains)
+	setNonEmptyParam(q, "author_name_prefix", gbr.AuthorNamePrefix)
+	setTimeParam(q, "published_after", gbr.PublishedAfter)
+	setTimeParam(q, "published_before", gbr.PublishedBefore)
+	setNonEmptyParam(q, "order_by", gbr.OrderBy)
+	setPageParams(q, gbr.PageSize, gbr.PageToken)
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/books", Query: q}
+
+	var br booksResponse
+	err := gs.roundTrip(ctx, call, &br)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &bookspb.GetBooksResponse{
+		Books:         make([]*bookspb.Book, len(br.Books)),
+		NextPageToken: br.NextPageToken,
+	}
+	for i, b := range br.Books {
+		response.Books[i] = bookToProto(b)
+	}
+	return response, nil
+}
+
+// DeleteBookByID satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) DeleteBookByID(ctx context.Context, dbr *bookspb.DeleteBookRequest) (*emptypb.Empty, error) {
+	call := grpcCall{Method: http.MethodDelete, Path: "/v1alpha1/books/" + url.PathEscape(dbr.BookId), Version: dbr.Version}
+	return &emptypb.Empty{}, gs.roundTrip(ctx, call, nil)
+}
+
+// RestoreBookByID satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) RestoreBookByID(ctx context.Context, rbr *bookspb.RestoreBookRequest) (*emptypb.Empty, error) {
+	call := grpcCall{Method: http.MethodPost, Path: "/v1alpha1/books/" + url.PathEscape(rbr.BookId) + ":restore"}
+	return &emptypb.Empty{}, gs.roundTrip(ctx, call, nil)
+}
+
+// GetTrash satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) GetTrash(ctx context.Context, _ *bookspb.GetTrashRequest) (*bookspb.GetTrashResponse, error) {
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/trash"}
+
+	var tr trashResponse
+	err := gs.roundTrip(ctx, call, &tr)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &bookspb.GetTrashResponse{
+		Authors: make([]*bookspb.Author, len(tr.Authors)),
+		Books:   make([]*bookspb.Book, len(tr.Books)),
+	}
+	for i, a := range tr.Authors {
+		response.Authors[i] = &bookspb.Author{
+			Id:        a.ID,
+			FirstName: a.FirstName,
+			LastName:  a.LastName,
+			Version:   a.Version,
+			DeletedAt: toTimestamp(&a.DeletedAt),
+		}
+	}
+	for i, b := range tr.Books {
+		response.Books[i] = bookToProto(b.bookResponse)
+		response.Books[i].DeletedAt = toTimestamp(&b.DeletedAt)
+	}
+	return response, nil
+}
+
+// GetAudit satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) GetAudit(ctx context.Context, gar *bookspb.GetAuditRequest) (*bookspb.GetAuditResponse, error) {
+	q := url.Values{}
+	setNonEmptyParam(q, "entity_type", gar.EntityType)
+	setNonEmptyParam(q, "entity_id", gar.EntityId)
+	setNonEmptyParam(q, "actor", gar.Actor)
+	setTimeParam(q, "occurred_after", gar.OccurredAfter)
+	setTimeParam(q, "occurred_before", gar.OccurredBefore)
+	setPageParams(q, gar.PageSize, gar.PageToken)
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/audit", Query: q}
+
+	var ar auditResponse
+	err := gs.roundTrip(ctx, call, &ar)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &bookspb.GetAuditResponse{
+		Events:        make([]*bookspb.AuditEvent, len(ar.Events)),
+		NextPageToken: ar.NextPageToken,
+	}
+	for i, e := range ar.Events {
+		response.Events[i] = &bookspb.AuditEvent{
+			Id:         e.ID,
+			OccurredAt: toTimestamp(&e.OccurredAt),
+			Actor:      e.Actor,
+			ApiTokenId: e.APITokenID,
+			Action:     e.Action,
+			EntityType: e.EntityType,
+			EntityId:   e.EntityID,
+			Before:     string(e.Before),
+			After:      string(e.After),
+		}
+	}
+	return response, nil
+}
+
+// Watch satisfies the `bookspb.BooksServer` interface. It streams every
+// event after `LastEventId` (or, if it is not set, every new event) until
+// the client cancels it or the server shuts down.
+func (gs *grpcServer) Watch(wr *bookspb.WatchRequest, stream bookspb.Books_WatchServer) error {
+	ctx := stream.Context()
+	err := authorizeRPC(ctx, permissionRead)
+	if err != nil {
+		return err
+	}
+	if wr.GetLastEventId() < 0 {
+		return grpcError(ctx, http.StatusBadRequest, errorResponse{Code: ErrorCodeInvalidArgument, Message: "invalid last event ID", Field: "last_event_id"})
+	}
+	pool := model.GetPool(ctx)
+	if pool == nil {
+		return grpcError(ctx, http.StatusNotImplemented, errorResponse{Code: ErrorCodeUnimplemented, Message: "requires database storage"})
+	}
+
+	// NOTE: Subscribe before reading the latest ID so that an event
+	//       committed in between still wakes this stream.
+	wake := gs.eb.subscribe()
+	defer gs.eb.unsubscribe(wake)
+
+	last := wr.GetLastEventId()
+	if wr.LastEventId == nil {
+		last, err = model.GetLatestAuditEventID(ctx, pool)
+		if err != nil {
+			return grpcModelError(ctx, err, "failed to get latest event")
+		}
+	}
+
+	// NOTE: The header is sent right away so that the client knows the
+	//       stream was accepted, even if there are no events yet.
+	err = stream.SendHeader(metadata.Pairs(
+		MetadataStatus, strconv.Itoa(http.StatusOK),
+		MetadataLastEventID, strconv.FormatInt(last, 10),
+	))
+	if err != nil {
+		return err
+	}
+
+	feed := model.NewAuditFeed(last)
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		err = sendEvents(ctx, stream, pool, feed)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-gs.eb.done:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-wake:
+		case <-heartbeat.C:
+		}
+	}
+}
+
+// sendEvents sends every audit event that `feed` has ready on `stream`.
+func sendEvents(ctx context.Context, stream bookspb.Books_WatchServer, pool *sql.DB, feed *model.AuditFeed) error {
+	for {
+		eventsDB, err := feed.Next(ctx, pool, eventsBatchSize)
+		if err != nil {
+			return grpcModelError(ctx, err, "failed to get events")
+		}
+
+		for _, e := range eventsDB {
+			err = stream.Send(eventToProto(dbAuditEventToEvent(&e)))
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(eventsDB) < eventsBatchSize {
+			return nil
+		}
+	}
+}
+
+// AddWebhook satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) AddWebhook(ctx context.Context, awr *bookspb.AddWebhookRequest) (*bookspb.AddWebhookResponse, error) {
+	body := addWebhookRequest{URL: awr.Url}
+	call := grpcCall{Method: http.MethodPost, Path: "/v1alpha1/webhooks", Body: body}
+
+	var response addWebhookResponse
+	err := gs.roundTrip(ctx, call, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &bookspb.AddWebhookResponse{Webhook: webhookToProto(response.webhookResponse), Secret: response.Secret}, nil
+}
+
+// GetWebhooks satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) GetWebhooks(ctx context.Context, _ *bookspb.GetWebhooksRequest) (*bookspb.GetWebhooksResponse, error) {
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/webhooks"}
+
+	var wr webhooksResponse
+	err := gs.roundTrip(ctx, call, &wr)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &bookspb.GetWebhooksResponse{Webhooks: make([]*bookspb.Webhook, len(wr.Webhooks))}
+	for i, wh := range wr.Webhooks {
+		response.Webhooks[i] = webhookToProto(wh)
+	}
+	return response, nil
+}
+
+// DeleteWebhookByID satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) DeleteWebhookByID(ctx context.Context, dwr *bookspb.DeleteWebhookRequest) (*emptypb.Empty, error) {
+	call := grpcCall{Method: http.MethodDelete, Path: "/v1alpha1/webhooks/" + url.PathEscape(dwr.WebhookId)}
+	return &emptypb.Empty{}, gs.roundTrip(ctx, call, nil)
+}
+
+// GetWebhookDeadLetters satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) GetWebhookDeadLetters(ctx context.Context, gwr *bookspb.GetWebhookDeadLettersRequest) (*bookspb.GetWebhookDeadLettersResponse, error) {
+	q := url.Values{}
+	setPageParams(q, gwr.PageSize, "")
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/webhooks/" + url.PathEscape(gwr.WebhookId) + "/dead_letters", Query: q}
+
+	var dr deadLettersResponse
+	err := gs.roundTrip(ctx, call, &dr)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &bookspb.GetWebhookDeadLettersResponse{DeadLetters: make([]*bookspb.DeadLetter, len(dr.DeadLetters))}
+	for i, dl := range dr.DeadLetters {
+		response.DeadLetters[i] = &bookspb.DeadLetter{
+			Id:        dl.ID,
+			Event:     eventToProto(dl.Event),
+			Attempts:  int32(dl.Attempts),
+			LastError: dl.LastError,
+			DeadAt:    toTimestamp(&dl.DeadAt),
+		}
+	}
+	return response, nil
+}
+
+// Batch satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) Batch(ctx context.Context, br *bookspb.BatchRequest) (*bookspb.BatchResponse, error) {
+	body := batchRequest{Operations: make([]batchOperation, len(br.Operations))}
+	for i, op := range br.Operations {
+		body.Operations[i] = batchOperation{
+			Action:   op.Action,
+			Resource: op.Resource,
+			Body:     json.RawMessage(op.Body),
+			Version:  op.Version,
+		}
+		if op.Body == "" || !json.Valid(body.Operations[i].Body) {
+			field := fmt.Sprintf("operations[%d].body", i)
+			return nil, grpcError(ctx, http.StatusBadRequest, errorResponse{Code: ErrorCodeInvalidArgument, Message: "invalid operation body", Field: field})
+		}
+	}
+	call := grpcCall{Method: http.MethodPost, Path: "/v1alpha1/batch", Body: body}
+
+	var bresp batchResponse
+	err := gs.roundTrip(ctx, call, &bresp)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &bookspb.BatchResponse{Results: make([]string, len(bresp.Results))}
+	for i, result := range bresp.Results {
+		asJSON, err := json.Marshal(result)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "could not serialize response")
+		}
+		response.Results[i] = string(asJSON)
+	}
+	return response, nil
+}
+
+// Search satisfies the `bookspb.BooksServer` interface.
+func (gs *grpcServer) Search(ctx context.Context, sr *bookspb.SearchRequest) (*bookspb.SearchResponse, error) {
+	q := url.Values{}
+	q.Set("q", sr.Query)
+	if sr.Limit != 0 {
+		q.Set("limit", strconv.Itoa(int(sr.Limit)))
+	}
+	call := grpcCall{Method: http.MethodGet, Path: "/v1alpha1/search", Query: q}
+
+	var sresp searchResponse
+	err := gs.roundTrip(ctx, call, &sresp)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &bookspb.SearchResponse{Results: make([]*bookspb.SearchResult, len(sresp.Results))}
+	for i, result := range sresp.Results {
+		response.Results[i] = &bookspb.SearchResult{
+			Kind:    result.Kind,
+			Id:      result.ID,
+			Snippet: result.Snippet,
+			Score:   result.Score,
+		}
+	}
+	return response, nil
+}
+
+// authorizeRPC authenticates an RPC via the `authorization` metadata and
+// checks that the role of the API token grants `p`; RPCs that are handled
+// via the HTTP/JSON routes are authorized there instead.
+func authorizeRPC(ctx context.Context, p permission) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorization := ""
+	if values := md.Get(HeaderAuthorization); len(values) > 0 {
+		authorization = values[0]
+	}
+
+	t, message, err := verifyAPIToken(ctx, authorization)
+	if err != nil {
+		return grpcModelError(ctx, err, "could not verify API token")
+	}
+	if t == nil {
+		return grpcError(ctx, http.StatusUnauthorized, errorResponse{Code: ErrorCodeUnauthenticated, Message: message})
+	}
+	if !hasPermission(t.Role, p) {
+		message := fmt.Sprintf("API token role %q does not have %q permission", t.Role, p)
+		return grpcError(ctx, http.StatusForbidden, errorResponse{Code: ErrorCodePermissionDenied, Message: message})
+	}
+	return nil
+}
+
+// grpcModelError converts an error returned from the `model` package into a
+// gRPC status error.
+func grpcModelError(ctx context.Context, err error, message string) error {
+	httpStatus, er := modelErrorResponse(err, message)
+	return grpcError(ctx, httpStatus, er)
+}
+
+func setNonEmpty(body map[string]interface{}, key, value string) {
+	if value != "" {
+		body[key] = value
+	}
+}
+
+func setNonEmptyParam(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+func setTimeParam(q url.Values, key string, ts *timestamppb.Timestamp) {
+	if ts != nil {
+		q.Set(key, ts.AsTime().Format(time.RFC3339Nano))
+	}
+}
+
+func setPageParams(q url.Values, pageSize int32, pageToken string) {
+	if pageSize != 0 {
+		q.Set("page_size", strconv.Itoa(int(pageSize)))
+	}
+	setNonEmptyParam(q, "page_token", pageToken)
+}
+
+func authorToProto(ar authorResponse) *bookspb.Author {
+	return &bookspb.Author{
+		Id:        ar.ID,
+		FirstName: ar.FirstName,
+		LastName:  ar.LastName,
+		BookCount: ar.BookCount,
+		Version:   ar.Version,
+	}
+}
+
+func bookToProto(br bookResponse) *bookspb.Book {
+	return &bookspb.Book{
+		Id:          br.ID,
+		Title:       br.Title,
+		AuthorId:    br.AuthorID,
+		PublishDate: toTimestamp(br.PublishDate),
+		Version:     br.Version,
+	}
+}
+
+func eventToProto(er eventResponse) *bookspb.Event {
+	return &bookspb.Event{
+		Id:         er.ID,
+		Type:       er.Type,
+		EntityType: er.EntityType,
+		EntityId:   er.EntityID,
+		OccurredAt: toTimestamp(&er.OccurredAt),
+		Data:       string(er.Data),
+	}
+}
+
+func webhookToProto(wr webhookResponse) *bookspb.Webhook {
+	return &bookspb.Webhook{
+		Id:        wr.ID,
+		Url:       wr.URL,
+		CreatedAt: toTimestamp(&wr.CreatedAt),
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+	"github.com/dhermes/example-terraform-provider/pkg/sqlmigrations"
+)
+
+// NOTE: Ensure that
+//       * `healthz` satisfies `handleFunc`.
+var (
+	_ handleFunc = healthz
+)
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+)
+
+// healthResponse is the JSON body sent by `/healthz` and `/readyz`.
+type healthResponse struct {
+	Status           string `json:"status"`
+	Revision         string `json:"revision,omitempty"`
+	ExpectedRevision string `json:"expected_revision,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+// healthz reports that the process is alive; it does not touch the database.
+func healthz(w http.ResponseWriter, req *http.Request) {
+	writeHealth(w, req, http.StatusOK, healthResponse{Status: healthStatusOK})
+}
+
+// readyz returns a handler that reports whether the server can serve
+// traffic: the database must be reachable and the latest revision applied
+// in `metadataTable` must not be behind the head of
+// `sqlmigrations.AllMigrations()`. A revision this server does not know is
+// assumed to be newer (e.g. applied for a newer server during a rolling
+// deploy) and so is ready; the revision is included in the response. With
+// `StorageMemory` there is no database, so the server is always ready.
+func readyz(metadataTable string) (handleFunc, error) {
+	migrations, err := sqlmigrations.AllMigrations("", "")
+	if err != nil {
+		return nil, err
+	}
+	revisions := migrations.Revisions()
+	head := revisions[len(revisions)-1]
+
+	h := func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		pool := model.GetPool(ctx)
+		if pool == nil {
+			writeHealth(w, req, http.StatusOK, healthResponse{Status: healthStatusOK})
+			return
+		}
+
+		hr := healthResponse{Status: healthStatusUnavailable, ExpectedRevision: head}
+
+		err := pool.PingContext(ctx)
+		if err != nil {
+			setRequestError(ctx, err)
+			hr.Reason = "database is unreachable"
+			writeHealth(w, req, http.StatusServiceUnavailable, hr)
+			return
+		}
+
+		revision, err := model.GetLatestRevision(ctx, pool, metadataTable)
+		if err != nil {
+			setRequestError(ctx, err)
+			hr.Reason = "could not determine schema revision"
+			writeHealth(w, req, http.StatusServiceUnavailable, hr)
+			return
+		}
+
+		hr.Revision = revision
+		if revision == "" || (revision != head && migrations.Get(revision) != nil) {
+			hr.Reason = "schema is behind"
+			setRequestError(ctx, errors.New(hr.Reason))
+			writeHealth(w, req, http.StatusServiceUnavailable, hr)
+			return
+		}
+
+		hr.Status = healthStatusOK
+		if revision != head {
+			hr.Reason = "schema revision is not known to this server"
+		}
+		writeHealth(w, req, http.StatusOK, hr)
+	}
+	return h, nil
+}
+
+func writeHealth(w http.ResponseWriter, _ *http.Request, status int, hr healthResponse) {
+	// NOTE: Marshaling a struct of strings cannot fail.
+	responseBody, _ := json.Marshal(hr)
+
+	w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "%s\n", responseBody)
+}
@@ -0,0 +1,174 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `*idempotencyRecorder` satisfies `http.ResponseWriter`.
+var (
+	_ http.ResponseWriter = (*idempotencyRecorder)(nil)
+)
+
+const (
+	// maxIdempotencyKeyLength is the longest `Idempotency-Key` accepted.
+	maxIdempotencyKeyLength = 255
+	// idempotencyCleanupTimeout bounds the (detached) queries that store or
+	// release an idempotency key after the handler has run.
+	idempotencyCleanupTimeout = 5 * time.Second
+)
+
+// idempotent returns a middleware that honors the `Idempotency-Key` header.
+// The first request with a key is handled as usual and its response is
+// stored; repeated requests with the same key (from the same API token)
+// within `ttl` get the stored response replayed instead of being handled
+// again. It must come after `requireAPIToken` in a chain.
+//
+// Server errors (5xx) are not stored, so a request that failed that way can
+// be retried with the same key.
+func idempotent(ttl time.Duration) middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			key := req.Header.Get(HeaderIdempotencyKey)
+			// NOTE: With `StorageMemory` there is nowhere to store keys, so
+			//       they are ignored.
+			if key == "" || model.GetPool(req.Context()) == nil {
+				h.ServeHTTP(w, req)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				invalidArgument(w, req, "idempotency key is too long", HeaderIdempotencyKey)
+				return
+			}
+
+			ctx := req.Context()
+			t := getAPIToken(ctx)
+			if t == nil {
+				unauthenticated(w, req, "missing API token")
+				return
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				invalidArgument(w, req, "could not read request body", "")
+				return
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			pool := model.GetPool(ctx)
+			ik := model.IdempotencyKey{APITokenID: t.ID, Key: key, RequestHash: requestHash(req, body)}
+			reserved, err := model.ReserveIdempotencyKey(ctx, pool, ik, time.Now().Add(-ttl))
+			if err != nil {
+				modelError(w, req, err, "failed to reserve idempotency key")
+				return
+			}
+			if !reserved {
+				replayIdempotent(w, req, ik)
+				return
+			}
+
+			ir := &idempotencyRecorder{ResponseWriter: w}
+			h.ServeHTTP(ir, req)
+
+			// NOTE: The request context may have been canceled (e.g. by the
+			//       request timeout) but the key must still be stored or
+			//       released, otherwise it stays "in progress" until it expires.
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), idempotencyCleanupTimeout)
+			defer cancel()
+			if ir.status == 0 {
+				ir.status = http.StatusOK
+			}
+			if ir.status >= http.StatusInternalServerError {
+				err = model.ReleaseIdempotencyKey(cleanupCtx, pool, t.ID, key)
+			} else {
+				ik.StatusCode = &ir.status
+				ik.ResponseBody = ir.body.Bytes()
+				err = model.CompleteIdempotencyKey(cleanupCtx, pool, ik)
+			}
+			setRequestError(ctx, err)
+		})
+	}
+}
+
+// replayIdempotent writes the stored response for an idempotency key that
+// has already been used.
+func replayIdempotent(w http.ResponseWriter, req *http.Request, ik model.IdempotencyKey) {
+	ctx := req.Context()
+	pool := model.GetPool(ctx)
+	stored, err := model.GetIdempotencyKey(ctx, pool, ik.APITokenID, ik.Key)
+	if err != nil {
+		modelError(w, req, err, "failed to get idempotency key")
+		return
+	}
+
+	if !bytes.Equal(stored.RequestHash, ik.RequestHash) {
+		writeError(w, req, http.StatusUnprocessableEntity, ErrorCodeInvalidArgument, "idempotency key was already used for a different request", HeaderIdempotencyKey)
+		return
+	}
+	if stored.StatusCode == nil {
+		writeError(w, req, http.StatusConflict, ErrorCodeRequestInProgress, "a request with this idempotency key is still in progress", HeaderIdempotencyKey)
+		return
+	}
+
+	w.Header().Set(HeaderIdempotentReplayed, "true")
+	if len(stored.ResponseBody) > 0 {
+		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
+	}
+	w.WriteHeader(*stored.StatusCode)
+	w.Write(stored.ResponseBody)
+}
+
+// requestHash identifies a request by its method, path and body, so that an
+// idempotency key can't be reused for a different request.
+func requestHash(req *http.Request, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// idempotencyRecorder wraps a response writer to capture the status code and
+// the body so they can be stored for an idempotency key.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader satisfies the `http.ResponseWriter` interface.
+func (ir *idempotencyRecorder) WriteHeader(status int) {
+	if ir.status == 0 {
+		ir.status = status
+	}
+	ir.ResponseWriter.WriteHeader(status)
+}
+
+// Write satisfies the `http.ResponseWriter` interface.
+func (ir *idempotencyRecorder) Write(b []byte) (int, error) {
+	if ir.status == 0 {
+		ir.status = http.StatusOK
+	}
+	ir.body.Write(b)
+	return ir.ResponseWriter.Write(b)
+}
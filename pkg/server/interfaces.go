@@ -19,3 +19,5 @@ import (
 )
 
 type handleFunc func(http.ResponseWriter, *http.Request)
+
+type middleware func(http.Handler) http.Handler
@@ -0,0 +1,189 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NOTE: Ensure that
+//       * `*statusRecorder` satisfies `http.ResponseWriter`.
+//       * `*statusRecorder` satisfies `http.Flusher`.
+var (
+	_ http.ResponseWriter = (*statusRecorder)(nil)
+	_ http.Flusher        = (*statusRecorder)(nil)
+)
+
+// requestInfo collects facts about a request as it moves through the
+// handler chain. Fields that are only known deeper in the chain (the matched
+// route, the API token and the underlying error) are filled in via
+// `setRequestRoute()`, `setRequestAPIToken()` and `setRequestError()`.
+type requestInfo struct {
+	Start      time.Time
+	Route      string
+	APITokenID uuid.UUID
+	Status     int
+	Bytes      int
+	Err        error
+}
+
+// requestObserver is notified once a request has been fully handled.
+type requestObserver func(req *http.Request, ri *requestInfo)
+
+type requestInfoKey struct{}
+
+// statusRecorder wraps a response writer to capture the status code and the
+// number of body bytes written.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// WriteHeader satisfies the `http.ResponseWriter` interface.
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Write satisfies the `http.ResponseWriter` interface.
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += n
+	return n, err
+}
+
+// Flush satisfies the `http.Flusher` interface (if the wrapped writer does).
+func (sr *statusRecorder) Flush() {
+	f, ok := sr.ResponseWriter.(http.Flusher)
+	if ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped response writer; it is used by
+// `http.ResponseController`.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// observeRequests returns a middleware that tracks a `requestInfo` for every
+// request and passes it to each observer once the request is complete.
+func observeRequests(observers ...requestObserver) middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ri := &requestInfo{Start: time.Now()}
+			sr := &statusRecorder{ResponseWriter: w}
+			ctx := context.WithValue(req.Context(), requestInfoKey{}, ri)
+
+			h.ServeHTTP(sr, req.WithContext(ctx))
+
+			ri.Status = sr.status
+			if ri.Status == 0 {
+				ri.Status = http.StatusOK
+			}
+			ri.Bytes = sr.bytes
+			for _, observe := range observers {
+				observe(req, ri)
+			}
+		})
+	}
+}
+
+// setRequestRoute records the matched route pattern for a request (if it is
+// being observed).
+func setRequestRoute(ctx context.Context, pattern string) {
+	ri, ok := ctx.Value(requestInfoKey{}).(*requestInfo)
+	if ok {
+		ri.Route = pattern
+	}
+}
+
+// setRequestAPIToken records the ID of the API token used to authenticate a
+// request (if it is being observed).
+func setRequestAPIToken(ctx context.Context, id uuid.UUID) {
+	ri, ok := ctx.Value(requestInfoKey{}).(*requestInfo)
+	if ok {
+		ri.APITokenID = id
+	}
+}
+
+// setRequestError records the underlying error for a request (if it is
+// being observed). The first error recorded wins, so the most specific error
+// should be recorded first.
+func setRequestError(ctx context.Context, err error) {
+	ri, ok := ctx.Value(requestInfoKey{}).(*requestInfo)
+	if ok && err != nil && ri.Err == nil {
+		ri.Err = err
+	}
+}
+
+// accessLogEntry is a single structured (JSON) access log line.
+type accessLogEntry struct {
+	Time      string  `json:"time"`
+	RequestID string  `json:"request_id"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Route     string  `json:"route,omitempty"`
+	TokenID   string  `json:"token_id,omitempty"`
+	Status    int     `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Bytes     int     `json:"bytes"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// accessLog returns a request observer that writes one JSON line per request
+// to `out`.
+func accessLog(out io.Writer) requestObserver {
+	mu := &sync.Mutex{}
+	return func(req *http.Request, ri *requestInfo) {
+		entry := accessLogEntry{
+			Time:      ri.Start.UTC().Format(time.RFC3339Nano),
+			RequestID: getRequestID(req.Context()),
+			Method:    req.Method,
+			Path:      req.URL.Path,
+			Route:     ri.Route,
+			Status:    ri.Status,
+			LatencyMS: float64(time.Since(ri.Start).Microseconds()) / 1000,
+			Bytes:     ri.Bytes,
+		}
+		if ri.APITokenID != uuid.Nil {
+			entry.TokenID = ri.APITokenID.String()
+		}
+		if ri.Err != nil {
+			entry.Error = ri.Err.Error()
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		out.Write(append(line, '\n'))
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// invalidMergePatch decodes an RFC 7396 JSON merge patch into `v`, which
+// should be a struct with pointer fields so that members absent from the
+// patch remain `nil`. Since none of the patchable fields can be removed, a
+// `null` member is rejected rather than treated as a deletion.
+func invalidMergePatch(w http.ResponseWriter, req *http.Request, v interface{}) bool {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		invalidArgument(w, req, "could not read request body", "")
+		return true
+	}
+
+	var members map[string]json.RawMessage
+	err = json.Unmarshal(body, &members)
+	if err != nil || members == nil {
+		invalidArgument(w, req, "merge patch must be a JSON object", "")
+		return true
+	}
+	for name, value := range members {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			invalidArgument(w, req, "merge patch cannot remove a required field", name)
+			return true
+		}
+	}
+
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.DisallowUnknownFields()
+	err = d.Decode(v)
+	if err == nil {
+		return false
+	}
+
+	field := ""
+	var ute *json.UnmarshalTypeError
+	if errors.As(err, &ute) {
+		field = ute.Field
+	}
+	invalidArgument(w, req, "invalid merge patch", field)
+	return true
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dhermes/example-terraform-provider/pkg/metrics"
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `*serverMetrics` satisfies `model.QueryObserver`.
+var (
+	_ model.QueryObserver = (*serverMetrics)(nil)
+)
+
+const (
+	// routeUnmatched is the `route` label used for requests that did not
+	// match any route; the raw path is not used to keep cardinality bounded.
+	routeUnmatched = "unmatched"
+	// methodOther is the `method` label used for requests with a method that
+	// is not a standard HTTP method; clients choose the method, so it must
+	// not be used as a label as-is.
+	methodOther = "other"
+)
+
+// serverMetrics holds the metrics exported by the Books API server.
+type serverMetrics struct {
+	registry *metrics.Registry
+	requests *metrics.CounterVec
+	latency  *metrics.HistogramVec
+	queries  *metrics.HistogramVec
+}
+
+func newServerMetrics(pool *sql.DB) *serverMetrics {
+	r := metrics.NewRegistry()
+	sm := &serverMetrics{
+		registry: r,
+		requests: r.Counter(
+			"books_http_requests_total",
+			"Total number of HTTP requests handled.",
+			"method", "route", "status",
+		),
+		latency: r.Histogram(
+			"books_http_request_duration_seconds",
+			"Latency of HTTP requests in seconds.",
+			metrics.DefaultBuckets,
+			"method", "route", "status",
+		),
+		queries: r.Histogram(
+			"books_db_query_duration_seconds",
+			"Latency of database queries in seconds.",
+			metrics.DefaultBuckets,
+			"query",
+		),
+	}
+	// NOTE: There are no connection pool statistics with `StorageMemory`.
+	if pool == nil {
+		return sm
+	}
+
+	r.GaugeFunc(
+		"books_db_max_open_connections",
+		"Maximum number of open connections to the database.",
+		func() float64 { return float64(pool.Stats().MaxOpenConnections) },
+	)
+	r.GaugeFunc(
+		"books_db_open_connections",
+		"The number of established connections both in use and idle.",
+		func() float64 { return float64(pool.Stats().OpenConnections) },
+	)
+	r.GaugeFunc(
+		"books_db_in_use_connections",
+		"The number of connections currently in use.",
+		func() float64 { return float64(pool.Stats().InUse) },
+	)
+	r.GaugeFunc(
+		"books_db_idle_connections",
+		"The number of idle connections.",
+		func() float64 { return float64(pool.Stats().Idle) },
+	)
+	r.CounterFunc(
+		"books_db_wait_count_total",
+		"The total number of connections waited for.",
+		func() float64 { return float64(pool.Stats().WaitCount) },
+	)
+	r.CounterFunc(
+		"books_db_wait_duration_seconds_total",
+		"The total time blocked waiting for a new connection.",
+		func() float64 { return pool.Stats().WaitDuration.Seconds() },
+	)
+
+	return sm
+}
+
+// ObserveQuery satisfies the `model.QueryObserver` interface.
+func (sm *serverMetrics) ObserveQuery(name string, duration time.Duration) {
+	sm.queries.Observe(duration.Seconds(), name)
+}
+
+// observeRequest is a `requestObserver` that records request counts and
+// latencies.
+func (sm *serverMetrics) observeRequest(req *http.Request, ri *requestInfo) {
+	route := ri.Route
+	if route == "" {
+		route = routeUnmatched
+	}
+	method := methodLabel(req.Method)
+	status := strconv.Itoa(ri.Status)
+
+	sm.requests.Inc(method, route, status)
+	sm.latency.Observe(time.Since(ri.Start).Seconds(), method, route, status)
+}
+
+// methodLabel returns the `method` label for a request method, which is
+// `methodOther` for anything other than a standard HTTP method.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return methodOther
+	}
+}
+
+type metricsKey struct{}
+
+func withMetrics(ctx context.Context, sm *serverMetrics) context.Context {
+	ctx = model.WithQueryObserver(ctx, sm)
+	return context.WithValue(ctx, metricsKey{}, sm)
+}
+
+func getMetrics(ctx context.Context) *serverMetrics {
+	raw := ctx.Value(metricsKey{})
+	sm, _ := raw.(*serverMetrics)
+	return sm // Will be `nil` if type assertion fails
+}
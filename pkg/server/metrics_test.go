@@ -0,0 +1,53 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestObserveRequestMethodLabel(t *testing.T) {
+	type testCase struct {
+		Method string
+		Want   string
+	}
+
+	cases := []testCase{
+		{Method: http.MethodGet, Want: http.MethodGet},
+		{Method: http.MethodPatch, Want: http.MethodPatch},
+		{Method: "PROPFIND", Want: methodOther},
+		{Method: "get", Want: methodOther},
+	}
+	for _, tc := range cases {
+		sm := newServerMetrics(nil)
+		req := httptest.NewRequest(tc.Method, "/v1alpha1/books", nil)
+		sm.observeRequest(req, &requestInfo{Start: time.Now(), Route: "/v1alpha1/books", Status: http.StatusOK})
+
+		w := &bytes.Buffer{}
+		err := sm.registry.Write(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `books_http_requests_total{method="` + tc.Want + `",route="/v1alpha1/books",status="200"} 1`
+		if !strings.Contains(w.String(), want+"\n") {
+			t.Errorf("method %q: exposition does not contain %s:\n%s", tc.Method, want, w.String())
+		}
+	}
+}
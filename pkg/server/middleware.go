@@ -0,0 +1,90 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// chain wraps a handler in a sequence of middleware; the first middleware is
+// the outermost, i.e. it sees the request first.
+func chain(h http.Handler, mws ...middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// requireJSON rejects requests that do not have a JSON content type.
+func requireJSON(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if contentTypeNotJSON(w, req) {
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// requireMergePatch rejects requests that do not have a JSON merge patch
+// content type; plain JSON is also accepted for convenience.
+func requireMergePatch(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		contentType := req.Header.Get(HeaderContentType)
+		if contentType != ContentTypeMergePatchJSON && contentType != ContentTypeApplicationJSON {
+			invalidArgument(w, req, "JSON merge patch requests only", HeaderContentType)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// requireDatabase rejects requests for features that need PostgreSQL when
+// the server is running with `StorageMemory`.
+func requireDatabase(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if model.GetPool(req.Context()) == nil {
+			unimplemented(w, req)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// requestTimeout attaches a deadline to every request context, other than
+// for the (long-lived) `exempt` paths; a zero timeout disables it.
+func requestTimeout(timeout time.Duration, exempt ...string) middleware {
+	return func(h http.Handler) http.Handler {
+		if timeout <= 0 {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			for _, path := range exempt {
+				if req.URL.Path == path {
+					h.ServeHTTP(w, req)
+					return
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			defer cancel()
+			h.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
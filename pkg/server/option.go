@@ -14,5 +14,162 @@
 
 package server
 
+import (
+	"fmt"
+	"time"
+)
+
 // Option represents an initialization helper that can modify a config in-place.
 type Option func(*Config) error
+
+// OptAddr sets the bind address on a config.
+func OptAddr(addr string) Option {
+	return func(c *Config) error {
+		c.Addr = addr
+		return nil
+	}
+}
+
+// OptGRPCAddr sets the gRPC bind address on a config.
+func OptGRPCAddr(addr string) Option {
+	return func(c *Config) error {
+		c.GRPCAddr = addr
+		return nil
+	}
+}
+
+// OptTLS sets the TLS certificate and private key files on a config.
+func OptTLS(certFile, keyFile string) Option {
+	return func(c *Config) error {
+		c.TLSCertFile = certFile
+		c.TLSKeyFile = keyFile
+		return nil
+	}
+}
+
+// OptTLSClientCA sets the CA bundle used to verify client certificates
+// (enabling mutual TLS) on a config.
+func OptTLSClientCA(caFile string) Option {
+	return func(c *Config) error {
+		c.TLSClientCAFile = caFile
+		return nil
+	}
+}
+
+// OptDSN sets the database DSN on a config.
+func OptDSN(dsn string) Option {
+	return func(c *Config) error {
+		c.DSN = dsn
+		return nil
+	}
+}
+
+// OptDBPasswordFile sets the file containing the database password on a
+// config.
+func OptDBPasswordFile(path string) Option {
+	return func(c *Config) error {
+		c.DBPasswordFile = path
+		return nil
+	}
+}
+
+// OptStorage sets the storage backend on a config.
+func OptStorage(storage string) Option {
+	return func(c *Config) error {
+		if storage != StorageSQL && storage != StorageMemory {
+			return fmt.Errorf("invalid storage %q", storage)
+		}
+		c.Storage = storage
+		return nil
+	}
+}
+
+// OptReadHeaderTimeout sets the HTTP server timeout for reading request
+// headers on a config.
+func OptReadHeaderTimeout(d time.Duration) Option {
+	return func(c *Config) error {
+		c.ReadHeaderTimeout = d
+		return nil
+	}
+}
+
+// OptReadTimeout sets the HTTP server read timeout on a config.
+func OptReadTimeout(d time.Duration) Option {
+	return func(c *Config) error {
+		c.ReadTimeout = d
+		return nil
+	}
+}
+
+// OptWriteTimeout sets the HTTP server write timeout on a config.
+func OptWriteTimeout(d time.Duration) Option {
+	return func(c *Config) error {
+		c.WriteTimeout = d
+		return nil
+	}
+}
+
+// OptIdleTimeout sets the HTTP server (keep-alive) idle timeout on a config.
+func OptIdleTimeout(d time.Duration) Option {
+	return func(c *Config) error {
+		c.IdleTimeout = d
+		return nil
+	}
+}
+
+// OptShutdownTimeout sets the graceful shutdown drain period on a config.
+func OptShutdownTimeout(d time.Duration) Option {
+	return func(c *Config) error {
+		c.ShutdownTimeout = d
+		return nil
+	}
+}
+
+// OptRequestTimeout sets the per-request deadline on a config.
+func OptRequestTimeout(d time.Duration) Option {
+	return func(c *Config) error {
+		c.RequestTimeout = d
+		return nil
+	}
+}
+
+// OptStatementTimeout sets the PostgreSQL `statement_timeout` on a config.
+func OptStatementTimeout(d time.Duration) Option {
+	return func(c *Config) error {
+		c.StatementTimeout = d
+		return nil
+	}
+}
+
+// OptMetricsEnabled enables (or disables) the `/metrics` endpoint on a config.
+func OptMetricsEnabled(enabled bool) Option {
+	return func(c *Config) error {
+		c.MetricsEnabled = enabled
+		return nil
+	}
+}
+
+// OptMetadataTable sets the golembic migrations metadata table on a config.
+func OptMetadataTable(table string) Option {
+	return func(c *Config) error {
+		c.MetadataTable = table
+		return nil
+	}
+}
+
+// OptIdempotencyKeyTTL sets the idempotency key retention window on a config.
+func OptIdempotencyKeyTTL(d time.Duration) Option {
+	return func(c *Config) error {
+		c.IdempotencyKeyTTL = d
+		return nil
+	}
+}
+
+// OptTrashRetentionDays sets the number of days deleted authors and books
+// are kept before they are purged on a config.
+func OptTrashRetentionDays(days int) Option {
+	return func(c *Config) error {
+		c.TrashRetentionDays = days
+		return nil
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+const (
+	// defaultPageSize is the page size used when a list request does not
+	// specify `page_size`.
+	defaultPageSize = 50
+	// maxPageSize is the largest `page_size` a list request may ask for.
+	maxPageSize = 500
+)
+
+// parsePageSize parses the (optional) `page_size` query parameter.
+func parsePageSize(q url.Values) (int, error) {
+	raw := q.Get("page_size")
+	if raw == "" {
+		return defaultPageSize, nil
+	}
+
+	pageSize, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, err
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		return 0, errors.New("page size out of range")
+	}
+
+	return pageSize, nil
+}
+
+// encodePageToken serializes a keyset cursor as an opaque page token.
+func encodePageToken(v interface{}) (string, error) {
+	asJSON, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(asJSON), nil
+}
+
+// decodePageToken deserializes an opaque page token into a keyset cursor.
+func decodePageToken(token string, v interface{}) error {
+	asJSON, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(asJSON))
+	d.DisallowUnknownFields()
+	return d.Decode(v)
+}
+
+// orderBy is a parsed `order_by` query parameter.
+type orderBy struct {
+	Field      string
+	Descending bool
+}
+
+// String returns the canonical form of the ordering, e.g. `title desc`. This
+// is stored in page tokens so that a token can't be used with a different
+// ordering than the one that produced it.
+func (ob orderBy) String() string {
+	if ob.Descending {
+		return ob.Field + " desc"
+	}
+	return ob.Field + " asc"
+}
+
+// parseOrderBy parses the (optional) `order_by` query parameter, which is of
+// the form `field` or `field asc|desc`. The first of `fields` is the default
+// when `order_by` is not specified.
+func parseOrderBy(q url.Values, fields ...string) (orderBy, error) {
+	raw := q.Get("order_by")
+	if raw == "" {
+		return orderBy{Field: fields[0]}, nil
+	}
+
+	parts := strings.Fields(raw)
+	if len(parts) == 0 || len(parts) > 2 {
+		return orderBy{}, errors.New("malformed order by")
+	}
+
+	ob := orderBy{}
+	for _, field := range fields {
+		if parts[0] == field {
+			ob.Field = field
+		}
+	}
+	if ob.Field == "" {
+		return orderBy{}, errors.New("unsupported order by field")
+	}
+
+	if len(parts) == 2 {
+		switch strings.ToLower(parts[1]) {
+		case "asc":
+		case "desc":
+			ob.Descending = true
+		default:
+			return orderBy{}, errors.New("unsupported order by direction")
+		}
+	}
+
+	return ob, nil
+}
+
+type authorsPageToken struct {
+	Order     string    `json:"o"`
+	LastName  string    `json:"l,omitempty"`
+	FirstName string    `json:"f,omitempty"`
+	BookCount uint32    `json:"c,omitempty"`
+	ID        uuid.UUID `json:"i"`
+}
+
+// parseAuthorsPageToken parses the (optional) `page_token` query parameter
+// for a list authors request. A `nil` cursor means "first page".
+func parseAuthorsPageToken(q url.Values, ob orderBy) (*model.AuthorCursor, error) {
+	raw := q.Get("page_token")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var apt authorsPageToken
+	err := decodePageToken(raw, &apt)
+	if err != nil {
+		return nil, err
+	}
+	if apt.Order != ob.String() {
+		return nil, errors.New("page token ordering does not match")
+	}
+
+	return &model.AuthorCursor{LastName: apt.LastName, FirstName: apt.FirstName, BookCount: apt.BookCount, ID: apt.ID}, nil
+}
+
+func authorsNextPageToken(a model.Author, ob orderBy) (string, error) {
+	ac := model.AuthorCursorFrom(a)
+	apt := authorsPageToken{Order: ob.String(), ID: ac.ID}
+	if ob.Field == string(model.AuthorOrderBookCount) {
+		apt.BookCount = ac.BookCount
+	} else {
+		apt.LastName = ac.LastName
+		apt.FirstName = ac.FirstName
+	}
+	return encodePageToken(apt)
+}
+
+type booksPageToken struct {
+	Order       string     `json:"o"`
+	Title       string     `json:"t,omitempty"`
+	PublishDate *time.Time `json:"p,omitempty"`
+	ID          uuid.UUID  `json:"i"`
+}
+
+// parseBooksPageToken parses the (optional) `page_token` query parameter
+// for a list books request. A `nil` cursor means "first page".
+func parseBooksPageToken(q url.Values, ob orderBy) (*model.BookCursor, error) {
+	raw := q.Get("page_token")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var bpt booksPageToken
+	err := decodePageToken(raw, &bpt)
+	if err != nil {
+		return nil, err
+	}
+	if bpt.Order != ob.String() {
+		return nil, errors.New("page token ordering does not match")
+	}
+	if ob.Field == string(model.BookOrderPublishDate) && bpt.PublishDate == nil {
+		return nil, errors.New("page token missing publish date")
+	}
+
+	return &model.BookCursor{Title: bpt.Title, PublishDate: bpt.PublishDate, ID: bpt.ID}, nil
+}
+
+func booksNextPageToken(b model.Book, ob orderBy) (string, error) {
+	bc := model.BookCursorFrom(b)
+	bpt := booksPageToken{Order: ob.String(), ID: bc.ID}
+	if ob.Field == string(model.BookOrderPublishDate) {
+		bpt.PublishDate = bc.PublishDate
+	} else {
+		bpt.Title = bc.Title
+	}
+	return encodePageToken(bpt)
+}
+
+type auditPageToken struct {
+	ID int64 `json:"i"`
+}
+
+// parseAuditPageToken parses the (optional) `page_token` query parameter
+// for a list audit events request. A `nil` cursor means "first page".
+func parseAuditPageToken(q url.Values) (*model.AuditCursor, error) {
+	raw := q.Get("page_token")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var apt auditPageToken
+	err := decodePageToken(raw, &apt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuditCursor{ID: apt.ID}, nil
+}
+
+func auditNextPageToken(e model.AuditEvent) (string, error) {
+	ac := model.AuditCursorFrom(e)
+	return encodePageToken(auditPageToken{ID: ac.ID})
+}
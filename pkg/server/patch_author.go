@@ -0,0 +1,58 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `patchAuthor` satisfies `handleFunc`.
+var (
+	_ handleFunc = patchAuthor
+)
+
+func patchAuthor(w http.ResponseWriter, req *http.Request) {
+	id := uuidParam(req, "author_id")
+
+	var par patchAuthorRequest
+	if invalidMergePatch(w, req, &par) {
+		return
+	}
+
+	var version int64
+	if invalidIfMatch(w, req, &version) {
+		return
+	}
+
+	ctx := req.Context()
+	store := model.GetStore(ctx)
+	p := model.AuthorPatch{FirstName: par.FirstName, LastName: par.LastName}
+	newVersion, err := store.PatchAuthor(ctx, id, p, version)
+	if err != nil {
+		modelError(w, req, err, "failed to patch author")
+		return
+	}
+
+	w.Header().Set(HeaderETag, formatETag(newVersion))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type patchAuthorRequest struct {
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `patchBook` satisfies `handleFunc`.
+var (
+	_ handleFunc = patchBook
+)
+
+func patchBook(w http.ResponseWriter, req *http.Request) {
+	id := uuidParam(req, "book_id")
+
+	var pbr patchBookRequest
+	if invalidMergePatch(w, req, &pbr) {
+		return
+	}
+
+	var version int64
+	if invalidIfMatch(w, req, &version) {
+		return
+	}
+
+	ctx := req.Context()
+	store := model.GetStore(ctx)
+	p := model.BookPatch{AuthorID: pbr.AuthorID, Title: pbr.Title, PublishDate: pbr.PublishDate}
+	newVersion, err := store.PatchBook(ctx, id, p, version)
+	if err != nil {
+		modelError(w, req, err, "failed to patch book")
+		return
+	}
+
+	w.Header().Set(HeaderETag, formatETag(newVersion))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type patchBookRequest struct {
+	Title       *string    `json:"title"`
+	AuthorID    *uuid.UUID `json:"author_id"`
+	PublishDate *time.Time `json:"publish_date"`
+}
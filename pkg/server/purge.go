@@ -0,0 +1,76 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+const (
+	// purgeInterval is how often expired idempotency keys, old webhook
+	// deliveries and expired trash are deleted.
+	purgeInterval = time.Hour
+)
+
+// purge periodically deletes expired idempotency keys and old (successful)
+// webhook deliveries, and hard-deletes authors and books that have been in
+// the trash for longer than the retention period, until `ctx` is done. A
+// zero `TrashRetentionDays` disables purging the trash.
+//
+// The first purge runs right away, so that a server which is restarted more
+// often than `purgeInterval` still purges.
+func purge(ctx context.Context, c Config) {
+	purgeOnce(ctx, c)
+
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purgeOnce(ctx, c)
+		}
+	}
+}
+
+func purgeOnce(ctx context.Context, c Config) {
+	pool := model.GetPool(ctx)
+	store := model.GetStore(ctx)
+	now := time.Now()
+
+	// NOTE: Expired keys are already ignored when a key is reserved, so this
+	//       only keeps the table from growing without bound.
+	if pool != nil {
+		_, _ = model.DeleteExpiredIdempotencyKeys(ctx, pool, now.Add(-c.IdempotencyKeyTTL))
+		_, _ = model.DeleteDeliveredWebhookDeliveries(ctx, pool, now.Add(-webhookDeliveryRetention))
+	}
+
+	if c.TrashRetentionDays <= 0 {
+		return
+	}
+	deletedBefore := now.AddDate(0, 0, -c.TrashRetentionDays)
+	// NOTE: Books are purged first, since an author is only purged once no
+	//       books (deleted or not) refer to it.
+	_, err := store.PurgeDeletedBooks(ctx, deletedBefore)
+	if err != nil {
+		return
+	}
+	_, _ = store.PurgeDeletedAuthors(ctx, deletedBefore)
+}
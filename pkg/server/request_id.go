@@ -0,0 +1,47 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type requestIDKey struct{}
+
+// withRequestID ensures every request has a request ID, either the one sent
+// by the client in `X-Request-ID` or a newly generated one. The ID is echoed
+// back in the response headers and attached to the request context.
+func withRequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(HeaderRequestID)
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(HeaderRequestID, id)
+		ctx := context.WithValue(req.Context(), requestIDKey{}, id)
+		h.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// getRequestID gets the request ID attached to a context by `withRequestID`.
+func getRequestID(ctx context.Context) string {
+	raw := ctx.Value(requestIDKey{})
+	id, _ := raw.(string)
+	return id // Will be empty if type assertion fails
+}
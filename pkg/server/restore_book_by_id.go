@@ -0,0 +1,42 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `restoreBookByID` satisfies `handleFunc`.
+var (
+	_ handleFunc = restoreBookByID
+)
+
+func restoreBookByID(w http.ResponseWriter, req *http.Request) {
+	id := uuidParam(req, "book_id")
+
+	ctx := req.Context()
+	store := model.GetStore(ctx)
+	version, err := store.RestoreBookByID(ctx, id)
+	if err != nil {
+		modelError(w, req, err, "failed to restore book by ID")
+		return
+	}
+
+	w.Header().Set(HeaderETag, formatETag(version))
+	w.WriteHeader(http.StatusNoContent)
+}
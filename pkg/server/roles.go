@@ -0,0 +1,93 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	// RoleReader can read authors and books.
+	RoleReader = "reader"
+	// RoleEditor can read, add and update authors and books.
+	RoleEditor = "editor"
+	// RoleAdmin can do everything, including deletes and managing API
+	// tokens.
+	RoleAdmin = "admin"
+)
+
+// permission is an action that a route requires of the API token used to
+// call it.
+type permission string
+
+const (
+	permissionRead   permission = "read"
+	permissionWrite  permission = "write"
+	permissionDelete permission = "delete"
+	permissionAdmin  permission = "admin"
+)
+
+// rolePermissions is the permission set granted to each role.
+var rolePermissions = map[string][]permission{
+	RoleReader: {permissionRead},
+	RoleEditor: {permissionRead, permissionWrite},
+	RoleAdmin:  {permissionRead, permissionWrite, permissionDelete, permissionAdmin},
+}
+
+// validRole determines if `role` is one of the known roles.
+func validRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// hasPermission determines if `role` grants `p`.
+func hasPermission(role string, p permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// requirePermission returns a middleware that rejects requests whose API
+// token (attached by `requireAPIToken`) does not grant `p`. It must come
+// after `requireAPIToken` in a chain.
+func requirePermission(p permission) middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			t := getAPIToken(req.Context())
+			if t == nil {
+				unauthenticated(w, req, "missing API token")
+				return
+			}
+			if !hasPermission(t.Role, p) {
+				message := fmt.Sprintf("API token role %q does not have %q permission", t.Role, p)
+				permissionDenied(w, req, message)
+				return
+			}
+			h.ServeHTTP(w, req)
+		})
+	}
+}
+
+// authorize returns a middleware that authenticates a request via
+// `requireAPIToken` and then checks that its role grants `p`.
+func authorize(p permission) middleware {
+	return func(h http.Handler) http.Handler {
+		return requireAPIToken(requirePermission(p)(h))
+	}
+}
@@ -16,26 +16,190 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"google.golang.org/grpc"
 )
 
 // Run registers all API routes and runs the Books API server.
+//
+// If `c.GRPCAddr` is set, the same API is also served over gRPC. If a TLS
+// certificate is configured, both are served over TLS only. With
+// `StorageMemory`, a warning is written to stderr since every request has
+// `admin` access.
+//
+// The server runs until the process receives SIGINT / SIGTERM or either
+// transport fails; in both cases every transport is stopped and in-flight
+// requests (and background work, such as purging and webhook deliveries)
+// are given `c.ShutdownTimeout` to drain. The returned error joins any
+// failures.
 func Run(ctx context.Context, c Config) error {
-	m := http.NewServeMux()
+	if c.Storage == StorageMemory {
+		warnDevAPIToken(os.Stderr, c.Addr, c.GRPCAddr)
+	}
 
-	m.HandleFunc("/v1alpha1/author", oneAuthorDispatch)
-	m.HandleFunc("/v1alpha1/authors", getAuthors)
-	m.HandleFunc("/v1alpha1/authors/", authorByIDDispatch)
-	m.HandleFunc("/v1alpha1/book", oneBookDispatch)
-	m.HandleFunc("/v1alpha1/books", getBooks)
-	m.HandleFunc("/v1alpha1/books/", bookByIDDispatch)
-	m.HandleFunc("/", defaultHandler)
+	r := newRouter()
+
+	tc, err := newTLSConfig(c)
+	if err != nil {
+		return err
+	}
+	ready, err := readyz(c.MetadataTable)
+	if err != nil {
+		return err
+	}
+	r.handle(http.MethodGet, "/healthz", healthz)
+	r.handle(http.MethodGet, "/readyz", ready)
+
+	idempotentCreate := idempotent(c.IdempotencyKeyTTL)
+	eb := newEventBroker()
+	r.handle(http.MethodPost, "/v1alpha1/author", addAuthor, authorize(permissionWrite), requireJSON, idempotentCreate)
+	r.handle(http.MethodGet, "/v1alpha1/author", getAuthorByName, authorize(permissionRead), requireJSON)
+	r.handle(http.MethodPut, "/v1alpha1/author", updateAuthor, authorize(permissionWrite), requireJSON)
+	r.handle(http.MethodGet, "/v1alpha1/authors", getAuthors, authorize(permissionRead), requireJSON)
+	r.handle(http.MethodGet, "/v1alpha1/authors/{author_id:uuid}", getAuthorByID, authorize(permissionRead), requireJSON)
+	r.handle(http.MethodPatch, "/v1alpha1/authors/{author_id:uuid}", patchAuthor, authorize(permissionWrite), requireMergePatch)
+	r.handle(http.MethodDelete, "/v1alpha1/authors/{author_id:uuid}", deleteAuthorByID, authorize(permissionDelete))
+	r.handle(http.MethodPost, "/v1alpha1/authors/{author_id:uuid}:restore", restoreAuthorByID, authorize(permissionDelete))
+	r.handle(http.MethodPost, "/v1alpha1/book", addBook, authorize(permissionWrite), requireJSON, idempotentCreate)
+	r.handle(http.MethodPut, "/v1alpha1/book", updateBook, authorize(permissionWrite), requireJSON)
+	r.handle(http.MethodGet, "/v1alpha1/books", getBooks, authorize(permissionRead), requireJSON)
+	r.handle(http.MethodGet, "/v1alpha1/books/{book_id:uuid}", getBookByID, authorize(permissionRead), requireJSON)
+	r.handle(http.MethodPatch, "/v1alpha1/books/{book_id:uuid}", patchBook, authorize(permissionWrite), requireMergePatch)
+	r.handle(http.MethodDelete, "/v1alpha1/books/{book_id:uuid}", deleteBookByID, authorize(permissionDelete))
+	r.handle(http.MethodPost, "/v1alpha1/books/{book_id:uuid}:restore", restoreBookByID, authorize(permissionDelete))
+	r.handle(http.MethodGet, "/v1alpha1/trash", getTrash, authorize(permissionRead), requireJSON)
+	r.handle(http.MethodPost, "/v1alpha1/batch", runBatch, authorize(permissionWrite), requireJSON, idempotentCreate)
+	r.handle(http.MethodGet, "/v1alpha1/search", search, authorize(permissionRead), requireJSON)
+	r.handle(http.MethodGet, "/v1alpha1/audit", getAudit, authorize(permissionAdmin), requireDatabase, requireJSON)
+	r.handle(http.MethodGet, eventsPath, streamEvents(eb), authorize(permissionRead), requireDatabase)
+	r.handle(http.MethodPost, "/v1alpha1/token", addAPIToken, authorize(permissionAdmin), requireDatabase, requireJSON)
+	r.handle(http.MethodDelete, "/v1alpha1/tokens/{token_id:uuid}", deleteAPITokenByID, authorize(permissionAdmin), requireDatabase)
+	r.handle(http.MethodPost, "/v1alpha1/webhooks", addWebhook, authorize(permissionAdmin), requireDatabase, requireJSON)
+	r.handle(http.MethodGet, "/v1alpha1/webhooks", getWebhooks, authorize(permissionAdmin), requireDatabase, requireJSON)
+	r.handle(http.MethodDelete, "/v1alpha1/webhooks/{webhook_id:uuid}", deleteWebhookByID, authorize(permissionAdmin), requireDatabase)
+	r.handle(http.MethodGet, "/v1alpha1/webhooks/{webhook_id:uuid}/dead_letters", getWebhookDeadLetters, authorize(permissionAdmin), requireDatabase, requireJSON)
+
+	observers := []requestObserver{accessLog(os.Stderr)}
+	sm := getMetrics(ctx)
+	if sm != nil {
+		r.handle(http.MethodGet, "/metrics", sm.registry.ServeHTTP)
+		observers = append(observers, sm.observeRequest)
+	}
+
+	h := chain(
+		r,
+		withRequestID,
+		observeRequests(observers...),
+		requestTimeout(c.RequestTimeout, eventsPath),
+	)
 
 	s := &http.Server{
-		Addr:        c.Addr,
-		Handler:     m,
+		Addr:              c.Addr,
+		Handler:           h,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		ReadTimeout:       c.ReadTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		TLSConfig:         tc,
+		// NOTE: Requests use `ctx` rather than `signalCtx` so that in-flight
+		//       requests are not canceled as soon as a signal arrives.
 		BaseContext: func(_ net.Listener) context.Context { return ctx },
 	}
-	return s.ListenAndServe()
+
+	listener, err := net.Listen("tcp", c.Addr)
+	if err != nil {
+		return err
+	}
+	var gs *grpc.Server
+	var grpcListener net.Listener
+	if c.GRPCAddr != "" {
+		grpcListener, err = net.Listen("tcp", c.GRPCAddr)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		gs = newGRPCServer(ctx, h, eb, tc)
+	}
+
+	signalCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// NOTE: `runCtx` is also canceled as soon as any server stops, so that
+	//       if one fails the others are drained rather than left running.
+	runCtx, cancel := context.WithCancel(signalCtx)
+	defer cancel()
+	var background sync.WaitGroup
+	background.Add(3)
+	go func() {
+		defer background.Done()
+		purge(runCtx, c)
+	}()
+	go func() {
+		defer background.Done()
+		eb.listen(runCtx)
+	}()
+	go func() {
+		defer background.Done()
+		dispatchWebhooks(runCtx, eb, &http.Client{Timeout: webhookTimeout})
+	}()
+
+	serveErrs := make(chan error, 2)
+	serving := 1
+	go func() {
+		defer cancel()
+		if tc != nil {
+			// NOTE: The certificate is already in `s.TLSConfig`.
+			serveErrs <- s.ServeTLS(listener, "", "")
+			return
+		}
+		serveErrs <- s.Serve(listener)
+	}()
+	if gs != nil {
+		serving++
+		go func() {
+			defer cancel()
+			serveErrs <- gs.Serve(grpcListener)
+		}()
+	}
+
+	<-runCtx.Done()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), c.ShutdownTimeout)
+	defer cancelShutdown()
+	if gs != nil {
+		stopGRPCServer(shutdownCtx, gs)
+	}
+	shutdownErr := s.Shutdown(shutdownCtx)
+	waitBackground(shutdownCtx, &background)
+
+	errs := []error{}
+	for i := 0; i < serving; i++ {
+		err := <-serveErrs
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, shutdownErr)
+	return errors.Join(errs...)
+}
+
+// waitBackground waits for the background goroutines tracked by `wg` to
+// return, but only until `ctx` is done.
+func waitBackground(ctx context.Context, wg *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
 }
@@ -0,0 +1,245 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// NOTE: Ensure that
+//       * `*router` satisfies `http.Handler`.
+var (
+	_ http.Handler = (*router)(nil)
+)
+
+const (
+	paramTypeString = "string"
+	paramTypeUUID   = "uuid"
+)
+
+// segment is one `/`-delimited part of a route pattern; it is either a
+// literal (e.g. `authors`) or a typed parameter (e.g. `{author_id:uuid}`).
+// The last parameter may be followed by a custom verb (e.g.
+// `{author_id:uuid}:restore`).
+type segment struct {
+	literal   string
+	param     string
+	paramType string
+	verb      string
+}
+
+// route is a single entry in the route table.
+type route struct {
+	method   string
+	pattern  string
+	segments []segment
+	handler  http.Handler
+}
+
+// router dispatches requests based on the method and path. Paths are matched
+// against patterns segment by segment; a path that matches a pattern but not
+// the method results in a 405 with an `Allow` header.
+type router struct {
+	routes   []route
+	notFound http.Handler
+}
+
+func newRouter() *router {
+	return &router{notFound: http.HandlerFunc(defaultHandler)}
+}
+
+// handle registers a handler for a method and path pattern. Any middleware
+// provided applies only to this route (the first is outermost).
+//
+// Parameters in a pattern are written `{name}` or `{name:type}`; the only
+// types are `string` (the default) and `uuid`. A parameter can be followed by
+// a custom verb, e.g. `{name:type}:verb`. This panics on an invalid pattern,
+// since the route table is fixed at compile time.
+func (r *router) handle(method, pattern string, h handleFunc, mws ...middleware) {
+	segments, err := parsePattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+
+	rt := route{
+		method:   method,
+		pattern:  pattern,
+		segments: segments,
+		handler:  chain(http.HandlerFunc(h), mws...),
+	}
+	r.routes = append(r.routes, rt)
+}
+
+// ServeHTTP satisfies the `http.Handler` interface.
+func (r *router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	parts := splitPath(req.URL.Path)
+
+	allowed := []string{}
+	for _, rt := range r.routes {
+		raw, ok := rt.match(parts)
+		if !ok {
+			continue
+		}
+		if rt.method != req.Method {
+			allowed = append(allowed, rt.method)
+			continue
+		}
+
+		params, field, ok := rt.parseParams(raw)
+		if !ok {
+			invalidArgument(w, req, "invalid "+field, field)
+			return
+		}
+
+		setRequestRoute(req.Context(), rt.pattern)
+		ctx := context.WithValue(req.Context(), pathParamsKey{}, params)
+		rt.handler.ServeHTTP(w, req.WithContext(ctx))
+		return
+	}
+
+	if len(allowed) > 0 {
+		sort.Strings(allowed)
+		w.Header().Set(HeaderAllow, strings.Join(allowed, ", "))
+		writeError(w, req, http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed, "not allowed", "")
+		return
+	}
+
+	r.notFound.ServeHTTP(w, req)
+}
+
+// match determines if the path parts match the route pattern; if so, the
+// (unparsed) parameter values are returned.
+func (rt route) match(parts []string) (map[string]string, bool) {
+	if len(parts) != len(rt.segments) {
+		return nil, false
+	}
+
+	raw := map[string]string{}
+	for i, s := range rt.segments {
+		if s.param == "" {
+			if s.literal != parts[i] {
+				return nil, false
+			}
+			continue
+		}
+
+		// NOTE: A parameter without a custom verb never matches a part with
+		//       a `:`, so e.g. `GET /authors/{id}:restore` is a 405 rather
+		//       than an invalid `{id}`.
+		part := parts[i]
+		if s.verb == "" && strings.Contains(part, ":") {
+			return nil, false
+		}
+		if s.verb != "" {
+			if !strings.HasSuffix(part, ":"+s.verb) {
+				return nil, false
+			}
+			part = strings.TrimSuffix(part, ":"+s.verb)
+		}
+
+		if part == "" {
+			return nil, false
+		}
+		raw[s.param] = part
+	}
+
+	return raw, true
+}
+
+// parseParams converts raw parameter values to their declared types. If
+// a value is invalid, the parameter name is returned.
+func (rt route) parseParams(raw map[string]string) (map[string]interface{}, string, bool) {
+	params := map[string]interface{}{}
+	for _, s := range rt.segments {
+		if s.param == "" {
+			continue
+		}
+
+		value := raw[s.param]
+		if s.paramType == paramTypeUUID {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return nil, s.param, false
+			}
+			params[s.param] = id
+			continue
+		}
+		params[s.param] = value
+	}
+
+	return params, "", true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+func parsePattern(pattern string) ([]segment, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("route pattern %q must begin with /", pattern)
+	}
+
+	segments := []segment{}
+	for _, part := range splitPath(pattern) {
+		if !strings.HasPrefix(part, "{") {
+			segments = append(segments, segment{literal: part})
+			continue
+		}
+
+		verb := ""
+		if i := strings.Index(part, "}:"); i != -1 {
+			part, verb = part[:i+1], part[i+2:]
+			if verb == "" {
+				return nil, fmt.Errorf("route pattern %q has an empty custom verb", pattern)
+			}
+		}
+		if !strings.HasSuffix(part, "}") {
+			return nil, fmt.Errorf("route pattern %q has unterminated parameter %q", pattern, part)
+		}
+		inner := strings.TrimSuffix(strings.TrimPrefix(part, "{"), "}")
+		name, paramType := inner, paramTypeString
+		if i := strings.Index(inner, ":"); i != -1 {
+			name, paramType = inner[:i], inner[i+1:]
+		}
+		if name == "" || (paramType != paramTypeString && paramType != paramTypeUUID) {
+			return nil, fmt.Errorf("route pattern %q has invalid parameter %q", pattern, part)
+		}
+		segments = append(segments, segment{param: name, paramType: paramType, verb: verb})
+	}
+
+	return segments, nil
+}
+
+type pathParamsKey struct{}
+
+// uuidParam gets a `{name:uuid}` path parameter for the current request.
+func uuidParam(req *http.Request, name string) uuid.UUID {
+	params, _ := req.Context().Value(pathParamsKey{}).(map[string]interface{})
+	id, _ := params[name].(uuid.UUID)
+	return id // Will be `uuid.Nil` if type assertion fails
+}
+
+// stringParam gets a `{name}` path parameter for the current request.
+func stringParam(req *http.Request, name string) string {
+	params, _ := req.Context().Value(pathParamsKey{}).(map[string]interface{})
+	value, _ := params[name].(string)
+	return value // Will be empty if type assertion fails
+}
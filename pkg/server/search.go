@@ -0,0 +1,101 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `search` satisfies `handleFunc`.
+var (
+	_ handleFunc = search
+)
+
+const (
+	// defaultSearchLimit is the number of results returned when a search
+	// request does not specify `limit`.
+	defaultSearchLimit = 20
+	// maxSearchLimit is the largest `limit` a search request may ask for.
+	maxSearchLimit = 100
+)
+
+func search(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	query := q.Get("q")
+	if query == "" {
+		invalidArgument(w, req, "missing search query parameter", "q")
+		return
+	}
+
+	limit, err := parseSearchLimit(q)
+	if err != nil {
+		invalidArgument(w, req, "invalid limit", "limit")
+		return
+	}
+
+	ctx := req.Context()
+	store := model.GetStore(ctx)
+	resultsDB, err := store.Search(ctx, query, limit)
+	if err != nil {
+		modelError(w, req, err, "failed to search")
+		return
+	}
+
+	results := make([]searchResult, len(resultsDB))
+	for i, sr := range resultsDB {
+		results[i] = searchResult{
+			Kind:    string(sr.Kind),
+			ID:      sr.ID.String(),
+			Snippet: sr.Snippet,
+			Score:   sr.Score,
+		}
+	}
+	serializeJSONResponse(w, req, searchResponse{Results: results})
+}
+
+// parseSearchLimit parses the (optional) `limit` query parameter.
+func parseSearchLimit(q url.Values) (int, error) {
+	raw := q.Get("limit")
+	if raw == "" {
+		return defaultSearchLimit, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, err
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		return 0, errors.New("limit out of range")
+	}
+
+	return limit, nil
+}
+
+type searchResult struct {
+	Kind    string  `json:"kind"`
+	ID      string  `json:"id"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+type searchResponse struct {
+	Results []searchResult `json:"results"`
+}
@@ -15,7 +15,6 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -30,33 +29,26 @@ var (
 )
 
 func updateAuthor(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodPut) {
-		return
-	}
-	if contentTypeNotJSON(w, req) {
-		return
-	}
-	if req.URL.Path != "/v1alpha1/author" {
-		notFound(w)
+	var uar updateAuthorRequest
+	if invalidJSONBody(w, req, &uar) {
 		return
 	}
 
-	var uar updateAuthorRequest
-	if invalidJSONBody(w, req, &uar) {
+	var version int64
+	if invalidIfMatch(w, req, &version) {
 		return
 	}
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	a := model.Author{ID: uar.ID, FirstName: uar.FirstName, LastName: uar.LastName}
-	err := model.UpdateAuthor(ctx, pool, a)
+	store := model.GetStore(ctx)
+	a := model.Author{ID: uar.ID, FirstName: uar.FirstName, LastName: uar.LastName, Version: version}
+	newVersion, err := store.UpdateAuthor(ctx, a)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to update author"}`+"\n")
+		modelError(w, req, err, "failed to update author")
 		return
 	}
 
+	w.Header().Set(HeaderETag, formatETag(newVersion))
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -15,7 +15,6 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
 	"time"
 
@@ -31,33 +30,32 @@ var (
 )
 
 func updateBook(w http.ResponseWriter, req *http.Request) {
-	if notAllowed(w, req, http.MethodPut) {
-		return
-	}
-	if contentTypeNotJSON(w, req) {
+	var ubr updateBookRequest
+	if invalidJSONBody(w, req, &ubr) {
 		return
 	}
-	if req.URL.Path != "/v1alpha1/book" {
-		notFound(w)
+	// NOTE: PUT replaces every field, so a missing publish date would remove
+	//       it; partial updates should use PATCH instead.
+	if ubr.PublishDate == nil {
+		invalidArgument(w, req, "publish date is required; use PATCH for partial updates", "publish_date")
 		return
 	}
 
-	var ubr updateBookRequest
-	if invalidJSONBody(w, req, &ubr) {
+	var version int64
+	if invalidIfMatch(w, req, &version) {
 		return
 	}
 
 	ctx := req.Context()
-	pool := model.GetPool(ctx)
-	b := model.Book{ID: ubr.ID, AuthorID: ubr.AuthorID, Title: ubr.Title, PublishDate: ubr.PublishDate}
-	err := model.UpdateBook(ctx, pool, b)
+	store := model.GetStore(ctx)
+	b := model.Book{ID: ubr.ID, AuthorID: ubr.AuthorID, Title: ubr.Title, PublishDate: ubr.PublishDate, Version: version}
+	newVersion, err := store.UpdateBook(ctx, b)
 	if err != nil {
-		w.Header().Set(HeaderContentType, ContentTypeApplicationJSON)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, `{"error": "failed to update book"}`+"\n")
+		modelError(w, req, err, "failed to update book")
 		return
 	}
 
+	w.Header().Set(HeaderETag, formatETag(newVersion))
 	w.WriteHeader(http.StatusNoContent)
 }
 
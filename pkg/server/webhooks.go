@@ -0,0 +1,248 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `sqlWebhookOutbox` satisfies `webhookOutbox`.
+var (
+	_ webhookOutbox = sqlWebhookOutbox{}
+)
+
+const (
+	// webhookSecretPrefix makes webhook secrets easy to recognize, e.g. by
+	// secret scanners.
+	webhookSecretPrefix = "whsec_"
+	// webhookSecretBytes is the number of random bytes in a webhook secret.
+	webhookSecretBytes = 32
+	// webhookBatchSize is the most deliveries claimed (and sent
+	// concurrently) at once.
+	webhookBatchSize = 20
+	// webhookTimeout is how long a receiver has to respond to a delivery.
+	webhookTimeout = 10 * time.Second
+	// webhookLease is how long a claimed delivery is reserved for the
+	// dispatcher that claimed it; it must be longer than `webhookTimeout`.
+	webhookLease = time.Minute
+	// webhookPollInterval is how often due retries are checked for when no
+	// new events arrive.
+	webhookPollInterval = 5 * time.Second
+	// webhookMaxAttempts is the number of failed attempts after which a
+	// delivery becomes a dead letter.
+	webhookMaxAttempts = 10
+	// webhookRetryDelay is the delay before the first retry of a delivery;
+	// it doubles with each attempt, up to `webhookMaxRetryDelay`.
+	webhookRetryDelay    = 10 * time.Second
+	webhookMaxRetryDelay = time.Hour
+	// webhookDeliveryRetention is how long successful deliveries are kept.
+	webhookDeliveryRetention = 7 * 24 * time.Hour
+	// webhookErrorBodyLimit is the most bytes of a receiver's error response
+	// recorded as the delivery error.
+	webhookErrorBodyLimit = 512
+)
+
+// newWebhookSecret generates a random secret for signing webhook deliveries.
+func newWebhookSecret() (string, error) {
+	raw := make([]byte, webhookSecretBytes)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+
+	return webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// validWebhookURL determines if `raw` is an absolute HTTP(S) URL.
+func validWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// signWebhook computes the `X-Books-Signature` header value for a delivery.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookOutbox is the queue of webhook deliveries; the dispatcher claims
+// due deliveries from it and records the outcome of every attempt. It is
+// backed by the `webhook_deliveries` table (see `sqlWebhookOutbox`).
+type webhookOutbox interface {
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	MarkWebhookDelivered(ctx context.Context, id int64) error
+	MarkWebhookFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error
+}
+
+// sqlWebhookOutbox is a `webhookOutbox` backed by the database.
+type sqlWebhookOutbox struct {
+	pool *sql.DB
+}
+
+// ClaimWebhookDeliveries satisfies the `webhookOutbox` interface.
+func (swo sqlWebhookOutbox) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	return model.ClaimWebhookDeliveries(ctx, swo.pool, limit, lease)
+}
+
+// MarkWebhookDelivered satisfies the `webhookOutbox` interface.
+func (swo sqlWebhookOutbox) MarkWebhookDelivered(ctx context.Context, id int64) error {
+	return model.MarkWebhookDelivered(ctx, swo.pool, id)
+}
+
+// MarkWebhookFailed satisfies the `webhookOutbox` interface.
+func (swo sqlWebhookOutbox) MarkWebhookFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error {
+	return model.MarkWebhookFailed(ctx, swo.pool, id, lastError, nextAttemptAt, dead)
+}
+
+// webhookDispatcher sends the deliveries in an outbox with `client`.
+type webhookDispatcher struct {
+	outbox webhookOutbox
+	client *http.Client
+}
+
+// dispatchWebhooks delivers queued webhook deliveries with `client` until
+// `ctx` is done. It runs whenever `eb` reports new events and every
+// `webhookPollInterval` (to send retries that have come due).
+func dispatchWebhooks(ctx context.Context, eb *eventBroker, client *http.Client) {
+	pool := model.GetPool(ctx)
+	// NOTE: With `StorageMemory` there are no webhooks.
+	if pool == nil {
+		return
+	}
+
+	wd := webhookDispatcher{outbox: sqlWebhookOutbox{pool: pool}, client: client}
+	wake := eb.subscribe()
+	defer eb.unsubscribe(wake)
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		wd.dispatchOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchOnce sends every delivery that is currently due.
+func (wd webhookDispatcher) dispatchOnce(ctx context.Context) {
+	for {
+		deliveries, err := wd.outbox.ClaimWebhookDeliveries(ctx, webhookBatchSize, webhookLease)
+		if err != nil {
+			return
+		}
+
+		wg := sync.WaitGroup{}
+		for _, d := range deliveries {
+			wg.Add(1)
+			go func(d model.WebhookDelivery) {
+				defer wg.Done()
+				wd.recordAttempt(ctx, d, wd.deliver(ctx, d))
+			}(d)
+		}
+		wg.Wait()
+
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// deliver sends a single signed delivery; any response other than a 2xx is a
+// failure.
+func (wd webhookDispatcher) deliver(ctx context.Context, d model.WebhookDelivery) error {
+	body, err := json.Marshal(dbAuditEventToEvent(&d.Event))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HeaderContentType, ContentTypeApplicationJSON)
+	req.Header.Set(HeaderWebhookEventID, strconv.FormatInt(d.Event.ID, 10))
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookSignature, signWebhook(d.Webhook.Secret, timestamp, body))
+
+	resp, err := wd.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorBodyLimit))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+
+	return nil
+}
+
+// recordAttempt marks a delivery as delivered, or schedules a retry with
+// exponential backoff (moving it to the dead letters after
+// `webhookMaxAttempts` failures).
+func (wd webhookDispatcher) recordAttempt(ctx context.Context, d model.WebhookDelivery, deliverErr error) {
+	if deliverErr == nil {
+		_ = wd.outbox.MarkWebhookDelivered(ctx, d.ID)
+		return
+	}
+
+	dead := d.Attempts+1 >= webhookMaxAttempts
+	nextAttemptAt := time.Now().Add(webhookBackoff(d.Attempts))
+	_ = wd.outbox.MarkWebhookFailed(ctx, d.ID, deliverErr.Error(), nextAttemptAt, dead)
+}
+
+// webhookBackoff is the delay before retrying a delivery that has failed
+// `attempts` times before the current failure; it starts at
+// `webhookRetryDelay` and doubles up to `webhookMaxRetryDelay`.
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryDelay << attempts
+	if delay > webhookMaxRetryDelay || delay <= 0 {
+		delay = webhookMaxRetryDelay
+	}
+	return delay
+}
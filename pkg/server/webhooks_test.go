@@ -0,0 +1,289 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dhermes/example-terraform-provider/pkg/model"
+)
+
+// NOTE: Ensure that
+//       * `*memoryWebhookOutbox` satisfies `webhookOutbox`.
+var (
+	_ webhookOutbox = (*memoryWebhookOutbox)(nil)
+)
+
+// memoryWebhookOutbox is a `webhookOutbox` for tests. Every delivery that is
+// neither delivered nor dead is due, so each `dispatchOnce()` is one attempt.
+type memoryWebhookOutbox struct {
+	mu         sync.Mutex
+	deliveries []*model.WebhookDelivery
+}
+
+func (mwo *memoryWebhookOutbox) ClaimWebhookDeliveries(_ context.Context, limit int, _ time.Duration) ([]model.WebhookDelivery, error) {
+	mwo.mu.Lock()
+	defer mwo.mu.Unlock()
+
+	claimed := []model.WebhookDelivery{}
+	for _, d := range mwo.deliveries {
+		if d.DeliveredAt == nil && d.DeadAt == nil && len(claimed) < limit {
+			claimed = append(claimed, *d)
+		}
+	}
+	return claimed, nil
+}
+
+func (mwo *memoryWebhookOutbox) MarkWebhookDelivered(_ context.Context, id int64) error {
+	mwo.mu.Lock()
+	defer mwo.mu.Unlock()
+
+	d := mwo.get(id)
+	now := time.Now()
+	d.Attempts++
+	d.DeliveredAt = &now
+	d.LastError = nil
+	return nil
+}
+
+func (mwo *memoryWebhookOutbox) MarkWebhookFailed(_ context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error {
+	mwo.mu.Lock()
+	defer mwo.mu.Unlock()
+
+	d := mwo.get(id)
+	d.Attempts++
+	d.LastError = &lastError
+	d.NextAttemptAt = nextAttemptAt
+	if dead {
+		now := time.Now()
+		d.DeadAt = &now
+	}
+	return nil
+}
+
+func (mwo *memoryWebhookOutbox) get(id int64) *model.WebhookDelivery {
+	for _, d := range mwo.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	panic("unknown delivery " + strconv.FormatInt(id, 10))
+}
+
+// receivedWebhook is a request captured by a test receiver.
+type receivedWebhook struct {
+	Header http.Header
+	Body   []byte
+}
+
+// newTestReceiver starts an `httptest` receiver that responds with the next
+// status in `statuses` (repeating the last one) and records every request.
+func newTestReceiver(t *testing.T, statuses ...int) (*httptest.Server, func() []receivedWebhook) {
+	t.Helper()
+
+	mu := sync.Mutex{}
+	received := []receivedWebhook{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		mu.Lock()
+		received = append(received, receivedWebhook{Header: req.Header.Clone(), Body: body})
+		i := len(received) - 1
+		mu.Unlock()
+
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		w.WriteHeader(statuses[i])
+		_, _ = io.WriteString(w, "receiver says "+strconv.Itoa(statuses[i])+"\n")
+	}))
+	t.Cleanup(s.Close)
+
+	return s, func() []receivedWebhook {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedWebhook{}, received...)
+	}
+}
+
+func newTestDelivery(url string) *model.WebhookDelivery {
+	return &model.WebhookDelivery{
+		ID:           1,
+		WebhookID:    uuid.New(),
+		AuditEventID: 42,
+		Webhook: model.Webhook{
+			URL:    url,
+			Secret: "whsec_test",
+		},
+		Event: model.AuditEvent{
+			ID:         42,
+			OccurredAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+			Action:     "create",
+			EntityType: "author",
+			EntityID:   uuid.New(),
+			After:      []byte(`{"first_name":"Ursula","last_name":"Le Guin"}`),
+		},
+	}
+}
+
+func TestDispatchWebhookSigned(t *testing.T) {
+	s, received := newTestReceiver(t, http.StatusNoContent)
+	d := newTestDelivery(s.URL)
+	outbox := &memoryWebhookOutbox{deliveries: []*model.WebhookDelivery{d}}
+	wd := webhookDispatcher{outbox: outbox, client: s.Client()}
+
+	wd.dispatchOnce(context.Background())
+
+	requests := received()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	r := requests[0]
+	if got := r.Header.Get(HeaderContentType); got != ContentTypeApplicationJSON {
+		t.Errorf("unexpected content type %q", got)
+	}
+	if got := r.Header.Get(HeaderWebhookEventID); got != "42" {
+		t.Errorf("unexpected event ID %q", got)
+	}
+
+	timestamp := r.Header.Get(HeaderWebhookTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp %q", timestamp)
+	}
+	if age := time.Since(time.Unix(unix, 0)); age < -time.Minute || age > time.Minute {
+		t.Errorf("timestamp is not current: %s", timestamp)
+	}
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte(timestamp + "." + string(r.Body)))
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := r.Header.Get(HeaderWebhookSignature); got != expected {
+		t.Errorf("unexpected signature %q, expected %q", got, expected)
+	}
+
+	event := eventResponse{}
+	err = json.Unmarshal(r.Body, &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.ID != 42 || event.Type != "author.created" || event.EntityID != d.Event.EntityID.String() {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	if d.DeliveredAt == nil || d.Attempts != 1 || d.LastError != nil {
+		t.Errorf("delivery not marked delivered: %+v", d)
+	}
+}
+
+func TestDispatchWebhookRetries(t *testing.T) {
+	s, received := newTestReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+	d := newTestDelivery(s.URL)
+	outbox := &memoryWebhookOutbox{deliveries: []*model.WebhookDelivery{d}}
+	wd := webhookDispatcher{outbox: outbox, client: s.Client()}
+	ctx := context.Background()
+
+	for attempt, status := range []int{http.StatusInternalServerError, http.StatusBadGateway} {
+		before := time.Now()
+		wd.dispatchOnce(ctx)
+
+		if d.Attempts != attempt+1 || d.DeliveredAt != nil || d.DeadAt != nil {
+			t.Fatalf("unexpected delivery after attempt %d: %+v", attempt+1, d)
+		}
+		expected := "unexpected status " + strconv.Itoa(status) + ": receiver says " + strconv.Itoa(status)
+		if d.LastError == nil || *d.LastError != expected {
+			t.Errorf("unexpected last error %v, expected %q", d.LastError, expected)
+		}
+		delay := webhookRetryDelay << attempt
+		if d.NextAttemptAt.Before(before.Add(delay)) || d.NextAttemptAt.After(time.Now().Add(delay)) {
+			t.Errorf("attempt %d: next attempt at %s is not %s from now", attempt+1, d.NextAttemptAt, delay)
+		}
+	}
+
+	wd.dispatchOnce(ctx)
+	if d.DeliveredAt == nil || d.Attempts != 3 || d.LastError != nil {
+		t.Errorf("delivery not marked delivered: %+v", d)
+	}
+	if got := len(received()); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+
+	// NOTE: A delivered webhook is not sent again.
+	wd.dispatchOnce(ctx)
+	if got := len(received()); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestDispatchWebhookDeadLetter(t *testing.T) {
+	s, received := newTestReceiver(t, http.StatusServiceUnavailable)
+	d := newTestDelivery(s.URL)
+	outbox := &memoryWebhookOutbox{deliveries: []*model.WebhookDelivery{d}}
+	wd := webhookDispatcher{outbox: outbox, client: s.Client()}
+	ctx := context.Background()
+
+	for attempt := 1; attempt < webhookMaxAttempts; attempt++ {
+		wd.dispatchOnce(ctx)
+		if d.Attempts != attempt || d.DeadAt != nil {
+			t.Fatalf("unexpected delivery after attempt %d: %+v", attempt, d)
+		}
+	}
+	wd.dispatchOnce(ctx)
+	if d.Attempts != webhookMaxAttempts || d.DeadAt == nil || d.DeliveredAt != nil {
+		t.Fatalf("delivery not dead after %d attempts: %+v", webhookMaxAttempts, d)
+	}
+	if d.LastError == nil || !strings.HasPrefix(*d.LastError, "unexpected status 503") {
+		t.Errorf("unexpected last error %v", d.LastError)
+	}
+
+	// NOTE: A dead letter is not retried.
+	wd.dispatchOnce(ctx)
+	if got := len(received()); got != webhookMaxAttempts {
+		t.Errorf("expected %d requests, got %d", webhookMaxAttempts, got)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	cases := []struct {
+		Attempts int
+		Expected time.Duration
+	}{
+		{Attempts: 0, Expected: 10 * time.Second},
+		{Attempts: 1, Expected: 20 * time.Second},
+		{Attempts: 2, Expected: 40 * time.Second},
+		{Attempts: 8, Expected: 2560 * time.Second},
+		{Attempts: 9, Expected: webhookMaxRetryDelay},
+		{Attempts: 40, Expected: webhookMaxRetryDelay},
+		{Attempts: 64, Expected: webhookMaxRetryDelay},
+	}
+	for _, tc := range cases {
+		if got := webhookBackoff(tc.Attempts); got != tc.Expected {
+			t.Errorf("webhookBackoff(%d) = %s, expected %s", tc.Attempts, got, tc.Expected)
+		}
+	}
+}
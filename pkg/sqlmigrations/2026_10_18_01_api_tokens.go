@@ -0,0 +1,72 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlmigrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dhermes/golembic"
+)
+
+// NOTE: Ensure that
+//       * `AddAPITokensTable` satisfies `golembic.UpMigration`.
+var (
+	_ golembic.UpMigration = AddAPITokensTable
+)
+
+const (
+	apiTokensCreate = `
+CREATE TABLE api_tokens (
+  id UUID NOT NULL,
+  name TEXT NOT NULL,
+  token_hash BYTEA NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  revoked_at TIMESTAMP WITH TIME ZONE
+)
+`
+	apiTokensPK = `
+ALTER TABLE
+  api_tokens
+ADD CONSTRAINT
+  pk_api_tokens_id
+PRIMARY KEY
+  (id)
+`
+	apiTokensUniqueConstraint = `
+ALTER TABLE
+  api_tokens
+ADD CONSTRAINT
+  uq_api_tokens_token_hash
+UNIQUE
+  (token_hash)
+`
+)
+
+// AddAPITokensTable runs SQL statements required for adding the `api_tokens`
+// table. Only a SHA-256 hash of each token is stored.
+func AddAPITokensTable(ctx context.Context, tx *sql.Tx) error {
+	err := applySQL(ctx, tx, apiTokensCreate)
+	if err != nil {
+		return err
+	}
+
+	err = applySQL(ctx, tx, apiTokensPK)
+	if err != nil {
+		return err
+	}
+
+	return applySQL(ctx, tx, apiTokensUniqueConstraint)
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlmigrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dhermes/golembic"
+)
+
+// NOTE: Ensure that
+//       * `AddAPITokenRoles` satisfies `golembic.UpMigration`.
+var (
+	_ golembic.UpMigration = AddAPITokenRoles
+)
+
+const (
+	// NOTE: Tokens minted before roles existed could do everything, so they
+	//       are backfilled as `admin`; the default is dropped afterward so
+	//       that every new token must have an explicit role.
+	apiTokensAddRole = `
+ALTER TABLE
+  api_tokens
+ADD COLUMN
+  role TEXT NOT NULL DEFAULT 'admin'
+`
+	apiTokensDropRoleDefault = `
+ALTER TABLE
+  api_tokens
+ALTER COLUMN
+  role
+DROP DEFAULT
+`
+	apiTokensRoleCheck = `
+ALTER TABLE
+  api_tokens
+ADD CONSTRAINT
+  chk_api_tokens_role
+CHECK
+  (role IN ('reader', 'editor', 'admin'))
+`
+)
+
+// AddAPITokenRoles runs SQL statements required for adding a `role` column
+// to the `api_tokens` table.
+func AddAPITokenRoles(ctx context.Context, tx *sql.Tx) error {
+	err := applySQL(ctx, tx, apiTokensAddRole)
+	if err != nil {
+		return err
+	}
+
+	err = applySQL(ctx, tx, apiTokensDropRoleDefault)
+	if err != nil {
+		return err
+	}
+
+	return applySQL(ctx, tx, apiTokensRoleCheck)
+}
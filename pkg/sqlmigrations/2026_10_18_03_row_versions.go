@@ -0,0 +1,55 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlmigrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dhermes/golembic"
+)
+
+// NOTE: Ensure that
+//       * `AddRowVersions` satisfies `golembic.UpMigration`.
+var (
+	_ golembic.UpMigration = AddRowVersions
+)
+
+const (
+	authorsAddVersion = `
+ALTER TABLE
+  authors
+ADD COLUMN
+  version BIGINT NOT NULL DEFAULT 1
+`
+	booksAddVersion = `
+ALTER TABLE
+  books
+ADD COLUMN
+  version BIGINT NOT NULL DEFAULT 1
+`
+)
+
+// AddRowVersions runs SQL statements required for adding a `version` column
+// to the `authors` and `books` tables. The version is incremented on every
+// update and is used for optimistic concurrency control.
+func AddRowVersions(ctx context.Context, tx *sql.Tx) error {
+	err := applySQL(ctx, tx, authorsAddVersion)
+	if err != nil {
+		return err
+	}
+
+	return applySQL(ctx, tx, booksAddVersion)
+}
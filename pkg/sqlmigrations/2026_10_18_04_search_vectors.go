@@ -0,0 +1,85 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlmigrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dhermes/golembic"
+)
+
+// NOTE: Ensure that
+//       * `AddSearchVectors` satisfies `golembic.UpMigration`.
+var (
+	_ golembic.UpMigration = AddSearchVectors
+)
+
+const (
+	authorsAddSearchVector = `
+ALTER TABLE
+  authors
+ADD COLUMN
+  search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('english', first_name || ' ' || last_name)
+  ) STORED
+`
+	authorsSearchVectorIndex = `
+CREATE INDEX
+  idx_authors_search_vector
+ON
+  authors
+USING
+  GIN (search_vector)
+`
+	booksAddSearchVector = `
+ALTER TABLE
+  books
+ADD COLUMN
+  search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('english', title)
+  ) STORED
+`
+	booksSearchVectorIndex = `
+CREATE INDEX
+  idx_books_search_vector
+ON
+  books
+USING
+  GIN (search_vector)
+`
+)
+
+// AddSearchVectors runs SQL statements required for adding a generated
+// `search_vector` column (with a GIN index) to the `authors` and `books`
+// tables. These are used for full-text search.
+func AddSearchVectors(ctx context.Context, tx *sql.Tx) error {
+	err := applySQL(ctx, tx, authorsAddSearchVector)
+	if err != nil {
+		return err
+	}
+
+	err = applySQL(ctx, tx, authorsSearchVectorIndex)
+	if err != nil {
+		return err
+	}
+
+	err = applySQL(ctx, tx, booksAddSearchVector)
+	if err != nil {
+		return err
+	}
+
+	return applySQL(ctx, tx, booksSearchVectorIndex)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlmigrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dhermes/golembic"
+)
+
+// NOTE: Ensure that
+//       * `AddIdempotencyKeysTable` satisfies `golembic.UpMigration`.
+var (
+	_ golembic.UpMigration = AddIdempotencyKeysTable
+)
+
+const (
+	idempotencyKeysCreate = `
+CREATE TABLE idempotency_keys (
+  api_token_id UUID NOT NULL,
+  key TEXT NOT NULL,
+  request_hash BYTEA NOT NULL,
+  status_code INTEGER,
+  response_body BYTEA,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL
+)
+`
+	idempotencyKeysPK = `
+ALTER TABLE
+  idempotency_keys
+ADD CONSTRAINT
+  pk_idempotency_keys_api_token_id_key
+PRIMARY KEY
+  (api_token_id, key)
+`
+	idempotencyKeysCreatedAtIndex = `
+CREATE INDEX
+  idx_idempotency_keys_created_at
+ON
+  idempotency_keys (created_at)
+`
+)
+
+// AddIdempotencyKeysTable runs SQL statements required for adding the
+// `idempotency_keys` table. Keys are scoped to the API token that sent them
+// and the response is stored so that a retried request can be replayed.
+func AddIdempotencyKeysTable(ctx context.Context, tx *sql.Tx) error {
+	err := applySQL(ctx, tx, idempotencyKeysCreate)
+	if err != nil {
+		return err
+	}
+
+	err = applySQL(ctx, tx, idempotencyKeysPK)
+	if err != nil {
+		return err
+	}
+
+	return applySQL(ctx, tx, idempotencyKeysCreatedAtIndex)
+}
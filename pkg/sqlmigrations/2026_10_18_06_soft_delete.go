@@ -0,0 +1,94 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlmigrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dhermes/golembic"
+)
+
+// NOTE: Ensure that
+//       * `AddSoftDelete` satisfies `golembic.UpMigration`.
+var (
+	_ golembic.UpMigration = AddSoftDelete
+)
+
+const (
+	authorsAddDeletedAt = `
+ALTER TABLE
+  authors
+ADD COLUMN
+  deleted_at TIMESTAMP WITH TIME ZONE
+`
+	authorsDropUniqueConstraint = `
+ALTER TABLE
+  authors
+DROP CONSTRAINT
+  uq_authors_full_name
+`
+	authorsLiveUniqueIndex = `
+CREATE UNIQUE INDEX
+  uq_authors_full_name
+ON
+  authors (first_name, last_name)
+WHERE
+  deleted_at IS NULL
+`
+	booksAddDeletedAt = `
+ALTER TABLE
+  books
+ADD COLUMN
+  deleted_at TIMESTAMP WITH TIME ZONE
+`
+	booksDropUniqueConstraint = `
+ALTER TABLE
+  books
+DROP CONSTRAINT
+  uq_books_author_id_title
+`
+	booksLiveUniqueIndex = `
+CREATE UNIQUE INDEX
+  uq_books_author_id_title
+ON
+  books (author_id, title)
+WHERE
+  deleted_at IS NULL
+`
+)
+
+// AddSoftDelete runs SQL statements required for adding a `deleted_at`
+// column to the `authors` and `books` tables. A row with `deleted_at` set is
+// in the trash; the unique constraints are replaced by partial unique
+// indexes so they only apply to rows that are not deleted.
+func AddSoftDelete(ctx context.Context, tx *sql.Tx) error {
+	statements := []string{
+		authorsAddDeletedAt,
+		authorsDropUniqueConstraint,
+		authorsLiveUniqueIndex,
+		booksAddDeletedAt,
+		booksDropUniqueConstraint,
+		booksLiveUniqueIndex,
+	}
+	for _, statement := range statements {
+		err := applySQL(ctx, tx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlmigrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dhermes/golembic"
+)
+
+// NOTE: Ensure that
+//       * `AddAuditLog` satisfies `golembic.UpMigration`.
+var (
+	_ golembic.UpMigration = AddAuditLog
+)
+
+const (
+	auditLogCreate = `
+CREATE TABLE audit_log (
+  id BIGSERIAL PRIMARY KEY,
+  occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  actor TEXT NOT NULL,
+  api_token_id UUID,
+  action TEXT NOT NULL,
+  entity_type TEXT NOT NULL,
+  entity_id UUID NOT NULL,
+  before JSONB,
+  after JSONB
+)
+`
+	auditLogEntityIndex = `
+CREATE INDEX
+  idx_audit_log_entity
+ON
+  audit_log (entity_type, entity_id, id)
+`
+	auditLogActorIndex = `
+CREATE INDEX
+  idx_audit_log_actor
+ON
+  audit_log (actor, id)
+`
+	auditLogOccurredAtIndex = `
+CREATE INDEX
+  idx_audit_log_occurred_at
+ON
+  audit_log (occurred_at)
+`
+	// NOTE: The actor is read from the transaction-local settings written by
+	//       `model` before every mutation; writes made without one (e.g. by
+	//       an operator in `psql`) are attributed to `system`. The generated
+	//       `search_vector` column is left out of the snapshots.
+	auditLogFunction = `
+CREATE FUNCTION audit_row_change() RETURNS TRIGGER AS $$
+DECLARE
+  audit_action TEXT;
+  audit_before JSONB;
+  audit_after JSONB;
+  audit_token TEXT;
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    audit_action := 'create';
+    audit_after := to_jsonb(NEW) - 'search_vector';
+  ELSIF TG_OP = 'DELETE' THEN
+    audit_action := 'purge';
+    audit_before := to_jsonb(OLD) - 'search_vector';
+  ELSE
+    IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+      audit_action := 'delete';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+      audit_action := 'restore';
+    ELSE
+      audit_action := 'update';
+    END IF;
+    audit_before := to_jsonb(OLD) - 'search_vector';
+    audit_after := to_jsonb(NEW) - 'search_vector';
+  END IF;
+
+  audit_token := NULLIF(current_setting('books.audit_api_token_id', TRUE), '');
+  INSERT INTO
+    audit_log (occurred_at, actor, api_token_id, action, entity_type, entity_id, before, after)
+  VALUES
+    (
+      clock_timestamp(),
+      COALESCE(NULLIF(current_setting('books.audit_actor', TRUE), ''), 'system'),
+      audit_token::UUID,
+      audit_action,
+      TG_ARGV[0],
+      COALESCE(NEW.id, OLD.id),
+      audit_before,
+      audit_after
+    );
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+`
+	authorsAuditTrigger = `
+CREATE TRIGGER
+  trg_authors_audit
+AFTER INSERT OR UPDATE OR DELETE ON
+  authors
+FOR EACH ROW EXECUTE FUNCTION
+  audit_row_change('author')
+`
+	booksAuditTrigger = `
+CREATE TRIGGER
+  trg_books_audit
+AFTER INSERT OR UPDATE OR DELETE ON
+  books
+FOR EACH ROW EXECUTE FUNCTION
+  audit_row_change('book')
+`
+)
+
+// AddAuditLog runs SQL statements required for adding the `audit_log` table
+// and the triggers that record every insert, update and delete on the
+// `authors` and `books` tables. Since the audit row is written by a trigger,
+// it is always part of the same transaction as the change it describes.
+func AddAuditLog(ctx context.Context, tx *sql.Tx) error {
+	statements := []string{
+		auditLogCreate,
+		auditLogEntityIndex,
+		auditLogActorIndex,
+		auditLogOccurredAtIndex,
+		auditLogFunction,
+		authorsAuditTrigger,
+		booksAuditTrigger,
+	}
+	for _, statement := range statements {
+		err := applySQL(ctx, tx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlmigrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dhermes/golembic"
+)
+
+// NOTE: Ensure that
+//       * `AddAuditLogNotify` satisfies `golembic.UpMigration`.
+var (
+	_ golembic.UpMigration = AddAuditLogNotify
+)
+
+const (
+	// NOTE: The notification carries no payload; listeners read the new
+	//       events from `audit_log`. Postgres folds identical notifications
+	//       in a transaction into one, so a batch only wakes listeners once.
+	auditLogNotifyFunction = `
+CREATE FUNCTION notify_audit_log() RETURNS TRIGGER AS $$
+BEGIN
+  PERFORM pg_notify('audit_log_events', '');
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+`
+	auditLogNotifyTrigger = `
+CREATE TRIGGER
+  trg_audit_log_notify
+AFTER INSERT ON
+  audit_log
+FOR EACH STATEMENT EXECUTE FUNCTION
+  notify_audit_log()
+`
+)
+
+// AddAuditLogNotify runs SQL statements required for sending a notification
+// on the `audit_log_events` channel whenever audit log rows are committed,
+// so that every server replica can stream new events to its subscribers.
+func AddAuditLogNotify(ctx context.Context, tx *sql.Tx) error {
+	err := applySQL(ctx, tx, auditLogNotifyFunction)
+	if err != nil {
+		return err
+	}
+
+	return applySQL(ctx, tx, auditLogNotifyTrigger)
+}
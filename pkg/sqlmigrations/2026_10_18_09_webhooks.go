@@ -0,0 +1,138 @@
+// Copyright 2021 Danny Hermes
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlmigrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dhermes/golembic"
+)
+
+// NOTE: Ensure that
+//       * `AddWebhooks` satisfies `golembic.UpMigration`.
+var (
+	_ golembic.UpMigration = AddWebhooks
+)
+
+const (
+	webhooksCreate = `
+CREATE TABLE webhooks (
+  id UUID NOT NULL,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL
+)
+`
+	webhooksPK = `
+ALTER TABLE
+  webhooks
+ADD CONSTRAINT
+  pk_webhooks_id
+PRIMARY KEY
+  (id)
+`
+	webhookDeliveriesCreate = `
+CREATE TABLE webhook_deliveries (
+  id BIGSERIAL PRIMARY KEY,
+  webhook_id UUID NOT NULL,
+  audit_event_id BIGINT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  last_error TEXT,
+  delivered_at TIMESTAMP WITH TIME ZONE,
+  dead_at TIMESTAMP WITH TIME ZONE
+)
+`
+	webhookDeliveriesPendingIndex = `
+CREATE INDEX
+  idx_webhook_deliveries_pending
+ON
+  webhook_deliveries (next_attempt_at)
+WHERE
+  delivered_at IS NULL AND
+  dead_at IS NULL
+`
+	webhookDeliveriesWebhookIndex = `
+CREATE INDEX
+  idx_webhook_deliveries_webhook_id
+ON
+  webhook_deliveries (webhook_id, id)
+`
+	webhookDeadLettersView = `
+CREATE VIEW webhook_dead_letters AS
+SELECT
+  id,
+  webhook_id,
+  audit_event_id,
+  attempts,
+  last_error,
+  dead_at
+FROM
+  webhook_deliveries
+WHERE
+  dead_at IS NOT NULL
+`
+	// NOTE: Since this runs in the same transaction as the write to
+	//       `audit_log` (which itself is written by a trigger on the
+	//       `authors` or `books` write), a delivery is queued if and only if
+	//       the change commits.
+	webhookOutboxFunction = `
+CREATE FUNCTION enqueue_webhook_deliveries() RETURNS TRIGGER AS $$
+BEGIN
+  INSERT INTO
+    webhook_deliveries (webhook_id, audit_event_id, next_attempt_at)
+  SELECT
+    id, NEW.id, NOW()
+  FROM
+    webhooks;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql
+`
+	webhookOutboxTrigger = `
+CREATE TRIGGER
+  trg_audit_log_webhooks
+AFTER INSERT ON
+  audit_log
+FOR EACH ROW EXECUTE FUNCTION
+  enqueue_webhook_deliveries()
+`
+)
+
+// AddWebhooks runs SQL statements required for adding the `webhooks` table
+// and the `webhook_deliveries` outbox. Every audit log row is queued for
+// delivery to every webhook by a trigger; deliveries that exhaust their
+// retries are visible in the `webhook_dead_letters` view.
+func AddWebhooks(ctx context.Context, tx *sql.Tx) error {
+	statements := []string{
+		webhooksCreate,
+		webhooksPK,
+		webhookDeliveriesCreate,
+		webhookDeliveriesPendingIndex,
+		webhookDeliveriesWebhookIndex,
+		webhookDeadLettersView,
+		webhookOutboxFunction,
+		webhookOutboxTrigger,
+	}
+	for _, statement := range statements {
+		err := applySQL(ctx, tx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
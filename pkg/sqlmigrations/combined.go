@@ -41,6 +41,60 @@ func AllMigrations(_, _ string) (*golembic.Migrations, error) {
 			golembic.OptDescription("Create books table"),
 			golembic.OptUp(AddBooksTable),
 		},
+		[]golembic.MigrationOption{
+			golembic.OptPrevious("8fc64f953bb0"),
+			golembic.OptRevision("d3de8227fe18"),
+			golembic.OptDescription("Create API tokens table"),
+			golembic.OptUp(AddAPITokensTable),
+		},
+		[]golembic.MigrationOption{
+			golembic.OptPrevious("d3de8227fe18"),
+			golembic.OptRevision("c7a9f0d4b38e"),
+			golembic.OptDescription("Add role to API tokens"),
+			golembic.OptUp(AddAPITokenRoles),
+		},
+		[]golembic.MigrationOption{
+			golembic.OptPrevious("c7a9f0d4b38e"),
+			golembic.OptRevision("a9e942e38dde"),
+			golembic.OptDescription("Add version to authors and books"),
+			golembic.OptUp(AddRowVersions),
+		},
+		[]golembic.MigrationOption{
+			golembic.OptPrevious("a9e942e38dde"),
+			golembic.OptRevision("0959de6b1f77"),
+			golembic.OptDescription("Add full-text search vectors to authors and books"),
+			golembic.OptUp(AddSearchVectors),
+		},
+		[]golembic.MigrationOption{
+			golembic.OptPrevious("0959de6b1f77"),
+			golembic.OptRevision("9e4a47870c66"),
+			golembic.OptDescription("Create idempotency keys table"),
+			golembic.OptUp(AddIdempotencyKeysTable),
+		},
+		[]golembic.MigrationOption{
+			golembic.OptPrevious("9e4a47870c66"),
+			golembic.OptRevision("90012ffcc52a"),
+			golembic.OptDescription("Add soft delete to authors and books"),
+			golembic.OptUp(AddSoftDelete),
+		},
+		[]golembic.MigrationOption{
+			golembic.OptPrevious("90012ffcc52a"),
+			golembic.OptRevision("8b2f8a925667"),
+			golembic.OptDescription("Create audit log table and triggers"),
+			golembic.OptUp(AddAuditLog),
+		},
+		[]golembic.MigrationOption{
+			golembic.OptPrevious("8b2f8a925667"),
+			golembic.OptRevision("0d896e5baf8e"),
+			golembic.OptDescription("Notify listeners of new audit log rows"),
+			golembic.OptUp(AddAuditLogNotify),
+		},
+		[]golembic.MigrationOption{
+			golembic.OptPrevious("0d896e5baf8e"),
+			golembic.OptRevision("feb48e250e73"),
+			golembic.OptDescription("Create webhooks and webhook deliveries outbox"),
+			golembic.OptUp(AddWebhooks),
+		},
 	)
 	if err != nil {
 		return nil, err
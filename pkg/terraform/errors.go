@@ -20,10 +20,14 @@ import (
 
 // NOTE: Ensure that
 //       * `DiagnosticError` satisfies `error`.
-//       * `DiagnosticsProvider` satisfies `error`.
+//       * `DiagnosticError` satisfies `DiagnosticsProvider`.
+//       * `DiagnosticWarning` satisfies `error`.
+//       * `DiagnosticWarning` satisfies `DiagnosticsProvider`.
 var (
 	_ error               = DiagnosticError{}
 	_ DiagnosticsProvider = DiagnosticError{}
+	_ error               = DiagnosticWarning{}
+	_ DiagnosticsProvider = DiagnosticWarning{}
 )
 
 // DiagnosticError is an idiomatic Go error that seeks to match a subset of
@@ -48,6 +52,30 @@ func (de DiagnosticError) AppendDiagnostic(diags diag.Diagnostics) diag.Diagnost
 	return diags
 }
 
+// DiagnosticWarning is an idiomatic Go error that produces a warning (rather
+// than error) `diag.Diagnostic`. Returning it signals that an operation
+// completed, but with something the user should know about.
+type DiagnosticWarning struct {
+	Summary string
+	Detail  string
+}
+
+// Error satisfies the `error` interface.
+func (dw DiagnosticWarning) Error() string {
+	return dw.Summary
+}
+
+// AppendDiagnostic appends a warning `diag.Diagnostic` from the current
+// warning.
+func (dw DiagnosticWarning) AppendDiagnostic(diags diag.Diagnostics) diag.Diagnostics {
+	diags = append(diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  dw.Summary,
+		Detail:   dw.Detail,
+	})
+	return diags
+}
+
 // AppendDiagnostic appends a `diag.Diagnostic` from an error.
 func AppendDiagnostic(err error, diags diag.Diagnostics) diag.Diagnostics {
 	if err == nil {